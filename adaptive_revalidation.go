@@ -0,0 +1,190 @@
+package crema
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"time"
+)
+
+const (
+	defaultAdaptiveExpectedRefreshes = 3.0
+	defaultAdaptiveSampleRate        = 0.1
+	defaultAdaptiveMaxKeys           = 10000
+	defaultAdaptiveRateDecay         = 10 * time.Second
+	// minAdaptiveSamples is the number of samples a key needs before its rate
+	// estimate is trusted over the fallback curve.
+	minAdaptiveSamples = 8
+)
+
+type adaptiveRevalidationConfig struct {
+	expectedRefreshes float64
+	sampleRate        float64
+	maxKeys           int
+	rateDecay         time.Duration
+}
+
+// AdaptiveRevalidationOption configures NewAdaptiveRevalidation.
+type AdaptiveRevalidationOption func(*adaptiveRevalidationConfig)
+
+// WithAdaptiveExpectedRefreshes sets the expected number of early revalidations
+// per key and window. Higher values make it less likely that a key expires
+// before being revalidated, at the cost of more loads. It defaults to 3.
+func WithAdaptiveExpectedRefreshes(n float64) AdaptiveRevalidationOption {
+	return func(c *adaptiveRevalidationConfig) {
+		if n > 0 {
+			c.expectedRefreshes = n
+		}
+	}
+}
+
+// WithAdaptiveSampleRate sets the fraction of requests counted toward the
+// request rate of their key. It defaults to 0.1.
+func WithAdaptiveSampleRate(rate float64) AdaptiveRevalidationOption {
+	return func(c *adaptiveRevalidationConfig) {
+		if rate > 0 {
+			c.sampleRate = min(rate, 1)
+		}
+	}
+}
+
+// WithAdaptiveMaxKeys caps the number of keys whose request rate is tracked.
+// Once reached, an arbitrary key is forgotten for each new one. It defaults to 10000.
+func WithAdaptiveMaxKeys(n int) AdaptiveRevalidationOption {
+	return func(c *adaptiveRevalidationConfig) {
+		if n > 0 {
+			c.maxKeys = n
+		}
+	}
+}
+
+// WithAdaptiveRateDecay sets the time constant with which past requests stop
+// counting toward the request rate. It defaults to 10 seconds.
+func WithAdaptiveRateDecay(duration time.Duration) AdaptiveRevalidationOption {
+	return func(c *adaptiveRevalidationConfig) {
+		if duration > 0 {
+			c.rateDecay = duration
+		}
+	}
+}
+
+// adaptiveKeyRate is an exponentially decaying estimate of a key's request rate.
+type adaptiveKeyRate struct {
+	perSecond  float64
+	lastMillis int64
+	samples    int
+}
+
+type adaptiveRevalidation struct {
+	config   adaptiveRevalidationConfig
+	window   time.Duration
+	fallback RevalidationStrategy
+
+	mu    sync.RWMutex
+	rates map[string]*adaptiveKeyRate
+}
+
+// NewAdaptiveRevalidation returns a strategy adjusting the revalidation
+// probability to each key's request rate, so that about the configured number
+// of requests revalidate a key early within window regardless of its traffic.
+// Hot keys are revalidated with a much lower probability per request than cold
+// ones. The rate is estimated from requests sampled with the cache random
+// source for a bounded number of keys; keys without an estimate use
+// NewExponentialRevalidation(window). The strategy implements
+// RevalidationObserver to sample requests.
+func NewAdaptiveRevalidation(window time.Duration, opts ...AdaptiveRevalidationOption) RevalidationStrategy {
+	config := adaptiveRevalidationConfig{
+		expectedRefreshes: defaultAdaptiveExpectedRefreshes,
+		sampleRate:        defaultAdaptiveSampleRate,
+		maxKeys:           defaultAdaptiveMaxKeys,
+		rateDecay:         defaultAdaptiveRateDecay,
+	}
+	for _, opt := range opts {
+		if opt == nil {
+			continue
+		}
+		opt(&config)
+	}
+	fallback := NewExponentialRevalidation(window)
+
+	return &adaptiveRevalidation{
+		config:   config,
+		window:   fallback.Window(0),
+		fallback: fallback,
+		rates:    make(map[string]*adaptiveKeyRate),
+	}
+}
+
+var _ RevalidationObserver = (*adaptiveRevalidation)(nil)
+
+func (r *adaptiveRevalidation) Probability(entry RevalidationEntry) float64 {
+	if entry.RemainingMillis() > r.window.Milliseconds() {
+		return 0
+	}
+	perSecond, ok := r.rate(entry.Key, entry.NowMillis)
+	if !ok {
+		return r.fallback.Probability(entry)
+	}
+
+	return min(r.config.expectedRefreshes/(perSecond*r.window.Seconds()), 1)
+}
+
+func (r *adaptiveRevalidation) Window(time.Duration) time.Duration {
+	return r.window
+}
+
+func (r *adaptiveRevalidation) String() string {
+	return fmt.Sprintf("adaptive(window=%s, expected_refreshes=%g, sample_rate=%g)",
+		r.window, r.config.expectedRefreshes, r.config.sampleRate)
+}
+
+// ObserveRequest counts the request toward the rate of its key when random
+// samples it.
+func (r *adaptiveRevalidation) ObserveRequest(entry RevalidationEntry, random func() float64) {
+	if random() < r.config.sampleRate {
+		r.sample(entry.Key, entry.NowMillis)
+	}
+}
+
+// sample counts a sampled request for key, weighted by the inverse sample rate.
+func (r *adaptiveRevalidation) sample(key string, nowMillis int64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	rate, ok := r.rates[key]
+	if !ok {
+		if len(r.rates) >= r.config.maxKeys {
+			for evicted := range r.rates {
+				delete(r.rates, evicted)
+
+				break
+			}
+		}
+		rate = &adaptiveKeyRate{lastMillis: nowMillis}
+		r.rates[key] = rate
+	}
+	rate.perSecond = r.decayed(rate, nowMillis) + 1/(r.config.sampleRate*r.config.rateDecay.Seconds())
+	rate.lastMillis = max(rate.lastMillis, nowMillis)
+	rate.samples = min(rate.samples+1, minAdaptiveSamples)
+}
+
+// rate returns the estimated requests per second for key, or false when too
+// few requests were sampled.
+func (r *adaptiveRevalidation) rate(key string, nowMillis int64) (float64, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	rate, ok := r.rates[key]
+	if !ok || rate.samples < minAdaptiveSamples {
+		return 0, false
+	}
+	perSecond := r.decayed(rate, nowMillis)
+
+	return perSecond, perSecond > 0
+}
+
+func (r *adaptiveRevalidation) decayed(rate *adaptiveKeyRate, nowMillis int64) float64 {
+	elapsed := time.Duration(max(nowMillis-rate.lastMillis, 0)) * time.Millisecond
+
+	return rate.perSecond * math.Exp(-elapsed.Seconds()/r.config.rateDecay.Seconds())
+}
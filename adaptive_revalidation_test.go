@@ -0,0 +1,131 @@
+package crema
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+)
+
+// newTestAdaptiveRevalidation returns an adaptive strategy sampling every request.
+func newTestAdaptiveRevalidation(opts ...AdaptiveRevalidationOption) *adaptiveRevalidation {
+	opts = append(opts, WithAdaptiveSampleRate(1))
+
+	return NewAdaptiveRevalidation(time.Minute, opts...).(*adaptiveRevalidation)
+}
+
+// driveTraffic sends perSecond requests per second to key for duration and
+// returns the time after the last one.
+func driveTraffic(strategy RevalidationObserver, key string, perSecond int, duration time.Duration) int64 {
+	intervalMillis := time.Second.Milliseconds() / int64(perSecond)
+	var nowMillis int64
+	for ; nowMillis < duration.Milliseconds(); nowMillis += intervalMillis {
+		entry := RevalidationEntry{Key: key, NowMillis: nowMillis, ExpireAtMillis: nowMillis + time.Hour.Milliseconds()}
+		strategy.ObserveRequest(entry, fakeRandom(0))
+	}
+
+	return nowMillis
+}
+
+func TestAdaptiveRevalidation_ScalesWithRequestRate(t *testing.T) {
+	t.Parallel()
+
+	strategy := newTestAdaptiveRevalidation(WithAdaptiveExpectedRefreshes(3))
+	nowMillis := driveTraffic(strategy, "hot", 100, time.Minute)
+	driveTraffic(strategy, "warm", 1, time.Minute)
+
+	hot := strategy.Probability(RevalidationEntry{Key: "hot", NowMillis: nowMillis, ExpireAtMillis: nowMillis + 1000})
+	if want := 3.0 / (100 * strategy.window.Seconds()); math.Abs(hot-want)/want > 0.1 {
+		t.Fatalf("expected hot key probability near %f, got %f", want, hot)
+	}
+	warm := strategy.Probability(RevalidationEntry{Key: "warm", NowMillis: nowMillis, ExpireAtMillis: nowMillis + 1000})
+	if warm <= hot*50 {
+		t.Fatalf("expected warm key to revalidate far more often per request than hot key, got warm=%f hot=%f", warm, hot)
+	}
+	outside := strategy.Probability(RevalidationEntry{Key: "hot", NowMillis: nowMillis, ExpireAtMillis: nowMillis + time.Hour.Milliseconds()})
+	if outside != 0 {
+		t.Fatalf("expected 0 outside the window, got %f", outside)
+	}
+}
+
+func TestAdaptiveRevalidation_FallsBackWithoutEstimate(t *testing.T) {
+	t.Parallel()
+
+	strategy := newTestAdaptiveRevalidation()
+	entry := RevalidationEntry{Key: "cold", NowMillis: 0, ExpireAtMillis: 1000}
+	fallback := NewExponentialRevalidation(time.Minute).Probability(entry)
+	if got := strategy.Probability(entry); got != fallback {
+		t.Fatalf("expected fallback probability %f, got %f", fallback, got)
+	}
+}
+
+func TestAdaptiveRevalidation_BoundsTrackedKeys(t *testing.T) {
+	t.Parallel()
+
+	strategy := newTestAdaptiveRevalidation(WithAdaptiveMaxKeys(2))
+	for _, key := range []string{"a", "b", "c", "d"} {
+		strategy.ObserveRequest(RevalidationEntry{Key: key, ExpireAtMillis: time.Hour.Milliseconds()}, fakeRandom(0))
+	}
+	if got := len(strategy.rates); got != 2 {
+		t.Fatalf("expected 2 tracked keys, got %d", got)
+	}
+}
+
+func TestAdaptiveRevalidation_SamplesRequests(t *testing.T) {
+	t.Parallel()
+
+	strategy := NewAdaptiveRevalidation(time.Minute, WithAdaptiveSampleRate(0.5)).(*adaptiveRevalidation)
+	draws := []float64{0.9, 0.1}
+	random := func() float64 {
+		draw := draws[0]
+		draws = append(draws[1:], draw)
+
+		return draw
+	}
+	for i := 0; i < 4; i++ {
+		strategy.ObserveRequest(RevalidationEntry{Key: "key", ExpireAtMillis: time.Hour.Milliseconds()}, random)
+	}
+	if got := strategy.rates["key"].samples; got != 2 {
+		t.Fatalf("expected 2 sampled requests, got %d", got)
+	}
+}
+
+func TestAdaptiveRevalidation_ProbabilityDoesNotSample(t *testing.T) {
+	t.Parallel()
+
+	strategy := newTestAdaptiveRevalidation()
+	for i := 0; i < minAdaptiveSamples; i++ {
+		strategy.Probability(RevalidationEntry{Key: "key", NowMillis: int64(i), ExpireAtMillis: 1000})
+	}
+	if got := len(strategy.rates); got != 0 {
+		t.Fatalf("expected Probability not to track request rates, got %d keys", got)
+	}
+}
+
+func TestCache_AdaptiveRevalidationSamplesOnlyReads(t *testing.T) {
+	t.Parallel()
+
+	strategy := newTestAdaptiveRevalidation()
+	provider := &testMemoryProvider[int]{items: make(map[string]CacheObject[int])}
+	provider.items["key"] = CacheObject[int]{Value: 1, ExpireAtMillis: time.Now().Add(time.Hour).UnixMilli()}
+	cache := NewCache(provider, NoopCacheStorageCodec[int]{},
+		WithRevalidationStrategy[int, CacheObject[int]](strategy),
+		WithRandomSource[int, CacheObject[int]](fakeRandom(0)),
+	)
+
+	err := cache.(CacheWarmer[int]).Warm(context.Background(), []string{"key"}, time.Hour, func(context.Context, string) (int, error) {
+		return 2, nil
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if got := len(strategy.rates); got != 0 {
+		t.Fatalf("expected Warm not to sample requests, got %d keys", got)
+	}
+	if _, err := cache.GetOrLoad(context.Background(), "key", time.Hour, constLoader(2)); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if got := strategy.rates["key"]; got == nil || got.samples != 1 {
+		t.Fatalf("expected GetOrLoad to sample the request with the cache random source, got %+v", got)
+	}
+}
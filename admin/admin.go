@@ -0,0 +1,191 @@
+// Package admin provides an http.Handler for inspecting crema caches at
+// runtime, meant to be mounted on an internal debug port next to pprof.
+//
+// It serves JSON describing each registered cache's configuration, counters
+// and in-flight singleflight loads, and lets operators get, delete and
+// refresh individual keys:
+//
+//	GET    /caches                       registered caches and their counters
+//	GET    /caches/{name}                configuration, counters and in-flight loads
+//	GET    /caches/{name}/keys/{key}     cached entry
+//	DELETE /caches/{name}/keys/{key}     delete the entry
+//	POST   /caches/{name}/refresh/{key}  reload the entry, see WithRefreshLoader
+//
+// The handler exposes cached values and can modify the cache, so it must not
+// be reachable from untrusted networks.
+package admin
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/abema/crema"
+)
+
+// Handler serves the admin endpoints for the caches registered with Register.
+// It is safe for concurrent use.
+type Handler struct {
+	mu     sync.RWMutex
+	caches map[string]registeredCache
+	mux    *http.ServeMux
+}
+
+var _ http.Handler = (*Handler)(nil)
+
+// NewHandler returns a Handler with no caches registered.
+// Mount it with http.StripPrefix when serving it below the root, e.g.
+//
+//	mux.Handle("/debug/crema/", http.StripPrefix("/debug/crema", admin.NewHandler()))
+func NewHandler() *Handler {
+	h := &Handler{caches: make(map[string]registeredCache), mux: http.NewServeMux()}
+	h.mux.HandleFunc("GET /caches", h.listCaches)
+	h.mux.HandleFunc("GET /caches/{name}", h.getCache)
+	h.mux.HandleFunc("GET /caches/{name}/keys/{key...}", h.getKey)
+	h.mux.HandleFunc("DELETE /caches/{name}/keys/{key...}", h.deleteKey)
+	h.mux.HandleFunc("POST /caches/{name}/refresh/{key...}", h.refreshKey)
+
+	return h
+}
+
+// ServeHTTP implements http.Handler.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mux.ServeHTTP(w, r)
+}
+
+type registerConfig[V any] struct {
+	loader crema.WarmLoadFunc[V]
+	ttl    time.Duration
+}
+
+// RegisterOption configures a cache registered with Register.
+type RegisterOption[V any] func(*registerConfig[V])
+
+// WithRefreshLoader enables the refresh endpoint, which reloads a key with
+// loader through crema.CacheRefresher and stores it with ttl.
+// Without it, or for caches not implementing crema.CacheRefresher, refresh
+// requests are answered with 501 Not Implemented.
+func WithRefreshLoader[V any](loader crema.WarmLoadFunc[V], ttl time.Duration) RegisterOption[V] {
+	return func(c *registerConfig[V]) {
+		c.loader = loader
+		c.ttl = ttl
+	}
+}
+
+// Cache is the subset of crema.Cache used by the handler. It lets Register
+// infer the value type of a crema.Cache, whose storage type is irrelevant here.
+type Cache[V any] interface {
+	Get(ctx context.Context, key string) (crema.CacheObject[V], bool, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// Register adds cache to h under name, replacing any cache registered under it.
+// Configuration, counters and in-flight loads are only reported for caches
+// implementing crema.CacheInspector, such as those built by crema.NewCache.
+func Register[V any](h *Handler, name string, cache Cache[V], opts ...RegisterOption[V]) {
+	var config registerConfig[V]
+	for _, opt := range opts {
+		if opt == nil {
+			continue
+		}
+		opt(&config)
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.caches[name] = &cacheAdapter[V]{cache: cache, config: config}
+}
+
+// Unregister removes the cache registered under name, if any.
+func (h *Handler) Unregister(name string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.caches, name)
+}
+
+func (h *Handler) lookup(name string) (registeredCache, bool) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	cache, ok := h.caches[name]
+
+	return cache, ok
+}
+
+func (h *Handler) names() []string {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	names := make([]string, 0, len(h.caches))
+	for name := range h.caches {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// registeredCache is a cache with its value type erased.
+type registeredCache interface {
+	inspect() (crema.CacheInfo, bool)
+	get(ctx context.Context, key string) (entryView, bool, error)
+	delete(ctx context.Context, key string) error
+	refresh(ctx context.Context, key string) error
+	refreshable() bool
+}
+
+type cacheAdapter[V any] struct {
+	cache  Cache[V]
+	config registerConfig[V]
+}
+
+func (a *cacheAdapter[V]) inspect() (crema.CacheInfo, bool) {
+	inspector, ok := a.cache.(crema.CacheInspector)
+	if !ok {
+		return crema.CacheInfo{}, false
+	}
+
+	return inspector.Inspect(), true
+}
+
+func (a *cacheAdapter[V]) get(ctx context.Context, key string) (entryView, bool, error) {
+	object, found, err := a.cache.Get(ctx, key)
+	if err != nil || !found {
+		return entryView{}, found, err
+	}
+	entry := crema.RevalidationEntry{
+		Key:            key,
+		NowMillis:      time.Now().UnixMilli(),
+		ExpireAtMillis: object.ExpireAtMillis,
+		TTL:            time.Duration(object.TTLMillis) * time.Millisecond,
+		LoadDuration:   time.Duration(object.LoadDurationMillis) * time.Millisecond,
+	}
+	view := newEntryView(object.Value, entry)
+	if info, ok := a.inspect(); ok {
+		view.addRevalidation(info.Config.RevalidationStrategy, entry)
+	}
+
+	return view, true, nil
+}
+
+func (a *cacheAdapter[V]) delete(ctx context.Context, key string) error {
+	return a.cache.Delete(ctx, key)
+}
+
+func (a *cacheAdapter[V]) refreshable() bool {
+	_, ok := a.cache.(crema.CacheRefresher[V])
+
+	return ok && a.config.loader != nil
+}
+
+func (a *cacheAdapter[V]) refresh(ctx context.Context, key string) error {
+	refresher, ok := a.cache.(crema.CacheRefresher[V])
+	if !ok || a.config.loader == nil {
+		return errRefreshNotConfigured
+	}
+	loader := a.config.loader
+
+	return refresher.Refresh(ctx, key, a.config.ttl, func(ctx context.Context) (V, error) {
+		return loader(ctx, key)
+	})
+}
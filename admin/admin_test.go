@@ -0,0 +1,215 @@
+package admin_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/abema/crema"
+	"github.com/abema/crema/admin"
+	"github.com/abema/crema/crematest"
+)
+
+type testCache = crema.Cache[string, crema.CacheObject[string]]
+
+func newTestCache(t *testing.T, opts ...crema.CacheOption[string, crema.CacheObject[string]]) testCache {
+	t.Helper()
+
+	provider := crematest.NewRecordingProvider[crema.CacheObject[string]](nil)
+
+	return crema.NewCache(provider, crema.NoopCacheStorageCodec[string]{}, opts...)
+}
+
+func serve(t *testing.T, h http.Handler, method, path string, out any) int {
+	t.Helper()
+
+	req := httptest.NewRequest(method, path, nil)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if out != nil && rec.Body.Len() > 0 {
+		if err := json.Unmarshal(rec.Body.Bytes(), out); err != nil {
+			t.Fatalf("%s %s: invalid JSON %q: %v", method, path, rec.Body.String(), err)
+		}
+	}
+
+	return rec.Code
+}
+
+func TestHandler_ListsCaches(t *testing.T) {
+	t.Parallel()
+
+	cache := newTestCache(t)
+	if _, err := cache.GetOrLoad(context.Background(), "key", time.Minute, func(context.Context) (string, error) {
+		return "value", nil
+	}); err != nil {
+		t.Fatalf("GetOrLoad() error = %v", err)
+	}
+	h := admin.NewHandler()
+	admin.Register(h, "users", cache)
+	admin.Register(h, "items", newTestCache(t))
+
+	var caches []struct {
+		Name  string `json:"name"`
+		Stats struct {
+			Gets  int64 `json:"gets"`
+			Loads int64 `json:"loads"`
+		} `json:"stats"`
+	}
+	if code := serve(t, h, http.MethodGet, "/caches", &caches); code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", code)
+	}
+	if len(caches) != 2 || caches[0].Name != "items" || caches[1].Name != "users" {
+		t.Fatalf("caches = %+v, want items and users", caches)
+	}
+	if caches[1].Stats.Gets != 1 || caches[1].Stats.Loads != 1 {
+		t.Fatalf("users stats = %+v, want 1 get and 1 load", caches[1].Stats)
+	}
+
+	h.Unregister("items")
+	if code := serve(t, h, http.MethodGet, "/caches", &caches); code != http.StatusOK || len(caches) != 1 {
+		t.Fatalf("status = %d, caches = %+v after Unregister", code, caches)
+	}
+}
+
+func TestHandler_ReportsConfigAndInFlightLoads(t *testing.T) {
+	t.Parallel()
+
+	cache := newTestCache(t,
+		crema.WithMaxLoadTimeout[string, crema.CacheObject[string]](3*time.Second),
+		crema.WithFollowerWaitTimeout[string, crema.CacheObject[string]](time.Second),
+	)
+	h := admin.NewHandler()
+	admin.Register(h, "users", cache, admin.WithRefreshLoader(func(context.Context, string) (string, error) {
+		return "value", nil
+	}, time.Minute))
+
+	unblock := make(chan struct{})
+	var wg sync.WaitGroup
+	for range 3 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, _ = cache.GetOrLoad(context.Background(), "hot", time.Minute, func(context.Context) (string, error) {
+				<-unblock
+
+				return "value", nil
+			})
+		}()
+	}
+	defer wg.Wait()
+	defer close(unblock)
+
+	var view struct {
+		Config struct {
+			Singleflight          bool    `json:"singleflight"`
+			RevalidationStrategy  string  `json:"revalidation_strategy"`
+			RevalidationSteepness float64 `json:"revalidation_steepness"`
+			MaxLoadTimeout        string  `json:"max_load_timeout"`
+			FollowerWaitTimeout   string  `json:"follower_wait_timeout"`
+			Refresh               bool    `json:"refresh"`
+		} `json:"config"`
+		InFlight []struct {
+			Key     string `json:"key"`
+			Waiters int    `json:"waiters"`
+		} `json:"in_flight"`
+	}
+	deadline := time.After(time.Second)
+	for {
+		if code := serve(t, h, http.MethodGet, "/caches/users", &view); code != http.StatusOK {
+			t.Fatalf("status = %d, want 200", code)
+		}
+		if len(view.InFlight) == 1 && view.InFlight[0].Waiters == 3 {
+			break
+		}
+		select {
+		case <-deadline:
+			t.Fatalf("in_flight = %+v, want hot with 3 waiters", view.InFlight)
+		default:
+			time.Sleep(time.Millisecond)
+		}
+	}
+	if view.InFlight[0].Key != "hot" {
+		t.Fatalf("in_flight = %+v, want hot", view.InFlight)
+	}
+	if !view.Config.Singleflight || view.Config.RevalidationSteepness <= 0 || !view.Config.Refresh ||
+		view.Config.MaxLoadTimeout != "3s" || view.Config.FollowerWaitTimeout != "1s" {
+		t.Fatalf("config = %+v", view.Config)
+	}
+	if !strings.HasPrefix(view.Config.RevalidationStrategy, "exponential(window=") {
+		t.Fatalf("revalidation_strategy = %q, want the default exponential strategy", view.Config.RevalidationStrategy)
+	}
+}
+
+func TestHandler_GetDeleteRefreshKey(t *testing.T) {
+	t.Parallel()
+
+	cache := newTestCache(t)
+	h := admin.NewHandler()
+	loads := 0
+	admin.Register(h, "users", cache, admin.WithRefreshLoader(func(_ context.Context, key string) (string, error) {
+		loads++
+
+		return "loaded " + key, nil
+	}, time.Hour))
+
+	if code := serve(t, h, http.MethodGet, "/caches/users/keys/user/1", nil); code != http.StatusNotFound {
+		t.Fatalf("GET missing key status = %d, want 404", code)
+	}
+	if code := serve(t, h, http.MethodPost, "/caches/users/refresh/user/1", nil); code != http.StatusNoContent {
+		t.Fatalf("POST refresh status = %d, want 204", code)
+	}
+
+	var entry struct {
+		Key                     string   `json:"key"`
+		Value                   string   `json:"value"`
+		TTL                     string   `json:"ttl"`
+		RevalidationWindow      string   `json:"revalidation_window"`
+		RevalidationProbability *float64 `json:"revalidation_probability"`
+	}
+	if code := serve(t, h, http.MethodGet, "/caches/users/keys/user/1", &entry); code != http.StatusOK {
+		t.Fatalf("GET key status = %d, want 200", code)
+	}
+	if entry.Key != "user/1" || entry.Value != "loaded user/1" || entry.TTL != "1h0m0s" || loads != 1 {
+		t.Fatalf("entry = %+v, loads = %d", entry, loads)
+	}
+	if entry.RevalidationWindow == "" || entry.RevalidationProbability == nil || *entry.RevalidationProbability != 0 {
+		t.Fatalf("entry revalidation = %q, %v, want a window and probability 0", entry.RevalidationWindow, entry.RevalidationProbability)
+	}
+
+	if code := serve(t, h, http.MethodDelete, "/caches/users/keys/user/1", nil); code != http.StatusNoContent {
+		t.Fatalf("DELETE status = %d, want 204", code)
+	}
+	if code := serve(t, h, http.MethodGet, "/caches/users/keys/user/1", nil); code != http.StatusNotFound {
+		t.Fatalf("GET deleted key status = %d, want 404", code)
+	}
+}
+
+func TestHandler_Errors(t *testing.T) {
+	t.Parallel()
+
+	h := admin.NewHandler()
+	admin.Register(h, "users", newTestCache(t))
+
+	var body struct {
+		Error string `json:"error"`
+	}
+	if code := serve(t, h, http.MethodGet, "/caches/unknown", &body); code != http.StatusNotFound ||
+		!strings.Contains(body.Error, `cache "unknown" is not registered`) {
+		t.Fatalf("status = %d, error = %q", code, body.Error)
+	}
+	if code := serve(t, h, http.MethodDelete, "/caches/unknown/keys/key", nil); code != http.StatusNotFound {
+		t.Fatalf("DELETE on unknown cache status = %d, want 404", code)
+	}
+	if code := serve(t, h, http.MethodPost, "/caches/users/refresh/key", &body); code != http.StatusNotImplemented ||
+		!strings.Contains(body.Error, "WithRefreshLoader") {
+		t.Fatalf("refresh without loader status = %d, error = %q", code, body.Error)
+	}
+	if code := serve(t, h, http.MethodPost, "/caches/users/keys/key", nil); code != http.StatusMethodNotAllowed {
+		t.Fatalf("POST key status = %d, want 405", code)
+	}
+}
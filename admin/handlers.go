@@ -0,0 +1,274 @@
+package admin
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/abema/crema"
+)
+
+var errRefreshNotConfigured = errors.New("refresh is not configured for this cache; " +
+	"register a crema.CacheRefresher with WithRefreshLoader")
+
+type cacheSummary struct {
+	Name          string     `json:"name"`
+	Stats         *statsView `json:"stats,omitempty"`
+	InFlightLoads int        `json:"in_flight_loads"`
+}
+
+type cacheView struct {
+	Name     string         `json:"name"`
+	Config   configView     `json:"config"`
+	Stats    statsView      `json:"stats"`
+	InFlight []inFlightView `json:"in_flight"`
+}
+
+type configView struct {
+	Singleflight          bool    `json:"singleflight"`
+	RevalidationStrategy  string  `json:"revalidation_strategy"`
+	RevalidationWindow    string  `json:"revalidation_window"`
+	RevalidationSteepness float64 `json:"revalidation_steepness,omitempty"`
+	MaxLoadTimeout        string  `json:"max_load_timeout"`
+	FollowerWaitTimeout   string  `json:"follower_wait_timeout"`
+	ProviderGetTimeout    string  `json:"provider_get_timeout"`
+	ProviderSetTimeout    string  `json:"provider_set_timeout"`
+	AsyncSet              bool    `json:"async_set"`
+	WriteBehind           bool    `json:"write_behind"`
+	Refresh               bool    `json:"refresh"`
+}
+
+type statsView struct {
+	Gets          int64 `json:"gets"`
+	Hits          int64 `json:"hits"`
+	Sets          int64 `json:"sets"`
+	Deletes       int64 `json:"deletes"`
+	Loads         int64 `json:"loads"`
+	SharedLoads   int64 `json:"shared_loads"`
+	Revalidations int64 `json:"revalidations"`
+	StaleServed   int64 `json:"stale_served"`
+	LoadErrors    int64 `json:"load_errors"`
+}
+
+type inFlightView struct {
+	Key     string `json:"key"`
+	Waiters int    `json:"waiters"`
+}
+
+type entryView struct {
+	Key                     string    `json:"key"`
+	Value                   any       `json:"value"`
+	ExpireAt                time.Time `json:"expire_at"`
+	ExpireAtMillis          int64     `json:"expire_at_millis"`
+	RemainingTTL            string    `json:"remaining_ttl"`
+	TTL                     string    `json:"ttl,omitempty"`
+	LoadDuration            string    `json:"load_duration,omitempty"`
+	RevalidationWindow      string    `json:"revalidation_window,omitempty"`
+	RevalidationProbability *float64  `json:"revalidation_probability,omitempty"`
+}
+
+type errorView struct {
+	Error string `json:"error"`
+}
+
+func newStatsView(stats crema.CacheStats) statsView {
+	return statsView{
+		Gets:          stats.Gets,
+		Hits:          stats.Hits,
+		Sets:          stats.Sets,
+		Deletes:       stats.Deletes,
+		Loads:         stats.Loads,
+		SharedLoads:   stats.SharedLoads,
+		Revalidations: stats.Revalidations,
+		StaleServed:   stats.StaleServed,
+		LoadErrors:    stats.LoadErrors,
+	}
+}
+
+func newConfigView(config crema.CacheConfig, refresh bool) configView {
+	return configView{
+		Singleflight:          config.Singleflight,
+		RevalidationStrategy:  strategyName(config.RevalidationStrategy),
+		RevalidationWindow:    config.RevalidationWindow.String(),
+		RevalidationSteepness: config.RevalidationSteepness,
+		MaxLoadTimeout:        config.MaxLoadTimeout.String(),
+		FollowerWaitTimeout:   config.FollowerWaitTimeout.String(),
+		ProviderGetTimeout:    config.ProviderGetTimeout.String(),
+		ProviderSetTimeout:    config.ProviderSetTimeout.String(),
+		AsyncSet:              config.AsyncSet,
+		WriteBehind:           config.WriteBehind,
+		Refresh:               refresh,
+	}
+}
+
+// strategyName describes strategy with its fmt.Stringer, which the built-in
+// strategies implement, falling back to its type name.
+func strategyName(strategy crema.RevalidationStrategy) string {
+	if stringer, ok := strategy.(fmt.Stringer); ok {
+		return stringer.String()
+	}
+
+	return fmt.Sprintf("%T", strategy)
+}
+
+func newEntryView(value any, entry crema.RevalidationEntry) entryView {
+	view := entryView{
+		Key:            entry.Key,
+		Value:          value,
+		ExpireAt:       time.UnixMilli(entry.ExpireAtMillis).UTC(),
+		ExpireAtMillis: entry.ExpireAtMillis,
+		RemainingTTL:   (time.Duration(entry.RemainingMillis()) * time.Millisecond).String(),
+	}
+	if entry.TTL > 0 {
+		view.TTL = entry.TTL.String()
+	}
+	if entry.LoadDuration > 0 {
+		view.LoadDuration = entry.LoadDuration.String()
+	}
+
+	return view
+}
+
+// addRevalidation reports the revalidation window of the entry and how likely
+// a read would revalidate it now.
+func (v *entryView) addRevalidation(strategy crema.RevalidationStrategy, entry crema.RevalidationEntry) {
+	if strategy == nil {
+		return
+	}
+	probability := 1.0
+	if entry.RemainingMillis() > 0 {
+		probability = strategy.Probability(entry)
+	}
+	v.RevalidationWindow = crema.EntryRevalidationWindow(strategy, entry).String()
+	v.RevalidationProbability = &probability
+}
+
+func (h *Handler) listCaches(w http.ResponseWriter, _ *http.Request) {
+	names := h.names()
+	summaries := make([]cacheSummary, 0, len(names))
+	for _, name := range names {
+		cache, ok := h.lookup(name)
+		if !ok {
+			continue
+		}
+		summary := cacheSummary{Name: name}
+		if info, ok := cache.inspect(); ok {
+			stats := newStatsView(info.Stats)
+			summary.Stats = &stats
+			summary.InFlightLoads = len(info.InFlight)
+		}
+		summaries = append(summaries, summary)
+	}
+	writeJSON(w, http.StatusOK, summaries)
+}
+
+func (h *Handler) getCache(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	cache, ok := h.lookup(name)
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Errorf("cache %q is not registered", name))
+
+		return
+	}
+	info, ok := cache.inspect()
+	if !ok {
+		writeError(w, http.StatusNotImplemented, fmt.Errorf("cache %q does not implement crema.CacheInspector", name))
+
+		return
+	}
+
+	inFlight := make([]inFlightView, 0, len(info.InFlight))
+	for _, load := range info.InFlight {
+		inFlight = append(inFlight, inFlightView{Key: load.Key, Waiters: load.Waiters})
+	}
+	writeJSON(w, http.StatusOK, cacheView{
+		Name:     name,
+		Config:   newConfigView(info.Config, cache.refreshable()),
+		Stats:    newStatsView(info.Stats),
+		InFlight: inFlight,
+	})
+}
+
+func (h *Handler) getKey(w http.ResponseWriter, r *http.Request) {
+	cache, key, ok := h.keyRequest(w, r)
+	if !ok {
+		return
+	}
+	entry, found, err := cache.get(r.Context(), key)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+
+		return
+	}
+	if !found {
+		writeError(w, http.StatusNotFound, fmt.Errorf("key %q not found", key))
+
+		return
+	}
+	writeJSON(w, http.StatusOK, entry)
+}
+
+func (h *Handler) deleteKey(w http.ResponseWriter, r *http.Request) {
+	cache, key, ok := h.keyRequest(w, r)
+	if !ok {
+		return
+	}
+	if err := cache.delete(r.Context(), key); err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) refreshKey(w http.ResponseWriter, r *http.Request) {
+	cache, key, ok := h.keyRequest(w, r)
+	if !ok {
+		return
+	}
+	if err := cache.refresh(r.Context(), key); err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, errRefreshNotConfigured) {
+			status = http.StatusNotImplemented
+		}
+		writeError(w, status, err)
+
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// keyRequest resolves the cache and key of a request, writing an error
+// response when the cache is not registered.
+func (h *Handler) keyRequest(w http.ResponseWriter, r *http.Request) (registeredCache, string, bool) {
+	name := r.PathValue("name")
+	cache, ok := h.lookup(name)
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Errorf("cache %q is not registered", name))
+
+		return nil, "", false
+	}
+
+	return cache, r.PathValue("key"), true
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	data, err := json.MarshalIndent(body, "", "  ")
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_, _ = w.Write(append(data, '\n'))
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	data, _ := json.Marshal(errorView{Error: err.Error()})
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_, _ = w.Write(append(data, '\n'))
+}
@@ -2,9 +2,11 @@ package crema
 
 import (
 	"context"
+	"errors"
 	"log/slog"
 	"math"
 	"math/rand/v2"
+	"sync/atomic"
 	"time"
 )
 
@@ -23,18 +25,59 @@ type Cache[V any, S any] interface {
 	GetOrLoad(ctx context.Context, key string, ttl time.Duration, loader CacheLoadFunc[V]) (V, error)
 }
 
+// CacheResultLoader is implemented by caches that report how GetOrLoad
+// obtained a value. Caches built by NewCache implement it; use a type
+// assertion to access it.
+type CacheResultLoader[V any] interface {
+	// GetOrLoadResult is like GetOrLoad but also reports whether the value was
+	// cached, loaded, shared or stale, when it expires and how long loading took.
+	GetOrLoadResult(ctx context.Context, key string, ttl time.Duration, loader CacheLoadFunc[V]) (LoadResult[V], error)
+}
+
+// CacheRefresher is implemented by caches that can reload a key on demand.
+// Caches built by NewCache implement it; use a type assertion to access it.
+type CacheRefresher[V any] interface {
+	// Refresh loads key with loader and stores the result regardless of whether
+	// the cached value is fresh, sharing an in-flight load for key if any.
+	Refresh(ctx context.Context, key string, ttl time.Duration, loader CacheLoadFunc[V]) error
+}
+
+// CacheCloser is implemented by caches that can be shut down gracefully.
+// Caches built by NewCache implement it; use a type assertion to access it.
+type CacheCloser interface {
+	// Close stops accepting new loads and waits until in-flight loads and pending
+	// background writes finish or ctx is done, canceling loads still running then.
+	// Cached values can still be read after Close.
+	Close(ctx context.Context) error
+}
+
+var (
+	_ CacheResultLoader[any] = (*cacheImpl[any, any])(nil)
+	_ CacheRefresher[any]    = (*cacheImpl[any, any])(nil)
+	_ CacheCloser            = (*cacheImpl[any, any])(nil)
+)
+
 type cacheImpl[V any, S any] struct {
-	_                              noCopy
-	provider                       CacheProvider[S]
-	codec                          CacheStorageCodec[V, S]
-	logger                         *slog.Logger
-	metrics                        MetricsProvider
-	internalLoader                 internalLoader[V]
-	now                            func() time.Time
-	steepness                      float64
-	revalidationWindowMilliseconds int64
-	maxLoadTimeout                 time.Duration
-	random                         func() float64 // must goroutine safe
+	_                        noCopy
+	provider                 CacheProvider[S]
+	codec                    CacheStorageCodec[V, S]
+	logger                   *slog.Logger
+	metrics                  MetricsProvider
+	internalLoader           internalLoader[V]
+	now                      func() time.Time
+	revalidation             RevalidationStrategy
+	maxLoadTimeout           time.Duration
+	providerGetTimeout       time.Duration
+	providerSetTimeout       time.Duration
+	asyncSet                 bool
+	writeBehindConfig        *WriteBehindConfig
+	writeBehind              *writeBehindQueue[V]
+	lifecycle                lifecycle
+	deleteOnChecksumMismatch bool
+	decodeErrorPolicy        DecodeErrorPolicy
+	loadShedPolicy           LoadShedPolicy
+	random                   func() float64 // must goroutine safe
+	stats                    cacheStats
 }
 
 // CacheObject wraps a cached value with its absolute expiration time.
@@ -43,6 +86,12 @@ type CacheObject[V any] struct {
 	Value V
 	// ExpireAtMillis is the absolute expiration time in milliseconds since epoch.
 	ExpireAtMillis int64
+	// LoadDurationMillis is how long the loader took to produce Value, in
+	// milliseconds. It is zero when unknown.
+	LoadDurationMillis int64 `json:",omitempty"`
+	// TTLMillis is the time to live Value was stored with, in milliseconds.
+	// It is zero when unknown.
+	TTLMillis int64 `json:",omitempty"`
 }
 
 // CacheLoadFunc loads a value when it is missing or needs revalidation.
@@ -51,6 +100,19 @@ type CacheLoadFunc[V any] func(ctx context.Context) (V, error)
 // CacheOption configures a Cache instance.
 type CacheOption[V any, S any] func(*cacheImpl[V, S])
 
+// DecodeErrorPolicy controls how Get handles stored entries that fail to decode.
+type DecodeErrorPolicy int
+
+const (
+	// DecodeErrorPolicyReturn returns a *DecodeError and keeps the entry.
+	DecodeErrorPolicyReturn DecodeErrorPolicy = iota
+	// DecodeErrorPolicyDelete deletes the entry and returns a *DecodeError.
+	DecodeErrorPolicyDelete
+	// DecodeErrorPolicyDeleteAsMiss deletes the entry and reports a cache miss,
+	// so plain Get recovers on its own as well as GetOrLoad.
+	DecodeErrorPolicyDeleteAsMiss
+)
+
 const defaultRevalidationWindowMilliseconds = 300000
 
 // WithLogger overrides the default logger used for cache warnings.
@@ -82,13 +144,26 @@ func WithDirectLoader[V any, S any]() CacheOption[V, S] {
 	}
 }
 
-// WithRevalidationWindow sets the target revalidation window duration.
+// WithRevalidationWindow sets the target revalidation window duration of the
+// default exponential revalidation strategy.
 func WithRevalidationWindow[V any, S any](duration time.Duration) CacheOption[V, S] {
-	steepness, revalidationWindowMilliseconds := calculateSteepnessAndRevalidationWindow(duration.Milliseconds())
+	return WithRevalidationStrategy[V, S](NewExponentialRevalidation(duration))
+}
 
+// WithRevalidationWindowRatio sets the revalidation window to fraction of each
+// entry's TTL using the exponential curve, e.g. 0.1 for the last 10% of the TTL.
+func WithRevalidationWindowRatio[V any, S any](fraction float64) CacheOption[V, S] {
+	return WithRevalidationStrategy[V, S](NewRelativeExponentialRevalidation(fraction))
+}
+
+// WithRevalidationStrategy replaces the strategy deciding when unexpired
+// entries are revalidated early. The default is NewExponentialRevalidation
+// with a 5 minute window.
+func WithRevalidationStrategy[V any, S any](strategy RevalidationStrategy) CacheOption[V, S] {
 	return func(c *cacheImpl[V, S]) {
-		c.steepness = steepness
-		c.revalidationWindowMilliseconds = revalidationWindowMilliseconds
+		if strategy != nil {
+			c.revalidation = strategy
+		}
 	}
 }
 
@@ -104,21 +179,164 @@ func WithMaxLoadTimeout[V any, S any](duration time.Duration) CacheOption[V, S]
 	}
 }
 
+// WithFollowerWaitTimeout bounds how long GetOrLoad waits for a load started
+// by another caller for the same key. On timeout it returns the cached value,
+// even if expired, when one was found, and ErrFollowerTimeout otherwise, while
+// the leader keeps loading to refresh the cache. It is independent of
+// WithMaxLoadTimeout, which bounds the leader's loader, and ignored with
+// WithDirectLoader. A non-positive duration disables the timeout.
+func WithFollowerWaitTimeout[V any, S any](duration time.Duration) CacheOption[V, S] {
+	return func(c *cacheImpl[V, S]) {
+		if loader, ok := c.internalLoader.(*singleflightLoader[V]); ok {
+			loader.followerTimeout = duration
+		}
+	}
+}
+
+// WithProviderGetTimeout bounds each CacheProvider.Get call made by the cache,
+// and the CacheProvider.Delete of undecodable entries found by those reads.
+// A non-positive duration disables the timeout and uses the caller context as is.
+func WithProviderGetTimeout[V any, S any](duration time.Duration) CacheOption[V, S] {
+	return func(c *cacheImpl[V, S]) {
+		c.providerGetTimeout = duration
+	}
+}
+
+// WithProviderSetTimeout bounds each CacheProvider.Set call made by the cache.
+// A non-positive duration disables the timeout and uses the caller context as is.
+func WithProviderSetTimeout[V any, S any](duration time.Duration) CacheOption[V, S] {
+	return func(c *cacheImpl[V, S]) {
+		c.providerSetTimeout = duration
+	}
+}
+
+// WithAsyncSet makes GetOrLoad store freshly loaded values in a background
+// goroutine detached from the caller's cancellation, so the caller does not wait
+// for the write and a canceled request does not lose the value.
+// Combine it with WithProviderSetTimeout to bound the detached write.
+func WithAsyncSet[V any, S any]() CacheOption[V, S] {
+	return func(c *cacheImpl[V, S]) {
+		c.asyncSet = true
+	}
+}
+
+// WithWriteBehind makes GetOrLoad hand freshly loaded values to a bounded queue
+// drained by worker goroutines, returning to the caller before the write.
+// Pending writes are flushed by Close. It takes precedence over WithAsyncSet.
+func WithWriteBehind[V any, S any](config WriteBehindConfig) CacheOption[V, S] {
+	return func(c *cacheImpl[V, S]) {
+		c.writeBehindConfig = &config
+	}
+}
+
+// WithLoadConcurrencyLimit bounds concurrent singleflight leader loads globally
+// and per key prefix, queueing or shedding loads beyond the limit.
+// It is ignored with WithDirectLoader.
+func WithLoadConcurrencyLimit[V any, S any](limit LoadConcurrencyLimit) CacheOption[V, S] {
+	return func(c *cacheImpl[V, S]) {
+		c.loadShedPolicy = limit.ShedPolicy
+		if loader, ok := c.internalLoader.(*singleflightLoader[V]); ok {
+			loader.limiter = newLoadLimiter(limit)
+		}
+	}
+}
+
+// WithLoadRateLimit rate-limits singleflight leader loads with a token bucket,
+// globally or per partition. Followers still coalesce onto the leader, so only
+// actual loader invocations consume tokens. It can be given more than once to
+// combine, e.g., a global and a partitioned limit; a load takes a token from
+// every limit or from none, and the limit rejecting it selects the ShedPolicy.
+// It is ignored with WithDirectLoader.
+func WithLoadRateLimit[V any, S any](limit LoadRateLimit) CacheOption[V, S] {
+	return func(c *cacheImpl[V, S]) {
+		if limit.Rate <= 0 {
+			return
+		}
+		if loader, ok := c.internalLoader.(*singleflightLoader[V]); ok {
+			loader.rateLimiters = append(loader.rateLimiters, newRateLimiter(limit))
+		}
+	}
+}
+
+// WithLoadRetry retries failed singleflight leader loads with exponential
+// backoff and jitter, so all waiting callers share one retry sequence. Retries
+// reuse the leader's concurrency admission but take a token from every
+// WithLoadRateLimit limit, giving up with the last error when one has none left.
+// They are bounded by WithMaxLoadTimeout. It is ignored with WithDirectLoader.
+func WithLoadRetry[V any, S any](policy RetryPolicy) CacheOption[V, S] {
+	return func(c *cacheImpl[V, S]) {
+		if loader, ok := c.internalLoader.(*singleflightLoader[V]); ok {
+			loader.retry = &policy
+		}
+	}
+}
+
+// WithLoadHedging makes singleflight leaders start a second loader call when the
+// first is slower than the policy's delay or observed latency percentile, using
+// whichever succeeds first and canceling the other. Followers share the result.
+// Hedged calls are skipped unless WithLoadRateLimit and WithLoadConcurrencyLimit
+// admit them without waiting. It is ignored with WithDirectLoader.
+func WithLoadHedging[V any, S any](policy HedgePolicy) CacheOption[V, S] {
+	return func(c *cacheImpl[V, S]) {
+		if loader, ok := c.internalLoader.(*singleflightLoader[V]); ok {
+			loader.hedger = newLoadHedger(policy)
+		}
+	}
+}
+
+// WithDeleteOnChecksumMismatch deletes entries whose decode fails with
+// ErrChecksumMismatch or ErrUnsupportedChecksumTypeID, so corrupted values,
+// including ones with a corrupted checksum header and legacy entries written
+// without a checksum, self-heal instead of failing every read until their TTL
+// elapses. Use it together with NewChecksumCodec.
+func WithDeleteOnChecksumMismatch[V any, S any]() CacheOption[V, S] {
+	return func(c *cacheImpl[V, S]) {
+		c.deleteOnChecksumMismatch = true
+	}
+}
+
+// WithDecodeErrorPolicy sets how undecodable entries are handled.
+// The default is DecodeErrorPolicyReturn.
+func WithDecodeErrorPolicy[V any, S any](policy DecodeErrorPolicy) CacheOption[V, S] {
+	return func(c *cacheImpl[V, S]) {
+		c.decodeErrorPolicy = policy
+	}
+}
+
+// WithClock replaces time.Now as the source of the current time for expiry and
+// revalidation decisions, e.g. with a fake clock in tests. Timeouts, backoffs
+// and load durations still use real time. now must be safe for concurrent use.
+func WithClock[V any, S any](now func() time.Time) CacheOption[V, S] {
+	return func(c *cacheImpl[V, S]) {
+		if now != nil {
+			c.now = now
+		}
+	}
+}
+
+// WithRandomSource replaces the uniform [0, 1) source of revalidation draws,
+// e.g. with scripted values in tests. random must be safe for concurrent use.
+func WithRandomSource[V any, S any](random func() float64) CacheOption[V, S] {
+	return func(c *cacheImpl[V, S]) {
+		if random != nil {
+			c.random = random
+		}
+	}
+}
+
 // NewCache constructs a Cache with defaults and optional overrides.
 func NewCache[V any, S any](provider CacheProvider[S], codec CacheStorageCodec[V, S], opts ...CacheOption[V, S]) Cache[V, S] {
-	steepness, revalidationWindowMilliseconds := calculateSteepnessAndRevalidationWindow(defaultRevalidationWindowMilliseconds)
 	metrics := NoopMetricsProvider{}
 	cache := &cacheImpl[V, S]{
-		provider:                       provider,
-		codec:                          codec,
-		logger:                         slog.New(noopLogHandler{}),
-		metrics:                        metrics,
-		internalLoader:                 newSingleflightLoader[V](metrics, 0),
-		now:                            time.Now,
-		random:                         rand.Float64,
-		steepness:                      steepness,
-		revalidationWindowMilliseconds: revalidationWindowMilliseconds,
-		maxLoadTimeout:                 0,
+		provider:       provider,
+		codec:          codec,
+		logger:         slog.New(noopLogHandler{}),
+		metrics:        metrics,
+		internalLoader: newSingleflightLoader[V](metrics, 0),
+		now:            time.Now,
+		random:         rand.Float64,
+		revalidation:   NewExponentialRevalidation(defaultRevalidationWindowMilliseconds * time.Millisecond),
+		maxLoadTimeout: 0,
 	}
 	for _, opt := range opts {
 		if opt == nil {
@@ -126,17 +344,28 @@ func NewCache[V any, S any](provider CacheProvider[S], codec CacheStorageCodec[V
 		}
 		opt(cache)
 	}
+	if loader, ok := cache.internalLoader.(*singleflightLoader[V]); ok {
+		loader.logger = cache.logger
+	}
+	if cache.writeBehindConfig != nil {
+		cache.writeBehind = newWriteBehindQueue(*cache.writeBehindConfig, cache.metrics, cache.logger, cache.setLoaded)
+	}
 
 	return cache
 }
 
 // Get returns the cached entry for key, if present.
+// Provider failures are returned as *ProviderError and undecodable entries as
+// *DecodeError, subject to the configured DecodeErrorPolicy.
 func (c *cacheImpl[V, S]) Get(ctx context.Context, key string) (CacheObject[V], bool, error) {
 	c.metrics.RecordCacheGet(ctx)
+	c.stats.gets.Add(1)
 
-	rv, exists, err := c.provider.Get(ctx, key)
+	getCtx, cancel := withOptionalTimeout(ctx, c.providerGetTimeout)
+	defer cancel()
+	rv, exists, err := c.provider.Get(getCtx, key)
 	if err != nil {
-		return CacheObject[V]{}, false, err
+		return CacheObject[V]{}, false, &ProviderError{Op: "get", Key: key, Err: err}
 	}
 	if !exists {
 		return CacheObject[V]{}, false, nil
@@ -144,9 +373,10 @@ func (c *cacheImpl[V, S]) Get(ctx context.Context, key string) (CacheObject[V],
 
 	co, err := c.codec.Decode(rv)
 	if err != nil {
-		return CacheObject[V]{}, false, err
+		return CacheObject[V]{}, false, c.handleDecodeError(ctx, key, err)
 	}
 	c.metrics.RecordCacheHit(ctx)
+	c.stats.hits.Add(1)
 
 	return co, true, nil
 }
@@ -154,6 +384,7 @@ func (c *cacheImpl[V, S]) Get(ctx context.Context, key string) (CacheObject[V],
 // Set stores a cache entry, skipping writes when already expired.
 func (c *cacheImpl[V, S]) Set(ctx context.Context, key string, value CacheObject[V]) error {
 	c.metrics.RecordCacheSet(ctx)
+	c.stats.sets.Add(1)
 
 	encoded, err := c.codec.Encode(value)
 	if err != nil {
@@ -164,64 +395,318 @@ func (c *cacheImpl[V, S]) Set(ctx context.Context, key string, value CacheObject
 		return nil
 	}
 
-	return c.provider.Set(ctx, key, encoded, ttl)
+	setCtx, cancel := withOptionalTimeout(ctx, c.providerSetTimeout)
+	defer cancel()
+	if err := c.provider.Set(setCtx, key, encoded, ttl); err != nil {
+		return &ProviderError{Op: "set", Key: key, Err: err}
+	}
+
+	return nil
 }
 
 // Delete removes a cached entry for key.
 func (c *cacheImpl[V, S]) Delete(ctx context.Context, key string) error {
 	c.metrics.RecordCacheDelete(ctx)
+	c.stats.deletes.Add(1)
 
-	return c.provider.Delete(ctx, key)
+	if err := c.provider.Delete(ctx, key); err != nil {
+		return &ProviderError{Op: "delete", Key: key, Err: err}
+	}
+
+	return nil
 }
 
 // GetOrLoad returns a cached value or uses loader when missing or revalidating.
 func (c *cacheImpl[V, S]) GetOrLoad(ctx context.Context, key string, ttl time.Duration, loader CacheLoadFunc[V]) (V, error) {
-	value, found, err := c.Get(ctx, key)
-	if err != nil {
-		c.logger.Warn("failed to get from cache", slog.String("key", key), slog.String("error", err.Error()))
-		found = false
+	result, err := c.GetOrLoadResult(ctx, key, ttl, loader)
+
+	return result.Value, err
+}
+
+// GetOrLoadResult is like GetOrLoad but also reports where the value came from.
+func (c *cacheImpl[V, S]) GetOrLoadResult(
+	ctx context.Context,
+	key string,
+	ttl time.Duration,
+	loader CacheLoadFunc[V],
+) (LoadResult[V], error) {
+	value, found := c.lookup(ctx, key)
+	if found {
+		c.observeRequest(key, c.now().UnixMilli(), value, ttl)
 	}
-	if found && !c.shouldRevalidate(c.now().UnixMilli(), value.ExpireAtMillis) {
-		return value.Value, nil
+
+	return c.loadIfDue(ctx, key, ttl, loader, value, found)
+}
+
+// loadIfDue returns value when it was found and is not due for revalidation,
+// and loads key with loader otherwise.
+func (c *cacheImpl[V, S]) loadIfDue(
+	ctx context.Context,
+	key string,
+	ttl time.Duration,
+	loader CacheLoadFunc[V],
+	value CacheObject[V],
+	found bool,
+) (LoadResult[V], error) {
+	if found && !c.shouldRevalidate(key, c.now().UnixMilli(), value, ttl) {
+		return LoadResult[V]{Value: value.Value, Source: LoadSourceHit, ExpireAtMillis: value.ExpireAtMillis}, nil
 	}
 
-	v, leader, err := c.internalLoader.load(ctx, key, loader)
+	if !c.lifecycle.acquire() {
+		return LoadResult[V]{}, ErrCacheClosed
+	}
+	defer c.lifecycle.release()
+
+	var loaderMillis atomic.Int64
+	start := time.Now()
+	v, leader, err := c.internalLoader.load(ctx, key, c.timeLoader(loader, &loaderMillis))
+	loadDuration := time.Since(start)
+	c.stats.recordLoad(leader, err)
 	if err != nil {
-		var zero V
+		if found && c.shouldServeStale(err) {
+			c.stats.staleServed.Add(1)
+
+			return LoadResult[V]{
+				Value:          value.Value,
+				Source:         LoadSourceStale,
+				ExpireAtMillis: value.ExpireAtMillis,
+				LoadDuration:   loadDuration,
+			}, nil
+		}
 
-		return zero, err
+		return LoadResult[V]{}, err
+	}
+
+	result := LoadResult[V]{
+		Value:          v,
+		Source:         loadSource(leader, found),
+		ExpireAtMillis: c.now().Add(ttl).UnixMilli(),
+		LoadDuration:   loadDuration,
 	}
 	if leader {
-		co := CacheObject[V]{
-			Value:          v,
-			ExpireAtMillis: c.now().Add(ttl).UnixMilli(),
+		if found {
+			c.stats.revalidations.Add(1)
 		}
-		if err := c.Set(ctx, key, co); err != nil {
-			c.logger.Warn("failed to set cache", slog.String("key", key), slog.String("error", err.Error()))
+		c.storeLoaded(ctx, key, CacheObject[V]{
+			Value:              v,
+			ExpireAtMillis:     result.ExpireAtMillis,
+			LoadDurationMillis: loaderMillis.Load(),
+			TTLMillis:          ttl.Milliseconds(),
+		})
+	}
+
+	return result, nil
+}
+
+func loadSource(leader, found bool) LoadSource {
+	switch {
+	case !leader:
+		return LoadSourceShared
+	case found:
+		return LoadSourceRevalidate
+	default:
+		return LoadSourceMiss
+	}
+}
+
+// Refresh loads key with loader and stores the result regardless of freshness.
+// A load already in flight for key is shared and stored by its leader instead.
+func (c *cacheImpl[V, S]) Refresh(ctx context.Context, key string, ttl time.Duration, loader CacheLoadFunc[V]) error {
+	if !c.lifecycle.acquire() {
+		return ErrCacheClosed
+	}
+	defer c.lifecycle.release()
+
+	var loaderMillis atomic.Int64
+	v, leader, err := c.internalLoader.load(ctx, key, c.timeLoader(loader, &loaderMillis))
+	c.stats.recordLoad(leader, err)
+	if err != nil {
+		return err
+	}
+	if leader {
+		c.storeLoaded(ctx, key, CacheObject[V]{
+			Value:              v,
+			ExpireAtMillis:     c.now().Add(ttl).UnixMilli(),
+			LoadDurationMillis: loaderMillis.Load(),
+			TTLMillis:          ttl.Milliseconds(),
+		})
+	}
+
+	return nil
+}
+
+// timeLoader wraps loader to store the duration of its last successful call in
+// millis. Retried and hedged calls of one load may run it more than once.
+func (c *cacheImpl[V, S]) timeLoader(loader CacheLoadFunc[V], millis *atomic.Int64) CacheLoadFunc[V] {
+	return func(ctx context.Context) (V, error) {
+		start := time.Now()
+		v, err := loader(ctx)
+		if err == nil {
+			millis.Store(time.Since(start).Milliseconds())
 		}
+
+		return v, err
 	}
+}
 
-	return v, nil
+// shouldServeStale reports whether a load error allows falling back to the cached value.
+func (c *cacheImpl[V, S]) shouldServeStale(err error) bool {
+	var rateLimitErr *rateLimitError
+	switch {
+	case errors.Is(err, ErrFollowerTimeout):
+		return true
+	case errors.Is(err, ErrLoadRejected):
+		return c.loadShedPolicy == LoadShedPolicyServeStale
+	case errors.As(err, &rateLimitErr):
+		return rateLimitErr.shedPolicy == LoadShedPolicyServeStale
+	default:
+		return false
+	}
 }
 
-// shouldRevalidate returns true if the entry is expired, or if the remaining
-// TTL is within the revalidation window and a random draw falls under the
-// revalidation probability p(t)=1-exp(-steepness*t).
-func (c *cacheImpl[V, S]) shouldRevalidate(nowMillis int64, expireAtMillis int64) bool {
-	remainMillis := expireAtMillis - nowMillis
-	if remainMillis <= 0 {
+// lookup reads key for GetOrLoad, logging lookup failures and treating them as misses.
+func (c *cacheImpl[V, S]) lookup(ctx context.Context, key string) (CacheObject[V], bool) {
+	value, found, err := c.Get(ctx, key)
+	if err != nil {
+		if errors.Is(err, ErrDecode) {
+			c.logger.Warn("failed to decode cache entry", slog.String("key", key), slog.String("error", err.Error()))
+		} else {
+			c.logger.Warn("failed to get from cache", slog.String("key", key), slog.String("error", err.Error()))
+		}
+
+		return CacheObject[V]{}, false
+	}
+
+	return value, found
+}
+
+// storeLoaded writes a freshly loaded value using the configured write mode:
+// write-behind queue, detached goroutine, or inline.
+func (c *cacheImpl[V, S]) storeLoaded(ctx context.Context, key string, co CacheObject[V]) {
+	switch {
+	case c.writeBehind != nil:
+		c.writeBehind.enqueue(ctx, key, co)
+	case c.asyncSet && c.lifecycle.acquire():
+		go func() {
+			defer c.lifecycle.release()
+			c.setLoaded(context.WithoutCancel(ctx), key, co)
+		}()
+	default:
+		c.setLoaded(ctx, key, co)
+	}
+}
+
+// Close stops accepting loads, drains in-flight loads and background writes,
+// and flushes the write-behind queue. Loads still running when ctx is done are
+// canceled and ctx.Err() is returned.
+func (c *cacheImpl[V, S]) Close(ctx context.Context) error {
+	c.lifecycle.close()
+	c.internalLoader.stop()
+
+	err := c.lifecycle.wait(ctx)
+	if loaderErr := c.internalLoader.drain(ctx); err == nil {
+		err = loaderErr
+	}
+	if c.writeBehind != nil {
+		if writeErr := c.writeBehind.close(ctx); err == nil {
+			err = writeErr
+		}
+	}
+
+	return err
+}
+
+// setLoaded stores a freshly loaded value, logging failures instead of
+// returning them since the loaded value is still served.
+func (c *cacheImpl[V, S]) setLoaded(ctx context.Context, key string, co CacheObject[V]) {
+	if err := c.Set(ctx, key, co); err != nil {
+		c.logger.Warn("failed to set cache", slog.String("key", key), slog.String("error", err.Error()))
+	}
+}
+
+// handleDecodeError wraps a codec error in *DecodeError and applies the
+// DecodeErrorPolicy. It returns nil when the entry should be treated as a miss.
+func (c *cacheImpl[V, S]) handleDecodeError(ctx context.Context, key string, err error) error {
+	deleteEntry := c.decodeErrorPolicy != DecodeErrorPolicyReturn ||
+		(c.deleteOnChecksumMismatch && failedChecksumVerification(err))
+	if deleteEntry {
+		c.deleteUndecodable(ctx, key, err)
+	}
+	if c.decodeErrorPolicy == DecodeErrorPolicyDeleteAsMiss {
+		return nil
+	}
+
+	return &DecodeError{Key: key, Err: err}
+}
+
+// deleteUndecodable removes an entry that failed to decode, bounded by the
+// provider get timeout since it runs as part of a read.
+// Failures are only logged since the decode error is handled by the caller.
+func (c *cacheImpl[V, S]) deleteUndecodable(ctx context.Context, key string, decodeErr error) {
+	deleteCtx, cancel := withOptionalTimeout(ctx, c.providerGetTimeout)
+	defer cancel()
+	if err := c.Delete(deleteCtx, key); err != nil {
+		c.logger.Warn("failed to delete undecodable cache entry", slog.String("key", key), slog.String("error", err.Error()))
+
+		return
+	}
+	c.logger.Warn("deleted undecodable cache entry", slog.String("key", key), slog.String("error", decodeErr.Error()))
+}
+
+// shouldRevalidate returns true if value is expired, or if a random draw
+// falls under the revalidation probability of the configured strategy.
+func (c *cacheImpl[V, S]) shouldRevalidate(key string, nowMillis int64, value CacheObject[V], ttl time.Duration) bool {
+	if value.ExpireAtMillis-nowMillis <= 0 {
 		return true
 	}
 
-	if remainMillis > c.revalidationWindowMilliseconds {
+	p := c.revalidation.Probability(newRevalidationEntry(key, nowMillis, value, ttl))
+	if p <= 0 {
 		return false
 	}
 
-	p := 1.0 - math.Exp(-c.steepness*float64(remainMillis))
-
 	return c.random() < p
 }
 
+// observeRequest reports a read of value to strategies learning from traffic.
+// Only reads serving callers are reported, not lookups such as Warm's.
+func (c *cacheImpl[V, S]) observeRequest(key string, nowMillis int64, value CacheObject[V], ttl time.Duration) {
+	if observer, ok := c.revalidation.(RevalidationObserver); ok {
+		observer.ObserveRequest(newRevalidationEntry(key, nowMillis, value, ttl), c.random)
+	}
+}
+
+// newRevalidationEntry describes value to the revalidation strategy. The TTL
+// value was stored with takes precedence over the caller's ttl.
+func newRevalidationEntry[V any](key string, nowMillis int64, value CacheObject[V], ttl time.Duration) RevalidationEntry {
+	if value.TTLMillis > 0 {
+		ttl = time.Duration(value.TTLMillis) * time.Millisecond
+	}
+
+	return RevalidationEntry{
+		Key:            key,
+		NowMillis:      nowMillis,
+		ExpireAtMillis: value.ExpireAtMillis,
+		TTL:            ttl,
+		LoadDuration:   time.Duration(value.LoadDurationMillis) * time.Millisecond,
+	}
+}
+
+// failedChecksumVerification reports whether err is a NewChecksumCodec
+// verification failure: a mismatching checksum or an unreadable header.
+func failedChecksumVerification(err error) bool {
+	return errors.Is(err, ErrChecksumMismatch) || errors.Is(err, ErrUnsupportedChecksumTypeID)
+}
+
+// withOptionalTimeout derives a context bounded by timeout when it is positive.
+func withOptionalTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return ctx, func() {}
+	}
+
+	return context.WithTimeout(ctx, timeout)
+}
+
 // calculateSteepnessAndRevalidationWindow derives the steepness for
 // p(t)=1-exp(-steepness*t) so that p(targetRevalidationWindowMilliseconds)=0.999,
 // then returns the smallest window (in milliseconds) where p(t) reaches 0.995.
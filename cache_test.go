@@ -127,9 +127,16 @@ func TestCache_GetPropagatesProviderGetError(t *testing.T) {
 	cache := NewCache(provider, NoopCacheStorageCodec[int]{})
 
 	_, ok, err := cache.Get(context.Background(), "key")
-	if err != expectErr {
+	if !errors.Is(err, expectErr) {
 		t.Fatalf("expected error %v, got %v", expectErr, err)
 	}
+	var providerErr *ProviderError
+	if !errors.As(err, &providerErr) || providerErr.Op != "get" {
+		t.Fatalf("expected *ProviderError for get, got %v", err)
+	}
+	if !errors.Is(err, ErrProvider) || errors.Is(err, ErrDecode) {
+		t.Fatalf("expected error to be classified as provider error, got %v", err)
+	}
 	if ok {
 		t.Fatalf("expected ok=false on error")
 	}
@@ -184,8 +191,8 @@ func TestCache_SetPropagatesProviderSetError(t *testing.T) {
 		Value:          1,
 		ExpireAtMillis: 2000,
 	})
-	if err != expectErr {
-		t.Fatalf("expected error %v, got %v", expectErr, err)
+	if !errors.Is(err, expectErr) || !errors.Is(err, ErrProvider) {
+		t.Fatalf("expected provider error wrapping %v, got %v", expectErr, err)
 	}
 }
 
@@ -214,8 +221,8 @@ func TestCache_DeletePropagatesProviderError(t *testing.T) {
 	provider := &errorProvider[CacheObject[int]]{deleteErr: expectErr}
 	cache := NewCache(provider, NoopCacheStorageCodec[int]{})
 
-	if err := cache.Delete(context.Background(), "key"); err != expectErr {
-		t.Fatalf("expected error %v, got %v", expectErr, err)
+	if err := cache.Delete(context.Background(), "key"); !errors.Is(err, expectErr) || !errors.Is(err, ErrProvider) {
+		t.Fatalf("expected provider error wrapping %v, got %v", expectErr, err)
 	}
 }
 
@@ -250,9 +257,136 @@ func TestCache_GetDecodeError(t *testing.T) {
 	if err == nil {
 		t.Fatal("expected decode error, got nil")
 	}
+	var decodeErr *DecodeError
+	if !errors.As(err, &decodeErr) || decodeErr.Key != "key" {
+		t.Fatalf("expected *DecodeError for key, got %v", err)
+	}
+	if !errors.Is(err, ErrDecode) || errors.Is(err, ErrProvider) {
+		t.Fatalf("expected error to be classified as decode error, got %v", err)
+	}
+	if ok {
+		t.Fatalf("expected ok=false on decode error")
+	}
+	if _, exists := provider.items["key"]; !exists {
+		t.Fatalf("expected entry to be kept with the default policy")
+	}
+}
+
+func TestCache_GetDecodeErrorPolicyDelete(t *testing.T) {
+	t.Parallel()
+
+	provider := &byteProvider{items: make(map[string][]byte)}
+	provider.items["key"] = []byte("{")
+	cache := NewCache(provider, JSONByteStringCodec[int]{}, WithDecodeErrorPolicy[int, []byte](DecodeErrorPolicyDelete))
+
+	_, ok, err := cache.Get(context.Background(), "key")
+	if !errors.Is(err, ErrDecode) {
+		t.Fatalf("expected decode error, got %v", err)
+	}
 	if ok {
 		t.Fatalf("expected ok=false on decode error")
 	}
+	if _, exists := provider.items["key"]; exists {
+		t.Fatalf("expected undecodable entry to be deleted")
+	}
+}
+
+func TestCache_GetDecodeErrorPolicyDeleteAsMiss(t *testing.T) {
+	t.Parallel()
+
+	provider := &byteProvider{items: make(map[string][]byte)}
+	provider.items["key"] = []byte("{")
+	cache := NewCache(provider, JSONByteStringCodec[int]{}, WithDecodeErrorPolicy[int, []byte](DecodeErrorPolicyDeleteAsMiss))
+
+	_, ok, err := cache.Get(context.Background(), "key")
+	if err != nil {
+		t.Fatalf("expected miss without error, got %v", err)
+	}
+	if ok {
+		t.Fatalf("expected ok=false for undecodable entry")
+	}
+	if _, exists := provider.items["key"]; exists {
+		t.Fatalf("expected undecodable entry to be deleted")
+	}
+}
+
+func TestCache_GetOrLoadDecodeErrorPolicyDeleteHeals(t *testing.T) {
+	t.Parallel()
+
+	provider := &byteProvider{items: make(map[string][]byte)}
+	provider.items["key"] = []byte("{")
+	cache := NewCache(provider, JSONByteStringCodec[int]{}, WithDecodeErrorPolicy[int, []byte](DecodeErrorPolicyDelete))
+	impl := cache.(*cacheImpl[int, []byte])
+	impl.now = func() time.Time { return time.UnixMilli(1000) }
+
+	value, err := cache.GetOrLoad(context.Background(), "key", time.Second, func(context.Context) (int, error) {
+		return 5, nil
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if value != 5 {
+		t.Fatalf("expected loaded value 5, got %d", value)
+	}
+
+	got, ok, err := cache.Get(context.Background(), "key")
+	if err != nil || !ok {
+		t.Fatalf("expected healed entry, got ok=%v err=%v", ok, err)
+	}
+	if got.Value != 5 {
+		t.Fatalf("expected stored value 5, got %d", got.Value)
+	}
+}
+
+func TestCache_GetDeletesEntryOnChecksumMismatch(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		stored  []byte
+		wantErr error
+	}{
+		"corrupted payload":     {stored: []byte{ChecksumTypeIDCRC32C, 0x00, 0x00, 0x00, 0x00, '{'}, wantErr: ErrChecksumMismatch},
+		"legacy unchecksummed":  {stored: []byte("5"), wantErr: ErrUnsupportedChecksumTypeID},
+		"corrupted header byte": {stored: []byte{0xFF, 0x00, 0x00, 0x00, 0x00, '5'}, wantErr: ErrUnsupportedChecksumTypeID},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			provider := &byteProvider{items: map[string][]byte{"key": tt.stored}}
+			cache := NewCache(
+				provider,
+				NewChecksumCodec(JSONByteStringCodec[int]{}),
+				WithDeleteOnChecksumMismatch[int, []byte](),
+			)
+
+			_, ok, err := cache.Get(context.Background(), "key")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("expected %v, got %v", tt.wantErr, err)
+			}
+			if ok {
+				t.Fatalf("expected ok=false on failed verification")
+			}
+			if _, exists := provider.items["key"]; exists {
+				t.Fatalf("expected unverifiable entry to be deleted")
+			}
+		})
+	}
+}
+
+func TestCache_GetKeepsEntryOnChecksumMismatchByDefault(t *testing.T) {
+	t.Parallel()
+
+	provider := &byteProvider{items: make(map[string][]byte)}
+	provider.items["key"] = []byte{ChecksumTypeIDCRC32C, 0x00, 0x00, 0x00, 0x00, '{'}
+	cache := NewCache(provider, NewChecksumCodec(JSONByteStringCodec[int]{}))
+
+	if _, _, err := cache.Get(context.Background(), "key"); !errors.Is(err, ErrChecksumMismatch) {
+		t.Fatalf("expected checksum mismatch, got %v", err)
+	}
+	if _, exists := provider.items["key"]; !exists {
+		t.Fatalf("expected entry to be kept without WithDeleteOnChecksumMismatch")
+	}
 }
 
 func TestCache_SetEncodeError(t *testing.T) {
@@ -272,30 +406,139 @@ func TestCache_SetEncodeError(t *testing.T) {
 	}
 }
 
+func TestCache_ProviderTimeouts(t *testing.T) {
+	t.Parallel()
+
+	provider := newContextRecordingProvider[CacheObject[int]]()
+	cache := NewCache(
+		provider,
+		NoopCacheStorageCodec[int]{},
+		WithProviderGetTimeout[int, CacheObject[int]](time.Minute),
+		WithProviderSetTimeout[int, CacheObject[int]](time.Hour),
+	)
+	start := time.Now()
+
+	if _, err := cache.GetOrLoad(context.Background(), "key", time.Minute, func(context.Context) (int, error) {
+		return 1, nil
+	}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	provider.mu.Lock()
+	defer provider.mu.Unlock()
+	getDeadline, ok := provider.getCtx.Deadline()
+	if !ok || getDeadline.Sub(start) > time.Minute+time.Second {
+		t.Fatalf("expected get deadline within a minute, got %v (ok=%v)", getDeadline.Sub(start), ok)
+	}
+	setDeadline, ok := provider.setCtx.Deadline()
+	if !ok || setDeadline.Sub(start) < 59*time.Minute {
+		t.Fatalf("expected set deadline around an hour, got %v (ok=%v)", setDeadline.Sub(start), ok)
+	}
+}
+
+func TestCache_GetDecodeErrorDeleteUsesProviderGetTimeout(t *testing.T) {
+	t.Parallel()
+
+	provider := &hangingDeleteProvider{byteProvider: byteProvider{items: map[string][]byte{"key": []byte("{")}}}
+	cache := NewCache(provider, JSONByteStringCodec[int]{},
+		WithDecodeErrorPolicy[int, []byte](DecodeErrorPolicyDeleteAsMiss),
+		WithProviderGetTimeout[int, []byte](10*time.Millisecond),
+	)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		if _, ok, err := cache.Get(context.Background(), "key"); ok || err != nil {
+			t.Errorf("expected miss without error, got ok=%v err=%v", ok, err)
+		}
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("expected Get to return once the delete timed out")
+	}
+}
+
+func TestCache_ProviderTimeoutsDisabledByDefault(t *testing.T) {
+	t.Parallel()
+
+	provider := newContextRecordingProvider[CacheObject[int]]()
+	cache := NewCache(provider, NoopCacheStorageCodec[int]{})
+
+	if _, err := cache.GetOrLoad(context.Background(), "key", time.Minute, func(context.Context) (int, error) {
+		return 1, nil
+	}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	provider.mu.Lock()
+	defer provider.mu.Unlock()
+	if _, ok := provider.getCtx.Deadline(); ok {
+		t.Fatal("expected no get deadline")
+	}
+	if _, ok := provider.setCtx.Deadline(); ok {
+		t.Fatal("expected no set deadline")
+	}
+}
+
+func TestCache_GetOrLoadAsyncSetSurvivesCancellation(t *testing.T) {
+	t.Parallel()
+
+	provider := newContextRecordingProvider[CacheObject[int]]()
+	cache := NewCache(
+		provider,
+		NoopCacheStorageCodec[int]{},
+		WithDirectLoader[int, CacheObject[int]](),
+		WithAsyncSet[int, CacheObject[int]](),
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	value, err := cache.GetOrLoad(ctx, "key", time.Minute, func(context.Context) (int, error) {
+		cancel()
+
+		return 3, nil
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if value != 3 {
+		t.Fatalf("expected value 3, got %d", value)
+	}
+
+	select {
+	case <-provider.setCh:
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for async set")
+	}
+	provider.mu.Lock()
+	defer provider.mu.Unlock()
+	if err := provider.setCtx.Err(); err != nil {
+		t.Fatalf("expected set context to be detached from cancellation, got %v", err)
+	}
+}
+
 func TestCache_ShouldRevalidateProbability(t *testing.T) {
 	t.Parallel()
 
-	steepness, window := calculateSteepnessAndRevalidationWindow(1000)
 	cache := &cacheImpl[int, CacheObject[int]]{
-		steepness:                      steepness,
-		revalidationWindowMilliseconds: window,
+		revalidation: NewExponentialRevalidation(time.Second),
 	}
 
 	cache.random = fakeRandom(0)
-	if !cache.shouldRevalidate(0, 500) {
+	if !cache.shouldRevalidate("key", 0, CacheObject[int]{ExpireAtMillis: 500}, 0) {
 		t.Fatalf("expected revalidation when random draw is below probability")
 	}
 
 	cache.random = fakeRandom(1)
-	if cache.shouldRevalidate(0, 500) {
+	if cache.shouldRevalidate("key", 0, CacheObject[int]{ExpireAtMillis: 500}, 0) {
 		t.Fatalf("expected no revalidation when random draw is above probability")
 	}
 
-	if cache.shouldRevalidate(0, 5000) {
+	if cache.shouldRevalidate("key", 0, CacheObject[int]{ExpireAtMillis: 5000}, 0) {
 		t.Fatalf("expected no revalidation outside the window")
 	}
 
-	if !cache.shouldRevalidate(0, -1) {
+	if !cache.shouldRevalidate("key", 0, CacheObject[int]{ExpireAtMillis: -1}, 0) {
 		t.Fatalf("expected revalidation for expired entry")
 	}
 }
@@ -433,11 +676,13 @@ func TestWithRevalidationWindow_SetsValues(t *testing.T) {
 	cache := NewCache(provider, NoopCacheStorageCodec[int]{}, WithRevalidationWindow[int, CacheObject[int]](target))
 	impl := cache.(*cacheImpl[int, CacheObject[int]])
 
-	if impl.steepness != expectedSteepness {
-		t.Fatalf("expected steepness %f, got %f", expectedSteepness, impl.steepness)
+	strategy := impl.revalidation.(*exponentialRevalidation)
+
+	if strategy.steepness != expectedSteepness {
+		t.Fatalf("expected steepness %f, got %f", expectedSteepness, strategy.steepness)
 	}
-	if impl.revalidationWindowMilliseconds != expectedWindow {
-		t.Fatalf("expected revalidation window %d, got %d", expectedWindow, impl.revalidationWindowMilliseconds)
+	if strategy.windowMillis != expectedWindow {
+		t.Fatalf("expected revalidation window %d, got %d", expectedWindow, strategy.windowMillis)
 	}
 }
 
@@ -448,11 +693,13 @@ func TestWithRevalidationWindow_DefaultsOnZero(t *testing.T) {
 	cache := NewCache(provider, NoopCacheStorageCodec[int]{}, WithRevalidationWindow[int, CacheObject[int]](0))
 	impl := cache.(*cacheImpl[int, CacheObject[int]])
 
-	if impl.steepness != 0 {
-		t.Fatalf("expected steepness 0, got %f", impl.steepness)
+	strategy := impl.revalidation.(*exponentialRevalidation)
+
+	if strategy.steepness != 0 {
+		t.Fatalf("expected steepness 0, got %f", strategy.steepness)
 	}
-	if impl.revalidationWindowMilliseconds != 0 {
-		t.Fatalf("expected revalidation window 0, got %d", impl.revalidationWindowMilliseconds)
+	if strategy.windowMillis != 0 {
+		t.Fatalf("expected revalidation window 0, got %d", strategy.windowMillis)
 	}
 }
 
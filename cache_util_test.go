@@ -2,6 +2,7 @@ package crema
 
 import (
 	"context"
+	"log/slog"
 	"sync"
 	"time"
 )
@@ -64,6 +65,17 @@ func (b *byteProvider) Delete(_ context.Context, key string) error {
 	return nil
 }
 
+// hangingDeleteProvider blocks Delete until its context is done.
+type hangingDeleteProvider struct {
+	byteProvider
+}
+
+func (p *hangingDeleteProvider) Delete(ctx context.Context, _ string) error {
+	<-ctx.Done()
+
+	return ctx.Err()
+}
+
 type errorProvider[S any] struct {
 	getErr    error
 	setErr    error
@@ -100,3 +112,45 @@ func fakeRandom(value float64) func() float64 {
 		return value
 	}
 }
+
+type contextRecordingProvider[S any] struct {
+	mu      sync.Mutex
+	getCtx  context.Context
+	setCtx  context.Context
+	setCh   chan struct{}
+	setKeys []string
+}
+
+func newContextRecordingProvider[S any]() *contextRecordingProvider[S] {
+	return &contextRecordingProvider[S]{setCh: make(chan struct{}, 1)}
+}
+
+func (p *contextRecordingProvider[S]) Get(ctx context.Context, _ string) (S, bool, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.getCtx = ctx
+	var zero S
+
+	return zero, false, nil
+}
+
+func (p *contextRecordingProvider[S]) Set(ctx context.Context, key string, _ S, _ time.Duration) error {
+	p.mu.Lock()
+	p.setCtx = ctx
+	p.setKeys = append(p.setKeys, key)
+	p.mu.Unlock()
+	select {
+	case p.setCh <- struct{}{}:
+	default:
+	}
+
+	return nil
+}
+
+func (p *contextRecordingProvider[S]) Delete(_ context.Context, _ string) error {
+	return nil
+}
+
+func testLogger() *slog.Logger {
+	return slog.New(noopLogHandler{})
+}
@@ -0,0 +1,332 @@
+package crema
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// CircuitBreakerState is the state of a CircuitBreakerProvider.
+type CircuitBreakerState int
+
+const (
+	// CircuitBreakerClosed passes every call through to the wrapped provider.
+	CircuitBreakerClosed CircuitBreakerState = iota
+	// CircuitBreakerOpen short-circuits calls without touching the wrapped provider.
+	CircuitBreakerOpen
+	// CircuitBreakerHalfOpen lets a limited number of probe calls through.
+	CircuitBreakerHalfOpen
+)
+
+// String returns the lower-case name of the state.
+func (s CircuitBreakerState) String() string {
+	switch s {
+	case CircuitBreakerClosed:
+		return "closed"
+	case CircuitBreakerOpen:
+		return "open"
+	case CircuitBreakerHalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+// ErrCircuitOpen is returned by CircuitBreakerProvider.Delete while the circuit is open.
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+const (
+	defaultCircuitBreakerConsecutiveFailures = 5
+	defaultCircuitBreakerOpenTimeout         = 10 * time.Second
+	defaultCircuitBreakerHalfOpenProbes      = 1
+)
+
+type circuitBreakerConfig struct {
+	consecutiveFailures int
+	failureRate         float64
+	minRequests         int
+	rateWindow          time.Duration
+	openTimeout         time.Duration
+	halfOpenProbes      int
+	logger              *slog.Logger
+	metrics             MetricsProvider
+}
+
+// CircuitBreakerOption configures a CircuitBreakerProvider.
+type CircuitBreakerOption func(*circuitBreakerConfig)
+
+// WithCircuitBreakerConsecutiveFailures trips the circuit after n consecutive failures.
+// A non-positive n disables the consecutive failure trigger.
+func WithCircuitBreakerConsecutiveFailures(n int) CircuitBreakerOption {
+	return func(c *circuitBreakerConfig) {
+		c.consecutiveFailures = n
+	}
+}
+
+// WithCircuitBreakerFailureRate trips the circuit when the failure ratio within
+// a window reaches rate, once at least minRequests calls were observed in it.
+// A non-positive rate or window disables the failure rate trigger.
+func WithCircuitBreakerFailureRate(rate float64, minRequests int, window time.Duration) CircuitBreakerOption {
+	return func(c *circuitBreakerConfig) {
+		c.failureRate = rate
+		c.minRequests = minRequests
+		c.rateWindow = window
+	}
+}
+
+// WithCircuitBreakerOpenTimeout sets how long the circuit stays open before probing.
+func WithCircuitBreakerOpenTimeout(duration time.Duration) CircuitBreakerOption {
+	return func(c *circuitBreakerConfig) {
+		if duration > 0 {
+			c.openTimeout = duration
+		}
+	}
+}
+
+// WithCircuitBreakerHalfOpenProbes sets how many concurrent probes are allowed
+// while half-open, and how many must succeed to close the circuit again.
+func WithCircuitBreakerHalfOpenProbes(n int) CircuitBreakerOption {
+	return func(c *circuitBreakerConfig) {
+		if n > 0 {
+			c.halfOpenProbes = n
+		}
+	}
+}
+
+// WithCircuitBreakerLogger sets the logger used to report state transitions.
+func WithCircuitBreakerLogger(logger *slog.Logger) CircuitBreakerOption {
+	return func(c *circuitBreakerConfig) {
+		if logger != nil {
+			c.logger = logger
+		}
+	}
+}
+
+// WithCircuitBreakerMetricsProvider sets the metrics provider notified of state
+// transitions when it implements CircuitBreakerMetricsProvider.
+func WithCircuitBreakerMetricsProvider(metrics MetricsProvider) CircuitBreakerOption {
+	return func(c *circuitBreakerConfig) {
+		if metrics == nil {
+			metrics = NoopMetricsProvider{}
+		}
+		c.metrics = metrics
+	}
+}
+
+// CircuitBreakerProvider wraps a CacheProvider and stops calling it after
+// repeated failures. While open, Get reports a miss, Set is skipped and Delete
+// returns ErrCircuitOpen, so GetOrLoad falls back to the loader immediately
+// instead of waiting for provider timeouts.
+// Errors caused by context cancellation are not counted as failures.
+type CircuitBreakerProvider[S any] struct {
+	_      noCopy
+	inner  CacheProvider[S]
+	config circuitBreakerConfig
+	now    func() time.Time
+
+	mu                  sync.Mutex
+	state               CircuitBreakerState
+	openedAt            time.Time
+	consecutiveFailures int
+	windowStart         time.Time
+	windowRequests      int
+	windowFailures      int
+	probesInFlight      int
+	probeSuccesses      int
+}
+
+var _ CacheProvider[any] = (*CircuitBreakerProvider[any])(nil)
+
+// NewCircuitBreakerProvider wraps inner with a circuit breaker.
+// By default the circuit opens after 5 consecutive failures, stays open for
+// 10 seconds and closes again after a single successful probe.
+func NewCircuitBreakerProvider[S any](inner CacheProvider[S], opts ...CircuitBreakerOption) *CircuitBreakerProvider[S] {
+	config := circuitBreakerConfig{
+		consecutiveFailures: defaultCircuitBreakerConsecutiveFailures,
+		openTimeout:         defaultCircuitBreakerOpenTimeout,
+		halfOpenProbes:      defaultCircuitBreakerHalfOpenProbes,
+		logger:              slog.New(noopLogHandler{}),
+		metrics:             NoopMetricsProvider{},
+	}
+	for _, opt := range opts {
+		if opt == nil {
+			continue
+		}
+		opt(&config)
+	}
+
+	return &CircuitBreakerProvider[S]{
+		inner:  inner,
+		config: config,
+		now:    time.Now,
+	}
+}
+
+// State returns the current circuit state.
+func (p *CircuitBreakerProvider[S]) State() CircuitBreakerState {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.state
+}
+
+// Get retrieves a value from the wrapped provider, or reports a miss while open.
+func (p *CircuitBreakerProvider[S]) Get(ctx context.Context, key string) (S, bool, error) {
+	probe, ok := p.allow(ctx)
+	if !ok {
+		var zero S
+
+		return zero, false, nil
+	}
+	value, found, err := p.inner.Get(ctx, key)
+	p.record(ctx, probe, err)
+
+	return value, found, err
+}
+
+// Set stores a value through the wrapped provider, or skips the write while open.
+func (p *CircuitBreakerProvider[S]) Set(ctx context.Context, key string, value S, ttl time.Duration) error {
+	probe, ok := p.allow(ctx)
+	if !ok {
+		return nil
+	}
+	err := p.inner.Set(ctx, key, value, ttl)
+	p.record(ctx, probe, err)
+
+	return err
+}
+
+// Delete removes a value through the wrapped provider, or returns ErrCircuitOpen while open.
+func (p *CircuitBreakerProvider[S]) Delete(ctx context.Context, key string) error {
+	probe, ok := p.allow(ctx)
+	if !ok {
+		return ErrCircuitOpen
+	}
+	err := p.inner.Delete(ctx, key)
+	p.record(ctx, probe, err)
+
+	return err
+}
+
+// allow reports whether a call may proceed and whether it is a half-open probe.
+func (p *CircuitBreakerProvider[S]) allow(ctx context.Context) (bool, bool) {
+	p.mu.Lock()
+	from := p.state
+	if p.state == CircuitBreakerOpen && p.now().Sub(p.openedAt) >= p.config.openTimeout {
+		p.state = CircuitBreakerHalfOpen
+		p.probesInFlight = 0
+		p.probeSuccesses = 0
+	}
+	to := p.state
+
+	probe, ok := false, true
+	switch p.state {
+	case CircuitBreakerOpen:
+		ok = false
+	case CircuitBreakerHalfOpen:
+		probe = true
+		ok = p.probesInFlight < p.config.halfOpenProbes
+		if ok {
+			p.probesInFlight++
+		}
+	case CircuitBreakerClosed:
+	}
+	p.mu.Unlock()
+
+	p.reportTransition(ctx, from, to)
+
+	return probe, ok
+}
+
+// record updates the breaker with the outcome of a call that was allowed through.
+func (p *CircuitBreakerProvider[S]) record(ctx context.Context, probe bool, err error) {
+	ignored := err != nil && errors.Is(err, context.Canceled)
+
+	p.mu.Lock()
+	from := p.state
+	switch {
+	case probe && p.state == CircuitBreakerHalfOpen:
+		p.recordProbe(err, ignored)
+	case !probe && p.state == CircuitBreakerClosed && !ignored:
+		p.recordClosed(err)
+	}
+	to := p.state
+	p.mu.Unlock()
+
+	p.reportTransition(ctx, from, to)
+}
+
+// recordProbe must be called with p.mu held.
+func (p *CircuitBreakerProvider[S]) recordProbe(err error, ignored bool) {
+	p.probesInFlight--
+	switch {
+	case ignored:
+	case err != nil:
+		p.trip()
+	default:
+		p.probeSuccesses++
+		if p.probeSuccesses >= p.config.halfOpenProbes {
+			p.reset()
+		}
+	}
+}
+
+// recordClosed must be called with p.mu held.
+func (p *CircuitBreakerProvider[S]) recordClosed(err error) {
+	now := p.now()
+	if p.config.rateWindow > 0 && now.Sub(p.windowStart) >= p.config.rateWindow {
+		p.windowStart = now
+		p.windowRequests = 0
+		p.windowFailures = 0
+	}
+	p.windowRequests++
+	if err == nil {
+		p.consecutiveFailures = 0
+
+		return
+	}
+	p.consecutiveFailures++
+	p.windowFailures++
+
+	if p.config.consecutiveFailures > 0 && p.consecutiveFailures >= p.config.consecutiveFailures {
+		p.trip()
+
+		return
+	}
+	if p.config.failureRate > 0 && p.config.rateWindow > 0 && p.windowRequests >= p.config.minRequests &&
+		float64(p.windowFailures)/float64(p.windowRequests) >= p.config.failureRate {
+		p.trip()
+	}
+}
+
+// trip must be called with p.mu held.
+func (p *CircuitBreakerProvider[S]) trip() {
+	p.state = CircuitBreakerOpen
+	p.openedAt = p.now()
+}
+
+// reset must be called with p.mu held.
+func (p *CircuitBreakerProvider[S]) reset() {
+	p.state = CircuitBreakerClosed
+	p.consecutiveFailures = 0
+	p.windowStart = p.now()
+	p.windowRequests = 0
+	p.windowFailures = 0
+}
+
+func (p *CircuitBreakerProvider[S]) reportTransition(ctx context.Context, from, to CircuitBreakerState) {
+	if from == to {
+		return
+	}
+	attrs := []any{slog.String("from", from.String()), slog.String("to", to.String())}
+	if to == CircuitBreakerOpen {
+		p.config.logger.Warn("cache provider circuit breaker opened", attrs...)
+	} else {
+		p.config.logger.Info("cache provider circuit breaker state changed", attrs...)
+	}
+	if metrics, ok := p.config.metrics.(CircuitBreakerMetricsProvider); ok {
+		metrics.RecordCircuitBreakerStateChange(ctx, from, to)
+	}
+}
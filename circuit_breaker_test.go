@@ -0,0 +1,291 @@
+package crema
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type flakyProvider struct {
+	mu    sync.Mutex
+	err   error
+	calls int32
+}
+
+func (p *flakyProvider) Get(_ context.Context, _ string) ([]byte, bool, error) {
+	if err := p.result(); err != nil {
+		return nil, false, err
+	}
+
+	return []byte("value"), true, nil
+}
+
+func (p *flakyProvider) Set(_ context.Context, _ string, _ []byte, _ time.Duration) error {
+	return p.result()
+}
+
+func (p *flakyProvider) Delete(_ context.Context, _ string) error {
+	return p.result()
+}
+
+func (p *flakyProvider) setErr(err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.err = err
+}
+
+func (p *flakyProvider) result() error {
+	atomic.AddInt32(&p.calls, 1)
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.err
+}
+
+type circuitBreakerTestMetrics struct {
+	BaseMetricsProvider
+
+	mu          sync.Mutex
+	transitions []CircuitBreakerState
+}
+
+func (m *circuitBreakerTestMetrics) RecordCircuitBreakerStateChange(_ context.Context, _, to CircuitBreakerState) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.transitions = append(m.transitions, to)
+}
+
+func newTestCircuitBreaker(inner CacheProvider[[]byte], opts ...CircuitBreakerOption) (*CircuitBreakerProvider[[]byte], *time.Time) {
+	breaker := NewCircuitBreakerProvider(inner, opts...)
+	now := time.UnixMilli(1000)
+	breaker.now = func() time.Time { return now }
+
+	return breaker, &now
+}
+
+func TestCircuitBreakerProvider_TripsAfterConsecutiveFailures(t *testing.T) {
+	t.Parallel()
+
+	inner := &flakyProvider{err: errors.New("down")}
+	breaker, _ := newTestCircuitBreaker(inner, WithCircuitBreakerConsecutiveFailures(3))
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		if _, _, err := breaker.Get(ctx, "key"); err == nil {
+			t.Fatalf("expected provider error on call %d", i)
+		}
+	}
+	if state := breaker.State(); state != CircuitBreakerOpen {
+		t.Fatalf("expected open state, got %v", state)
+	}
+
+	_, ok, err := breaker.Get(ctx, "key")
+	if err != nil || ok {
+		t.Fatalf("expected miss while open, got ok=%v err=%v", ok, err)
+	}
+	if err := breaker.Set(ctx, "key", []byte("v"), time.Second); err != nil {
+		t.Fatalf("expected set to be skipped while open, got %v", err)
+	}
+	if err := breaker.Delete(ctx, "key"); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected ErrCircuitOpen from delete, got %v", err)
+	}
+	if calls := atomic.LoadInt32(&inner.calls); calls != 3 {
+		t.Fatalf("expected inner provider to be skipped while open, got %d calls", calls)
+	}
+}
+
+func TestCircuitBreakerProvider_SuccessResetsConsecutiveFailures(t *testing.T) {
+	t.Parallel()
+
+	inner := &flakyProvider{}
+	breaker, _ := newTestCircuitBreaker(inner, WithCircuitBreakerConsecutiveFailures(2))
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		inner.setErr(errors.New("down"))
+		breaker.Get(ctx, "key")
+		inner.setErr(nil)
+		breaker.Get(ctx, "key")
+	}
+	if state := breaker.State(); state != CircuitBreakerClosed {
+		t.Fatalf("expected closed state, got %v", state)
+	}
+}
+
+func TestCircuitBreakerProvider_HalfOpenProbeCloses(t *testing.T) {
+	t.Parallel()
+
+	inner := &flakyProvider{err: errors.New("down")}
+	metrics := &circuitBreakerTestMetrics{}
+	breaker, now := newTestCircuitBreaker(
+		inner,
+		WithCircuitBreakerConsecutiveFailures(1),
+		WithCircuitBreakerOpenTimeout(time.Second),
+		WithCircuitBreakerMetricsProvider(metrics),
+	)
+	ctx := context.Background()
+
+	if _, _, err := breaker.Get(ctx, "key"); err == nil {
+		t.Fatal("expected provider error")
+	}
+	if state := breaker.State(); state != CircuitBreakerOpen {
+		t.Fatalf("expected open state, got %v", state)
+	}
+
+	*now = now.Add(time.Second)
+	inner.setErr(nil)
+	value, ok, err := breaker.Get(ctx, "key")
+	if err != nil || !ok || string(value) != "value" {
+		t.Fatalf("expected probe to reach provider, got value=%q ok=%v err=%v", value, ok, err)
+	}
+	if state := breaker.State(); state != CircuitBreakerClosed {
+		t.Fatalf("expected closed state after successful probe, got %v", state)
+	}
+
+	expected := []CircuitBreakerState{CircuitBreakerOpen, CircuitBreakerHalfOpen, CircuitBreakerClosed}
+	metrics.mu.Lock()
+	defer metrics.mu.Unlock()
+	if len(metrics.transitions) != len(expected) {
+		t.Fatalf("expected transitions %v, got %v", expected, metrics.transitions)
+	}
+	for i := range expected {
+		if metrics.transitions[i] != expected[i] {
+			t.Fatalf("expected transitions %v, got %v", expected, metrics.transitions)
+		}
+	}
+}
+
+func TestCircuitBreakerProvider_HalfOpenProbeFailureReopens(t *testing.T) {
+	t.Parallel()
+
+	inner := &flakyProvider{err: errors.New("down")}
+	breaker, now := newTestCircuitBreaker(
+		inner,
+		WithCircuitBreakerConsecutiveFailures(1),
+		WithCircuitBreakerOpenTimeout(time.Second),
+	)
+	ctx := context.Background()
+
+	if _, _, err := breaker.Get(ctx, "key"); err == nil {
+		t.Fatal("expected provider error")
+	}
+	*now = now.Add(time.Second)
+	if _, _, err := breaker.Get(ctx, "key"); err == nil {
+		t.Fatal("expected probe error")
+	}
+	if state := breaker.State(); state != CircuitBreakerOpen {
+		t.Fatalf("expected open state after failed probe, got %v", state)
+	}
+}
+
+func TestCircuitBreakerProvider_HalfOpenLimitsProbes(t *testing.T) {
+	t.Parallel()
+
+	inner := &flakyProvider{err: errors.New("down")}
+	breaker, now := newTestCircuitBreaker(
+		inner,
+		WithCircuitBreakerConsecutiveFailures(1),
+		WithCircuitBreakerOpenTimeout(time.Second),
+	)
+	ctx := context.Background()
+
+	if _, _, err := breaker.Get(ctx, "key"); err == nil {
+		t.Fatal("expected provider error")
+	}
+	*now = now.Add(time.Second)
+
+	probe, ok := breaker.allow(ctx)
+	if !ok || !probe {
+		t.Fatalf("expected first half-open call to be a probe, got probe=%v ok=%v", probe, ok)
+	}
+	if _, ok := breaker.allow(ctx); ok {
+		t.Fatal("expected second concurrent probe to be rejected")
+	}
+	breaker.record(ctx, probe, nil)
+	if state := breaker.State(); state != CircuitBreakerClosed {
+		t.Fatalf("expected closed state, got %v", state)
+	}
+}
+
+func TestCircuitBreakerProvider_TripsOnFailureRate(t *testing.T) {
+	t.Parallel()
+
+	inner := &flakyProvider{}
+	breaker, _ := newTestCircuitBreaker(
+		inner,
+		WithCircuitBreakerConsecutiveFailures(0),
+		WithCircuitBreakerFailureRate(0.5, 4, time.Minute),
+	)
+	ctx := context.Background()
+
+	for _, err := range []error{nil, errors.New("down"), nil} {
+		inner.setErr(err)
+		breaker.Get(ctx, "key")
+	}
+	if state := breaker.State(); state != CircuitBreakerClosed {
+		t.Fatalf("expected closed state below min requests, got %v", state)
+	}
+
+	inner.setErr(errors.New("down"))
+	breaker.Get(ctx, "key")
+	if state := breaker.State(); state != CircuitBreakerOpen {
+		t.Fatalf("expected open state at 50%% failure rate, got %v", state)
+	}
+}
+
+func TestCircuitBreakerProvider_IgnoresContextCanceled(t *testing.T) {
+	t.Parallel()
+
+	inner := &flakyProvider{err: context.Canceled}
+	breaker, _ := newTestCircuitBreaker(inner, WithCircuitBreakerConsecutiveFailures(1))
+
+	for i := 0; i < 3; i++ {
+		breaker.Get(context.Background(), "key")
+	}
+	if state := breaker.State(); state != CircuitBreakerClosed {
+		t.Fatalf("expected canceled calls not to trip the breaker, got %v", state)
+	}
+}
+
+func TestCircuitBreakerProvider_GetOrLoadFallsBackWhileOpen(t *testing.T) {
+	t.Parallel()
+
+	inner := &flakyProvider{err: errors.New("down")}
+	breaker, _ := newTestCircuitBreaker(inner, WithCircuitBreakerConsecutiveFailures(1))
+	cache := NewCache[string](breaker, JSONByteStringCodec[string]{})
+	loader := func(context.Context) (string, error) {
+		return "loaded", nil
+	}
+
+	for i := 0; i < 3; i++ {
+		value, err := cache.GetOrLoad(context.Background(), "key", time.Minute, loader)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if value != "loaded" {
+			t.Fatalf("expected loaded value, got %q", value)
+		}
+	}
+	if calls := atomic.LoadInt32(&inner.calls); calls != 1 {
+		t.Fatalf("expected only the tripping call to reach the provider, got %d", calls)
+	}
+}
+
+func TestCircuitBreakerState_String(t *testing.T) {
+	t.Parallel()
+
+	tests := map[CircuitBreakerState]string{
+		CircuitBreakerClosed:    "closed",
+		CircuitBreakerOpen:      "open",
+		CircuitBreakerHalfOpen:  "half-open",
+		CircuitBreakerState(99): "unknown",
+	}
+	for state, expected := range tests {
+		if got := state.String(); got != expected {
+			t.Fatalf("expected %q, got %q", expected, got)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+// Command crema-sim simulates revalidation behavior under synthetic traffic.
+//
+// It drives a real crema.Cache on a fake clock with a configurable request
+// stream and loader latency, once per revalidation strategy, and reports
+// loader calls, stampede peaks, hit ratio and staleness as a table, CSV or SVG.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/abema/crema/internal/strategyspec"
+)
+
+const (
+	defaultRate        = 100
+	defaultKeys        = 1000
+	defaultBurstFactor = 10
+	defaultTTL         = 5 * time.Minute
+	defaultDuration    = time.Hour
+	defaultLatency     = 200 * time.Millisecond
+	defaultJitter      = 0.5
+	defaultBurstEvery  = 10 * time.Minute
+	defaultBurstLength = 30 * time.Second
+	defaultStrategies  = "none,exponential:30s,exponential:1m,exponential:5m"
+	exitUsage          = 2
+)
+
+type options struct {
+	strategies string
+	format     string
+	output     string
+	config     simConfig
+}
+
+func main() {
+	opts, err := parseFlags(os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
+	if err != nil {
+		os.Exit(exitUsage)
+	}
+	if err := run(opts, os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		os.Exit(1)
+	}
+}
+
+func parseFlags(args []string) (options, error) {
+	var opts options
+	fs := flag.NewFlagSet("crema-sim", flag.ContinueOnError)
+	fs.StringVar(&opts.strategies, "strategies", defaultStrategies,
+		"comma-separated strategies: none, exponential:<window>, linear:<window>, adaptive:<window>, "+
+			"relative:<fraction>, fixed:<fraction>, xfetch:<beta>")
+	fs.StringVar(&opts.format, "format", formatTable, "output format: table, csv or svg")
+	fs.StringVar(&opts.output, "o", "", "output path (default stdout)")
+	fs.DurationVar(&opts.config.duration, "duration", defaultDuration, "simulated duration")
+	fs.DurationVar(&opts.config.ttl, "ttl", defaultTTL, "cache ttl")
+	fs.DurationVar(&opts.config.latency, "latency", defaultLatency, "mean loader latency")
+	fs.Float64Var(&opts.config.latencyJitter, "latency-jitter", defaultJitter, "loader latency jitter as a fraction of the mean")
+	fs.Uint64Var(&opts.config.seed, "seed", 1, "random seed")
+	fs.StringVar(&opts.config.traffic.pattern, "traffic", trafficPoisson, "traffic pattern: poisson or bursty")
+	fs.Float64Var(&opts.config.traffic.rate, "rate", defaultRate, "mean requests per second")
+	fs.Float64Var(&opts.config.traffic.burstFactor, "burst-factor", defaultBurstFactor, "rate multiplier during bursts")
+	fs.DurationVar(&opts.config.traffic.burstEvery, "burst-every", defaultBurstEvery, "time between burst starts")
+	fs.DurationVar(&opts.config.traffic.burstLength, "burst-length", defaultBurstLength, "burst length")
+	fs.IntVar(&opts.config.traffic.keys, "keys", defaultKeys, "number of distinct keys")
+	fs.Float64Var(&opts.config.traffic.zipfS, "zipf", 0, "zipf exponent of the key distribution, > 1 (default uniform)")
+	if err := fs.Parse(args); err != nil {
+		return options{}, err
+	}
+
+	return opts, nil
+}
+
+func run(opts options, stdout io.Writer) error {
+	var results []simResult
+	for _, spec := range strings.Split(opts.strategies, ",") {
+		spec = strings.TrimSpace(spec)
+		strategy, err := strategyspec.Parse(spec, opts.config.latency)
+		if err != nil {
+			return err
+		}
+		config := opts.config
+		config.label = spec
+		config.strategy = strategy
+		result, err := simulate(config)
+		if err != nil {
+			return fmt.Errorf("simulate %s: %w", spec, err)
+		}
+		results = append(results, result)
+	}
+
+	if opts.output == "" {
+		return writeReport(stdout, opts.format, results)
+	}
+	f, err := os.Create(opts.output)
+	if err != nil {
+		return err
+	}
+	if err := writeReport(f, opts.format, results); err != nil {
+		f.Close()
+
+		return err
+	}
+
+	return f.Close()
+}
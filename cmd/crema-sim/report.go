@@ -0,0 +1,149 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"html"
+	"io"
+	"strconv"
+	"text/tabwriter"
+	"time"
+)
+
+const (
+	formatTable = "table"
+	formatCSV   = "csv"
+	formatSVG   = "svg"
+
+	svgWidth       = 800
+	svgPanelHeight = 160
+	svgMargin      = 40
+	svgLabelWidth  = 180
+	svgBarGap      = 4
+	svgFontSize    = 12
+	percent        = 100
+	tablePadding   = 2
+)
+
+var reportHeader = []string{
+	"config", "requests", "hits", "misses", "revalidations", "loader_calls",
+	"hit_ratio", "peak_concurrent_loads", "mean_staleness", "p99_staleness",
+}
+
+func writeReport(w io.Writer, format string, results []simResult) error {
+	switch format {
+	case formatTable:
+		return writeTable(w, results)
+	case formatCSV:
+		return writeCSV(w, results)
+	case formatSVG:
+		return writeSVG(w, results)
+	default:
+		return fmt.Errorf("unknown format %q", format)
+	}
+}
+
+func reportRow(r simResult) []string {
+	return []string{
+		r.Label,
+		strconv.Itoa(r.Requests),
+		strconv.Itoa(r.Hits),
+		strconv.Itoa(r.Misses),
+		strconv.Itoa(r.Revalidations),
+		strconv.Itoa(r.LoaderCalls),
+		strconv.FormatFloat(r.HitRatio(), 'f', 4, 64),
+		strconv.Itoa(r.PeakConcurrentLoads),
+		r.MeanStaleness.Round(time.Millisecond).String(),
+		r.P99Staleness.Round(time.Millisecond).String(),
+	}
+}
+
+func writeTable(w io.Writer, results []simResult) error {
+	tw := tabwriter.NewWriter(w, 0, 0, tablePadding, ' ', tabwriter.AlignRight)
+	writeTabRow(tw, reportHeader)
+	for _, r := range results {
+		writeTabRow(tw, reportRow(r))
+	}
+
+	return tw.Flush()
+}
+
+func writeTabRow(w io.Writer, row []string) {
+	for _, cell := range row {
+		fmt.Fprint(w, cell, "\t")
+	}
+	fmt.Fprintln(w)
+}
+
+func writeCSV(w io.Writer, results []simResult) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(reportHeader); err != nil {
+		return err
+	}
+	for _, r := range results {
+		if err := cw.Write(reportRow(r)); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+
+	return cw.Error()
+}
+
+// svgPanel is a bar chart of one metric across configurations.
+type svgPanel struct {
+	title string
+	value func(simResult) float64
+	unit  string
+}
+
+var svgPanels = []svgPanel{
+	{title: "loader calls", value: func(r simResult) float64 { return float64(r.LoaderCalls) }},
+	{title: "peak concurrent loads per key", value: func(r simResult) float64 { return float64(r.PeakConcurrentLoads) }},
+	{title: "hit ratio", value: func(r simResult) float64 { return r.HitRatio() * percent }, unit: "%"},
+	{title: "mean staleness", value: func(r simResult) float64 { return r.MeanStaleness.Seconds() }, unit: "s"},
+}
+
+func writeSVG(w io.Writer, results []simResult) error {
+	height := svgMargin + len(svgPanels)*svgPanelHeight
+	fmt.Fprintf(w, `<?xml version="1.0" encoding="UTF-8"?>`+"\n")
+	fmt.Fprintf(w, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d">`+"\n",
+		svgWidth, height, svgWidth, height)
+	fmt.Fprintf(w, `<rect x="0" y="0" width="%d" height="%d" fill="#f7f4ef"/>`+"\n", svgWidth, height)
+	for i, panel := range svgPanels {
+		writeSVGPanel(w, panel, results, svgMargin/2+i*svgPanelHeight)
+	}
+	_, err := fmt.Fprintln(w, `</svg>`)
+
+	return err
+}
+
+func writeSVGPanel(w io.Writer, panel svgPanel, results []simResult, top int) {
+	fmt.Fprintf(w, `<text x="%d" y="%d" font-family="Verdana" font-size="14" fill="#222222">%s</text>`+"\n",
+		svgMargin, top+svgFontSize, panel.title)
+	if len(results) == 0 {
+		return
+	}
+	maxValue := 0.0
+	for _, r := range results {
+		maxValue = max(maxValue, panel.value(r))
+	}
+	barsTop := top + svgFontSize + svgBarGap + svgBarGap
+	barHeight := max((svgPanelHeight-svgMargin)/len(results)-svgBarGap, 1)
+	plotWidth := svgWidth - 2*svgMargin - svgLabelWidth
+	for i, r := range results {
+		value := panel.value(r)
+		width := 0.0
+		if maxValue > 0 {
+			width = float64(plotWidth) * value / maxValue
+		}
+		y := barsTop + i*(barHeight+svgBarGap)
+		fmt.Fprintf(w, `<text x="%d" y="%d" font-family="Verdana" font-size="%d" fill="#222222" `+
+			`text-anchor="end" dominant-baseline="middle">%s</text>`+"\n",
+			svgMargin+svgLabelWidth-svgBarGap, y+barHeight/2, svgFontSize, html.EscapeString(r.Label))
+		fmt.Fprintf(w, `<rect x="%d" y="%d" width="%.2f" height="%d" fill="#1e5aa8"/>`+"\n",
+			svgMargin+svgLabelWidth, y, width, barHeight)
+		fmt.Fprintf(w, `<text x="%.2f" y="%d" font-family="Verdana" font-size="%d" fill="#222222" dominant-baseline="middle">%s%s</text>`+"\n",
+			float64(svgMargin+svgLabelWidth+svgBarGap)+width, y+barHeight/2, svgFontSize, strconv.FormatFloat(value, 'g', 4, 64), panel.unit)
+	}
+}
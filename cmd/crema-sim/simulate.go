@@ -0,0 +1,223 @@
+package main
+
+import (
+	"container/heap"
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"slices"
+	"time"
+
+	"github.com/abema/crema"
+	"github.com/abema/crema/crematest"
+)
+
+const (
+	// stalenessPercentile is the percentile of served value ages reported.
+	stalenessPercentile = 0.99
+	simSeedStream       = 0x63726d61
+)
+
+// simConfig is one simulated configuration.
+type simConfig struct {
+	label         string
+	strategy      crema.RevalidationStrategy
+	traffic       trafficConfig
+	duration      time.Duration
+	ttl           time.Duration
+	latency       time.Duration
+	latencyJitter float64
+	seed          uint64
+}
+
+// simResult summarizes a simulation run.
+type simResult struct {
+	Label               string
+	Requests            int
+	Hits                int
+	Misses              int
+	Revalidations       int
+	LoaderCalls         int
+	PeakConcurrentLoads int
+	MeanStaleness       time.Duration
+	P99Staleness        time.Duration
+}
+
+// HitRatio returns the fraction of requests served from the cache.
+func (r simResult) HitRatio() float64 {
+	if r.Requests == 0 {
+		return 0
+	}
+
+	return float64(r.Hits) / float64(r.Requests)
+}
+
+// pendingWrite is a loaded value becoming visible once its load completes.
+type pendingWrite struct {
+	at    time.Time
+	key   string
+	value crema.CacheObject[int64]
+}
+
+type pendingWrites []pendingWrite
+
+func (p pendingWrites) Len() int           { return len(p) }
+func (p pendingWrites) Less(i, j int) bool { return p[i].at.Before(p[j].at) }
+func (p pendingWrites) Swap(i, j int)      { p[i], p[j] = p[j], p[i] }
+func (p *pendingWrites) Push(x any)        { *p = append(*p, x.(pendingWrite)) }
+
+func (p *pendingWrites) Pop() any {
+	old := *p
+	last := old[len(old)-1]
+	*p = old[:len(old)-1]
+
+	return last
+}
+
+// simProvider is a shared cache whose writes only become visible once the
+// load producing them completes, so requests arriving during a load of an
+// expired key load it again, as separate instances sharing a cache would.
+type simProvider struct {
+	clock    *crematest.FakeClock
+	items    map[string]crema.CacheObject[int64]
+	pending  pendingWrites
+	inflight map[string][]time.Time
+	// loadLatency is the latency of the load whose result is stored next.
+	loadLatency time.Duration
+	peak        int
+}
+
+func newSimProvider(clock *crematest.FakeClock) *simProvider {
+	return &simProvider{
+		clock:    clock,
+		items:    make(map[string]crema.CacheObject[int64]),
+		inflight: make(map[string][]time.Time),
+	}
+}
+
+func (p *simProvider) Get(_ context.Context, key string) (crema.CacheObject[int64], bool, error) {
+	value, ok := p.items[key]
+	if !ok || value.ExpireAtMillis <= p.clock.Now().UnixMilli() {
+		return crema.CacheObject[int64]{}, false, nil
+	}
+
+	return value, true, nil
+}
+
+func (p *simProvider) Set(_ context.Context, key string, value crema.CacheObject[int64], _ time.Duration) error {
+	// The entry expires ttl after the load completes, not after it started.
+	value.ExpireAtMillis += p.loadLatency.Milliseconds()
+	heap.Push(&p.pending, pendingWrite{at: p.clock.Now().Add(p.loadLatency), key: key, value: value})
+
+	return nil
+}
+
+func (p *simProvider) Delete(_ context.Context, key string) error {
+	delete(p.items, key)
+
+	return nil
+}
+
+// startLoad records a load of key taking latency and tracks the peak number
+// of concurrent loads of a single key.
+func (p *simProvider) startLoad(key string, latency time.Duration) {
+	now := p.clock.Now()
+	running := slices.DeleteFunc(p.inflight[key], func(done time.Time) bool { return !done.After(now) })
+	running = append(running, now.Add(latency))
+	p.inflight[key] = running
+	p.peak = max(p.peak, len(running))
+	p.loadLatency = latency
+}
+
+// applyWrites stores the values of loads completed by now.
+func (p *simProvider) applyWrites(now time.Time) {
+	for len(p.pending) > 0 && !p.pending[0].at.After(now) {
+		write := heap.Pop(&p.pending).(pendingWrite)
+		if current, ok := p.items[write.key]; !ok || current.ExpireAtMillis <= write.value.ExpireAtMillis {
+			p.items[write.key] = write.value
+		}
+	}
+}
+
+// simulate drives a crema.Cache with config's traffic on a fake clock.
+func simulate(config simConfig) (simResult, error) {
+	random := rand.New(rand.NewPCG(config.seed, simSeedStream))
+	arrivals, err := newArrivals(config.traffic, random)
+	if err != nil {
+		return simResult{}, err
+	}
+	keys, err := newKeyPicker(config.traffic, random)
+	if err != nil {
+		return simResult{}, err
+	}
+
+	start := time.UnixMilli(0)
+	clock := crematest.NewFakeClock(start)
+	provider := newSimProvider(clock)
+	cache := crema.NewCache(
+		provider,
+		crema.NoopCacheStorageCodec[int64]{},
+		crema.WithClock[int64, crema.CacheObject[int64]](clock.Now),
+		crema.WithRandomSource[int64, crema.CacheObject[int64]](random.Float64),
+		crema.WithRevalidationStrategy[int64, crema.CacheObject[int64]](config.strategy),
+		crema.WithDirectLoader[int64, crema.CacheObject[int64]](),
+	).(crema.CacheResultLoader[int64])
+
+	result := simResult{Label: config.label}
+	var staleness []time.Duration
+	for at := arrivals.next(); at < config.duration; at = arrivals.next() {
+		now := start.Add(at)
+		clock.Set(now)
+		provider.applyWrites(now)
+
+		key := keys.next()
+		loaded, err := cache.GetOrLoadResult(context.Background(), key, config.ttl, func(context.Context) (int64, error) {
+			result.LoaderCalls++
+			provider.startLoad(key, config.loadLatency(random))
+
+			return now.UnixMilli(), nil
+		})
+		if err != nil {
+			return simResult{}, fmt.Errorf("load %s: %w", key, err)
+		}
+		result.record(loaded, now, &staleness)
+	}
+	result.PeakConcurrentLoads = provider.peak
+	result.MeanStaleness, result.P99Staleness = summarizeStaleness(staleness)
+
+	return result, nil
+}
+
+func (c simConfig) loadLatency(random *rand.Rand) time.Duration {
+	jitter := c.latencyJitter * (2*random.Float64() - 1)
+
+	return max(time.Duration(float64(c.latency)*(1+jitter)), 0)
+}
+
+// record counts a request and, for hits, the age of the served value.
+func (r *simResult) record(loaded crema.LoadResult[int64], now time.Time, staleness *[]time.Duration) {
+	r.Requests++
+	switch loaded.Source {
+	case crema.LoadSourceHit, crema.LoadSourceStale:
+		r.Hits++
+		*staleness = append(*staleness, now.Sub(time.UnixMilli(loaded.Value)))
+	case crema.LoadSourceRevalidate:
+		r.Revalidations++
+	case crema.LoadSourceMiss, crema.LoadSourceShared:
+		r.Misses++
+	}
+}
+
+func summarizeStaleness(staleness []time.Duration) (time.Duration, time.Duration) {
+	if len(staleness) == 0 {
+		return 0, 0
+	}
+	slices.Sort(staleness)
+	var total time.Duration
+	for _, age := range staleness {
+		total += age
+	}
+	p99 := staleness[min(int(float64(len(staleness))*stalenessPercentile), len(staleness)-1)]
+
+	return total / time.Duration(len(staleness)), p99
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/abema/crema/internal/strategyspec"
+)
+
+func testSimConfig(t *testing.T, spec string) simConfig {
+	t.Helper()
+
+	strategy, err := strategyspec.Parse(spec, 100*time.Millisecond)
+	if err != nil {
+		t.Fatalf("Parse(%q) error = %v", spec, err)
+	}
+
+	return simConfig{
+		label:    spec,
+		strategy: strategy,
+		traffic:  trafficConfig{pattern: trafficPoisson, rate: 50, keys: 10},
+		duration: 10 * time.Minute,
+		ttl:      time.Minute,
+		latency:  100 * time.Millisecond,
+		seed:     1,
+	}
+}
+
+func TestSimulate_RevalidationReducesMisses(t *testing.T) {
+	t.Parallel()
+
+	none, err := simulate(testSimConfig(t, "none"))
+	if err != nil {
+		t.Fatalf("simulate() error = %v", err)
+	}
+	early, err := simulate(testSimConfig(t, "exponential:10s"))
+	if err != nil {
+		t.Fatalf("simulate() error = %v", err)
+	}
+
+	if none.Revalidations != 0 {
+		t.Fatalf("expected no early revalidations without a window, got %d", none.Revalidations)
+	}
+	if early.Revalidations == 0 || early.Misses >= none.Misses {
+		t.Fatalf("expected early revalidation to replace misses, got none=%+v early=%+v", none, early)
+	}
+	if got := none.Hits + none.Misses; got != none.Requests || none.LoaderCalls != none.Misses {
+		t.Fatalf("expected every request to hit or load, got %+v", none)
+	}
+}
+
+func TestSimulate_IsDeterministic(t *testing.T) {
+	t.Parallel()
+
+	for _, spec := range []string{"exponential:10s", "adaptive:10s"} {
+		first, err := simulate(testSimConfig(t, spec))
+		if err != nil {
+			t.Fatalf("simulate() error = %v", err)
+		}
+		second, err := simulate(testSimConfig(t, spec))
+		if err != nil {
+			t.Fatalf("simulate() error = %v", err)
+		}
+		if first != second {
+			t.Fatalf("expected identical %s runs with the same seed, got %+v and %+v", spec, first, second)
+		}
+	}
+}
+
+func TestSimulate_CountsStampedes(t *testing.T) {
+	t.Parallel()
+
+	config := testSimConfig(t, "none")
+	config.traffic = trafficConfig{pattern: trafficPoisson, rate: 100, keys: 1}
+	config.latency = time.Second
+	result, err := simulate(config)
+	if err != nil {
+		t.Fatalf("simulate() error = %v", err)
+	}
+	if result.PeakConcurrentLoads < 10 {
+		t.Fatalf("expected requests during slow loads of an expired key to stampede, got peak %d", result.PeakConcurrentLoads)
+	}
+}
+
+func TestWriteReport_Formats(t *testing.T) {
+	t.Parallel()
+
+	results := []simResult{{Label: "exponential:1m", Requests: 10, Hits: 8, Misses: 2, LoaderCalls: 2}}
+	for format, want := range map[string]string{
+		formatTable: "exponential:1m",
+		formatCSV:   "exponential:1m,10,8,2,0,2,0.8000",
+		formatSVG:   "</svg>",
+	} {
+		var buf bytes.Buffer
+		if err := writeReport(&buf, format, results); err != nil {
+			t.Fatalf("writeReport(%s) error = %v", format, err)
+		}
+		if !strings.Contains(buf.String(), want) {
+			t.Fatalf("writeReport(%s) = %q, want it to contain %q", format, buf.String(), want)
+		}
+	}
+	if err := writeReport(&bytes.Buffer{}, "png", results); err == nil {
+		t.Fatal("expected an unknown format to be rejected")
+	}
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"strconv"
+	"time"
+)
+
+const (
+	trafficPoisson = "poisson"
+	trafficBursty  = "bursty"
+)
+
+// trafficConfig describes the synthetic request stream.
+type trafficConfig struct {
+	pattern     string
+	rate        float64
+	burstFactor float64
+	burstEvery  time.Duration
+	burstLength time.Duration
+	keys        int
+	zipfS       float64
+}
+
+// arrivals generates request times as a Poisson process, optionally with a
+// rate multiplied by burstFactor during the first burstLength of every burstEvery.
+type arrivals struct {
+	config trafficConfig
+	random *rand.Rand
+	now    time.Duration
+}
+
+func newArrivals(config trafficConfig, random *rand.Rand) (*arrivals, error) {
+	if config.rate <= 0 {
+		return nil, fmt.Errorf("rate must be positive, got %v", config.rate)
+	}
+	switch config.pattern {
+	case trafficPoisson:
+	case trafficBursty:
+		if config.burstEvery <= 0 || config.burstLength <= 0 || config.burstFactor <= 0 {
+			return nil, fmt.Errorf("bursty traffic needs a positive burst factor, period and length")
+		}
+	default:
+		return nil, fmt.Errorf("unknown traffic pattern %q", config.pattern)
+	}
+
+	return &arrivals{config: config, random: random}, nil
+}
+
+// next returns the time of the next request since the start of the simulation.
+func (a *arrivals) next() time.Duration {
+	gap := a.random.ExpFloat64() / a.rateAt(a.now)
+	a.now += time.Duration(gap * float64(time.Second))
+
+	return a.now
+}
+
+func (a *arrivals) rateAt(at time.Duration) float64 {
+	if a.config.pattern == trafficBursty && at%a.config.burstEvery < a.config.burstLength {
+		return a.config.rate * a.config.burstFactor
+	}
+
+	return a.config.rate
+}
+
+// keyPicker picks the key of each request, uniformly or Zipf distributed.
+type keyPicker struct {
+	keys   int
+	random *rand.Rand
+	zipf   *rand.Zipf
+}
+
+func newKeyPicker(config trafficConfig, random *rand.Rand) (*keyPicker, error) {
+	if config.keys <= 0 {
+		return nil, fmt.Errorf("keys must be positive, got %d", config.keys)
+	}
+	picker := &keyPicker{keys: config.keys, random: random}
+	if config.zipfS != 0 {
+		picker.zipf = rand.NewZipf(random, config.zipfS, 1, uint64(config.keys-1))
+		if picker.zipf == nil {
+			return nil, fmt.Errorf("zipf exponent must be greater than 1, got %v", config.zipfS)
+		}
+	}
+
+	return picker, nil
+}
+
+func (p *keyPicker) next() string {
+	if p.zipf != nil {
+		return "key-" + strconv.FormatUint(p.zipf.Uint64(), 10)
+	}
+
+	return "key-" + strconv.Itoa(p.random.IntN(p.keys))
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/abema/crema"
+	cremamemcache "github.com/abema/crema/ext/gomemcache"
+	cremarueidis "github.com/abema/crema/ext/rueidis"
+	cremavalkey "github.com/abema/crema/ext/valkey-go"
+	"github.com/bradfitz/gomemcache/memcache"
+	"github.com/redis/rueidis"
+	"github.com/valkey-io/valkey-go"
+)
+
+const (
+	backendRedis     = "redis"
+	backendValkey    = "valkey"
+	backendMemcached = "memcached"
+
+	defaultRedisAddr     = "127.0.0.1:6379"
+	defaultMemcachedAddr = "127.0.0.1:11211"
+)
+
+// openProvider connects to the backend at addr through the same provider
+// implementation the cache uses. The returned function closes the connection.
+func openProvider(backend, addr string) (crema.CacheProvider[[]byte], func(), error) {
+	switch backend {
+	case backendRedis:
+		client, err := rueidis.NewClient(rueidis.ClientOption{
+			InitAddress:  []string{withDefault(addr, defaultRedisAddr)},
+			DisableCache: true,
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return cremarueidis.NewRedisCacheProvider(client), client.Close, nil
+	case backendValkey:
+		client, err := valkey.NewClient(valkey.ClientOption{
+			InitAddress:  []string{withDefault(addr, defaultRedisAddr)},
+			DisableCache: true,
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return cremavalkey.NewValkeyCacheProvider(client), client.Close, nil
+	case backendMemcached:
+		client := memcache.New(withDefault(addr, defaultMemcachedAddr))
+
+		return cremamemcache.NewMemcachedCacheProvider(client), func() { _ = client.Close() }, nil
+	default:
+		return nil, nil, fmt.Errorf("unknown backend %q", backend)
+	}
+}
+
+func withDefault(addr, fallback string) string {
+	if addr == "" {
+		return fallback
+	}
+
+	return addr
+}
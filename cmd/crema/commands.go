@@ -0,0 +1,130 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"time"
+
+	"github.com/abema/crema/internal/strategyspec"
+)
+
+const reportWidth = 15
+
+var errExpiryNotInFuture = errors.New("expiry must be in the future; use delete to remove the entry")
+
+func inspectFlags(fs *flag.FlagSet, conn *connection) {
+	fs.StringVar(&conn.strategy, "strategy", defaultStrategy,
+		"revalidation strategy of the cache: exponential:<window>, linear:<window>, relative:<fraction>, "+
+			"fixed:<fraction> or xfetch:<beta>")
+}
+
+func runInspect(ctx context.Context, c *cli, conn *connection, args []string) error {
+	strategy, err := strategyspec.Parse(conn.strategy, 0)
+	if err != nil {
+		return err
+	}
+	key := args[0]
+	data, e, err := getEntry(ctx, conn, key)
+	if err != nil {
+		return err
+	}
+
+	now := c.now()
+	expireAt := time.UnixMilli(e.object.ExpireAtMillis)
+	writeField(c, "key", key)
+	writeField(c, "size", fmt.Sprintf("%d bytes", len(data)))
+	writeField(c, "checksum", e.format.checksumName())
+	writeField(c, "compression", e.format.compression())
+	writeField(c, "envelope", e.format.envelope)
+	writeField(c, "expire_at", fmt.Sprintf("%s (%d)", expireAt.UTC().Format(time.RFC3339Nano), e.object.ExpireAtMillis))
+	writeField(c, "remaining_ttl", expireAt.Sub(now).Round(time.Millisecond).String())
+	writeField(c, "stored_ttl", formatMillis(e.object.TTLMillis))
+	writeField(c, "load_duration", formatMillis(e.object.LoadDurationMillis))
+	writeField(c, "revalidation", describeRevalidation(strategy, key, e.object, now))
+	fmt.Fprintf(c.stdout, "value:\n%s\n", formatValue(e.format.envelope, e.object.Value))
+
+	return nil
+}
+
+func runDelete(ctx context.Context, c *cli, conn *connection, args []string) error {
+	if err := conn.provider.Delete(ctx, args[0]); err != nil {
+		return fmt.Errorf("delete %q: %w", args[0], err)
+	}
+	fmt.Fprintf(c.stdout, "deleted %s\n", args[0])
+
+	return nil
+}
+
+// runSetExpiry rewrites ExpireAtMillis of an entry in its original encoding
+// and stores it with the matching provider TTL, as crema.Cache.Set does.
+func runSetExpiry(ctx context.Context, c *cli, conn *connection, args []string) error {
+	key := args[0]
+	now := c.now()
+	expireAt, err := parseExpiry(args[1], now)
+	if err != nil {
+		return err
+	}
+	ttl := expireAt.Sub(now)
+	if ttl <= 0 {
+		return errExpiryNotInFuture
+	}
+	_, e, err := getEntry(ctx, conn, key)
+	if err != nil {
+		return err
+	}
+
+	e.object.ExpireAtMillis = expireAt.UnixMilli()
+	data, err := e.encode()
+	if err != nil {
+		return fmt.Errorf("encode %q: %w", key, err)
+	}
+	if err := conn.provider.Set(ctx, key, data, ttl); err != nil {
+		return fmt.Errorf("set %q: %w", key, err)
+	}
+	fmt.Fprintf(c.stdout, "%s now expires at %s (in %v)\n", key, expireAt.UTC().Format(time.RFC3339Nano), ttl.Round(time.Millisecond))
+
+	return nil
+}
+
+// parseExpiry parses a duration from now or an RFC 3339 time.
+func parseExpiry(value string, now time.Time) (time.Time, error) {
+	if d, err := time.ParseDuration(value); err == nil {
+		return now.Add(d), nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid expiry %q: want a duration or an RFC 3339 time", value)
+	}
+
+	return t, nil
+}
+
+func getEntry(ctx context.Context, conn *connection, key string) ([]byte, entry, error) {
+	data, ok, err := conn.provider.Get(ctx, key)
+	if err != nil {
+		return nil, entry{}, fmt.Errorf("get %q: %w", key, err)
+	}
+	if !ok {
+		return nil, entry{}, fmt.Errorf("key %q not found", key)
+	}
+	e, err := decodeEntry(data)
+	if err != nil {
+		return nil, entry{}, fmt.Errorf("decode %q: %w", key, err)
+	}
+
+	return data, e, nil
+}
+
+func writeField(c *cli, name, value string) {
+	fmt.Fprintf(c.stdout, "%-*s %s\n", reportWidth, name+":", value)
+}
+
+func formatMillis(millis int64) string {
+	if millis == 0 {
+		return "unknown"
+	}
+
+	return (time.Duration(millis) * time.Millisecond).String()
+}
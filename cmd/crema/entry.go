@@ -0,0 +1,299 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/abema/crema"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+const (
+	envelopeJSON     = "json"
+	envelopeProtobuf = "protobuf"
+
+	// Field numbers of ProtoCacheObject in ext/protobuf.
+	protoFieldVersion            = 1
+	protoFieldSerializedValue    = 2
+	protoFieldExpireAtMillis     = 3
+	protoFieldLoadDurationMillis = 4
+	protoFieldTTLMillis          = 5
+	protoEnvelopeVersion         = 1
+)
+
+var (
+	errEmptyEntry              = errors.New("empty entry")
+	errUnsupportedProtoVersion = errors.New("unsupported protobuf envelope version")
+	errUnexpectedProtoWireType = errors.New("unexpected protobuf wire type")
+	errUnrecognizedEntry       = errors.New("unrecognized entry encoding")
+)
+
+// storageFormat describes how an entry is laid out in storage.
+type storageFormat struct {
+	// checksum reports whether the entry starts with the checksum header
+	// written by crema.NewChecksumCodec.
+	checksum bool
+	// header reports whether the entry starts with the compression type byte
+	// written by crema.NewBinaryCompressionCodec.
+	header        bool
+	compressionID byte
+	envelope      string
+}
+
+func (f storageFormat) checksumName() string {
+	if f.checksum {
+		return "crc32c"
+	}
+
+	return "none"
+}
+
+func (f storageFormat) compression() string {
+	switch {
+	case !f.header:
+		return "none (no compression type byte)"
+	case f.compressionID == crema.CompressionTypeIDZlib:
+		return "zlib"
+	default:
+		return "none"
+	}
+}
+
+// codec returns a codec reading and writing entries in this format, with the
+// value kept in its serialized form.
+func (f storageFormat) codec() crema.CacheStorageCodec[[]byte, []byte] {
+	var codec crema.CacheStorageCodec[[]byte, []byte] = protoEnvelopeCodec{}
+	if f.envelope == envelopeJSON {
+		codec = jsonEnvelopeCodec{}
+	}
+	if f.header {
+		threshold := -1
+		if f.compressionID == crema.CompressionTypeIDZlib {
+			threshold = 0
+		}
+		codec = crema.NewBinaryCompressionCodec(codec, threshold)
+	}
+	if f.checksum {
+		codec = crema.NewChecksumCodec(codec)
+	}
+
+	return codec
+}
+
+// entry is a stored cache entry together with the format it was found in.
+type entry struct {
+	format storageFormat
+	object crema.CacheObject[[]byte]
+}
+
+// decodeEntry detects the checksum header, compression type byte and envelope
+// of data and decodes it. The checksum is verified, failing with an error
+// wrapping crema.ErrChecksumMismatch. The value is left serialized, as JSON or
+// as the protobuf message bytes.
+func decodeEntry(data []byte) (entry, error) {
+	payload, checksum, err := stripChecksum(data)
+	if err != nil {
+		return entry{}, err
+	}
+	candidates := []storageFormat{
+		{checksum: checksum, envelope: envelopeJSON},
+		{checksum: checksum, envelope: envelopeProtobuf},
+	}
+	if payload[0] == crema.CompressionTypeIDNone || payload[0] == crema.CompressionTypeIDZlib {
+		candidates = []storageFormat{
+			{checksum: checksum, header: true, compressionID: payload[0], envelope: envelopeJSON},
+			{checksum: checksum, header: true, compressionID: payload[0], envelope: envelopeProtobuf},
+		}
+	}
+
+	errs := make([]error, 0, len(candidates))
+	for _, format := range candidates {
+		object, err := format.codec().Decode(data)
+		if err == nil {
+			return entry{format: format, object: object}, nil
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", format.envelope, err))
+	}
+
+	return entry{}, fmt.Errorf("%w: %w", errUnrecognizedEntry, errors.Join(errs...))
+}
+
+// stripChecksum verifies and removes the checksum header of data, if any, and
+// reports whether there was one.
+func stripChecksum(data []byte) ([]byte, bool, error) {
+	if len(data) == 0 {
+		return nil, false, errEmptyEntry
+	}
+	if data[0] != crema.ChecksumTypeIDCRC32C {
+		return data, false, nil
+	}
+	object, err := crema.NewChecksumCodec[[]byte](rawCodec{}).Decode(data)
+	if err != nil {
+		return nil, false, err
+	}
+	if len(object.Value) == 0 {
+		return nil, false, errEmptyEntry
+	}
+
+	return object.Value, true, nil
+}
+
+// encode re-encodes the entry in the format it was found in, recomputing its
+// checksum if it had one.
+func (e entry) encode() ([]byte, error) {
+	return e.format.codec().Encode(e.object)
+}
+
+// rawCodec passes data through unchanged as the value, so that
+// crema.NewChecksumCodec can verify and strip a checksum header alone.
+type rawCodec struct{}
+
+func (rawCodec) Encode(value crema.CacheObject[[]byte]) ([]byte, error) {
+	return value.Value, nil
+}
+
+func (rawCodec) Decode(data []byte) (crema.CacheObject[[]byte], error) {
+	return crema.CacheObject[[]byte]{Value: data}, nil
+}
+
+// jsonEnvelopeCodec reads entries written by crema.JSONByteStringCodec.
+type jsonEnvelopeCodec struct{}
+
+func (jsonEnvelopeCodec) Encode(value crema.CacheObject[[]byte]) ([]byte, error) {
+	return crema.JSONByteStringCodec[json.RawMessage]{}.Encode(convertObject[[]byte, json.RawMessage](value))
+}
+
+func (jsonEnvelopeCodec) Decode(data []byte) (crema.CacheObject[[]byte], error) {
+	object, err := crema.JSONByteStringCodec[json.RawMessage]{}.Decode(data)
+	if err != nil {
+		return crema.CacheObject[[]byte]{}, err
+	}
+
+	return convertObject[json.RawMessage, []byte](object), nil
+}
+
+func convertObject[From, To ~[]byte](object crema.CacheObject[From]) crema.CacheObject[To] {
+	return crema.CacheObject[To]{
+		Value:              To(object.Value),
+		ExpireAtMillis:     object.ExpireAtMillis,
+		LoadDurationMillis: object.LoadDurationMillis,
+		TTLMillis:          object.TTLMillis,
+	}
+}
+
+// protoEnvelopeCodec reads entries written by the ext/protobuf codec without
+// knowing the message type of the value.
+type protoEnvelopeCodec struct{}
+
+func (protoEnvelopeCodec) Encode(value crema.CacheObject[[]byte]) ([]byte, error) {
+	// ProtoCacheObject fields have explicit presence and are always set, so
+	// they are always written.
+	b := protowire.AppendTag(nil, protoFieldVersion, protowire.VarintType)
+	b = protowire.AppendVarint(b, protoEnvelopeVersion)
+	b = protowire.AppendTag(b, protoFieldSerializedValue, protowire.BytesType)
+	b = protowire.AppendBytes(b, value.Value)
+	for _, field := range []struct {
+		num   protowire.Number
+		value int64
+	}{
+		{num: protoFieldExpireAtMillis, value: value.ExpireAtMillis},
+		{num: protoFieldLoadDurationMillis, value: value.LoadDurationMillis},
+		{num: protoFieldTTLMillis, value: value.TTLMillis},
+	} {
+		b = protowire.AppendTag(b, field.num, protowire.VarintType)
+		b = protowire.AppendVarint(b, uint64(field.value))
+	}
+
+	return b, nil
+}
+
+func (protoEnvelopeCodec) Decode(data []byte) (crema.CacheObject[[]byte], error) {
+	var object crema.CacheObject[[]byte]
+	version := uint64(0)
+	for len(data) > 0 {
+		num, typ, n := protowire.ConsumeTag(data)
+		if n < 0 {
+			return crema.CacheObject[[]byte]{}, protowire.ParseError(n)
+		}
+		data = data[n:]
+		n, err := decodeProtoField(&object, &version, num, typ, data)
+		if err != nil {
+			return crema.CacheObject[[]byte]{}, err
+		}
+		data = data[n:]
+	}
+	if version != protoEnvelopeVersion {
+		return crema.CacheObject[[]byte]{}, fmt.Errorf("%w: %d", errUnsupportedProtoVersion, version)
+	}
+
+	return object, nil
+}
+
+// decodeProtoField decodes one ProtoCacheObject field and returns its length.
+func decodeProtoField(
+	object *crema.CacheObject[[]byte],
+	version *uint64,
+	num protowire.Number,
+	typ protowire.Type,
+	data []byte,
+) (int, error) {
+	switch num {
+	case protoFieldSerializedValue:
+		value, n, err := consumeBytesField(num, typ, data)
+		object.Value = append([]byte{}, value...)
+
+		return n, err
+	case protoFieldVersion:
+		value, n, err := consumeVarintField(num, typ, data)
+		*version = value
+
+		return n, err
+	case protoFieldExpireAtMillis:
+		value, n, err := consumeVarintField(num, typ, data)
+		object.ExpireAtMillis = int64(value)
+
+		return n, err
+	case protoFieldLoadDurationMillis:
+		value, n, err := consumeVarintField(num, typ, data)
+		object.LoadDurationMillis = int64(value)
+
+		return n, err
+	case protoFieldTTLMillis:
+		value, n, err := consumeVarintField(num, typ, data)
+		object.TTLMillis = int64(value)
+
+		return n, err
+	default:
+		n := protowire.ConsumeFieldValue(num, typ, data)
+		if n < 0 {
+			return 0, protowire.ParseError(n)
+		}
+
+		return n, nil
+	}
+}
+
+func consumeVarintField(num protowire.Number, typ protowire.Type, data []byte) (uint64, int, error) {
+	if typ != protowire.VarintType {
+		return 0, 0, fmt.Errorf("%w for field %d", errUnexpectedProtoWireType, num)
+	}
+	value, n := protowire.ConsumeVarint(data)
+	if n < 0 {
+		return 0, 0, protowire.ParseError(n)
+	}
+
+	return value, n, nil
+}
+
+func consumeBytesField(num protowire.Number, typ protowire.Type, data []byte) ([]byte, int, error) {
+	if typ != protowire.BytesType {
+		return nil, 0, fmt.Errorf("%w for field %d", errUnexpectedProtoWireType, num)
+	}
+	value, n := protowire.ConsumeBytes(data)
+	if n < 0 {
+		return nil, 0, protowire.ParseError(n)
+	}
+
+	return value, n, nil
+}
@@ -0,0 +1,234 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/abema/crema"
+	"github.com/abema/crema/ext/protobuf"
+	"github.com/abema/crema/internal/strategyspec"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+var testObject = crema.CacheObject[string]{Value: "hello", ExpireAtMillis: 1760000000000, LoadDurationMillis: 120, TTLMillis: 300000}
+
+func encodeJSON(t *testing.T, threshold int, header bool) []byte {
+	t.Helper()
+
+	var codec crema.CacheStorageCodec[string, []byte] = crema.JSONByteStringCodec[string]{}
+	if header {
+		codec = crema.NewBinaryCompressionCodec(codec, threshold)
+	}
+	data, err := codec.Encode(testObject)
+	if err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+
+	return data
+}
+
+func encodeProto(t *testing.T, threshold int, header bool) []byte {
+	t.Helper()
+
+	inner, err := protobuf.NewProtobufCodec(&wrapperspb.StringValue{})
+	if err != nil {
+		t.Fatalf("NewProtobufCodec() error = %v", err)
+	}
+	var codec crema.CacheStorageCodec[*wrapperspb.StringValue, []byte] = inner
+	if header {
+		codec = crema.NewBinaryCompressionCodec(codec, threshold)
+	}
+	data, err := codec.Encode(crema.CacheObject[*wrapperspb.StringValue]{
+		Value:              wrapperspb.String(testObject.Value),
+		ExpireAtMillis:     testObject.ExpireAtMillis,
+		LoadDurationMillis: testObject.LoadDurationMillis,
+		TTLMillis:          testObject.TTLMillis,
+	})
+	if err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+
+	return data
+}
+
+// withChecksum prefixes data with the header written by crema.NewChecksumCodec.
+func withChecksum(t *testing.T, data []byte) []byte {
+	t.Helper()
+
+	data, err := crema.NewChecksumCodec[[]byte](rawCodec{}).Encode(crema.CacheObject[[]byte]{Value: data})
+	if err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+
+	return data
+}
+
+func TestDecodeEntry_DetectsFormats(t *testing.T) {
+	t.Parallel()
+
+	const noHeader = "none (no compression type byte)"
+	cases := []struct {
+		name        string
+		data        []byte
+		envelope    string
+		compression string
+		checksum    string
+		value       string
+	}{
+		{name: "json", data: encodeJSON(t, 0, false), envelope: envelopeJSON, compression: noHeader, value: `"hello"`},
+		{name: "json/none", data: encodeJSON(t, -1, true), envelope: envelopeJSON, compression: "none", value: `"hello"`},
+		{name: "json/zlib", data: encodeJSON(t, 0, true), envelope: envelopeJSON, compression: "zlib", value: `"hello"`},
+		{name: "protobuf", data: encodeProto(t, 0, false), envelope: envelopeProtobuf, compression: noHeader, value: `1: "hello"`},
+		{name: "protobuf/none", data: encodeProto(t, -1, true), envelope: envelopeProtobuf, compression: "none", value: `1: "hello"`},
+		{name: "protobuf/zlib", data: encodeProto(t, 0, true), envelope: envelopeProtobuf, compression: "zlib", value: `1: "hello"`},
+		{
+			name: "crc32c/json", data: withChecksum(t, encodeJSON(t, 0, false)),
+			envelope: envelopeJSON, compression: noHeader, checksum: "crc32c", value: `"hello"`,
+		},
+		{
+			name: "crc32c/json/zlib", data: withChecksum(t, encodeJSON(t, 0, true)),
+			envelope: envelopeJSON, compression: "zlib", checksum: "crc32c", value: `"hello"`,
+		},
+		{
+			name: "crc32c/protobuf/none", data: withChecksum(t, encodeProto(t, -1, true)),
+			envelope: envelopeProtobuf, compression: "none", checksum: "crc32c", value: `1: "hello"`,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			e, err := decodeEntry(tc.data)
+			if err != nil {
+				t.Fatalf("decodeEntry() error = %v", err)
+			}
+			checksum := tc.checksum
+			if checksum == "" {
+				checksum = "none"
+			}
+			if e.format.envelope != tc.envelope || e.format.compression() != tc.compression || e.format.checksumName() != checksum {
+				t.Fatalf("format = %s/%s/%s, want %s/%s/%s", e.format.envelope, e.format.compression(), e.format.checksumName(),
+					tc.envelope, tc.compression, checksum)
+			}
+			if e.object.ExpireAtMillis != testObject.ExpireAtMillis ||
+				e.object.LoadDurationMillis != testObject.LoadDurationMillis ||
+				e.object.TTLMillis != testObject.TTLMillis {
+				t.Fatalf("object = %+v, want the metadata of %+v", e.object, testObject)
+			}
+			if got := formatValue(e.format.envelope, e.object.Value); got != tc.value {
+				t.Fatalf("formatValue() = %q, want %q", got, tc.value)
+			}
+
+			encoded, err := e.encode()
+			if err != nil {
+				t.Fatalf("encode() error = %v", err)
+			}
+			if !bytes.Equal(encoded, tc.data) {
+				t.Fatalf("encode() = %x, want the original %x", encoded, tc.data)
+			}
+		})
+	}
+}
+
+func TestDecodeEntry_RejectsUnknownData(t *testing.T) {
+	t.Parallel()
+
+	for _, data := range [][]byte{nil, {0x02, '{', '}'}, []byte("plain text"), {crema.CompressionTypeIDZlib, 0xff}} {
+		if _, err := decodeEntry(data); err == nil {
+			t.Fatalf("decodeEntry(%q) error = nil, want an error", data)
+		}
+	}
+	if _, err := decodeEntry([]byte("plain text")); !errors.Is(err, errUnrecognizedEntry) {
+		t.Fatalf("decodeEntry() error = %v, want errUnrecognizedEntry", err)
+	}
+}
+
+func TestDecodeEntry_VerifiesChecksum(t *testing.T) {
+	t.Parallel()
+
+	data := withChecksum(t, encodeJSON(t, 0, true))
+	data[len(data)-1] ^= 0xff
+	if _, err := decodeEntry(data); !errors.Is(err, crema.ErrChecksumMismatch) {
+		t.Fatalf("decodeEntry() error = %v, want crema.ErrChecksumMismatch", err)
+	}
+	if _, err := decodeEntry(withChecksum(t, nil)); !errors.Is(err, errEmptyEntry) {
+		t.Fatalf("decodeEntry() error = %v, want errEmptyEntry", err)
+	}
+}
+
+func TestFormatValue_DumpsProtobufFields(t *testing.T) {
+	t.Parallel()
+
+	value, err := structpb.NewStruct(map[string]any{"id": 42.0})
+	if err != nil {
+		t.Fatalf("NewStruct() error = %v", err)
+	}
+	inner, err := protobuf.NewProtobufCodec(&structpb.Struct{})
+	if err != nil {
+		t.Fatalf("NewProtobufCodec() error = %v", err)
+	}
+	data, err := inner.Encode(crema.CacheObject[*structpb.Struct]{Value: value})
+	if err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+	e, err := decodeEntry(data)
+	if err != nil {
+		t.Fatalf("decodeEntry() error = %v", err)
+	}
+
+	want := strings.Join([]string{
+		"1 {",
+		`  1: "id"`,
+		"  2 {",
+		"    2: 0x4045000000000000",
+		"  }",
+		"}",
+	}, "\n")
+	if got := formatValue(envelopeProtobuf, e.object.Value); got != want {
+		t.Fatalf("formatValue() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestDescribeRevalidation(t *testing.T) {
+	t.Parallel()
+
+	now := time.UnixMilli(testObject.ExpireAtMillis)
+	strategy := crema.NewLinearRevalidation(time.Minute)
+	object := crema.CacheObject[[]byte]{ExpireAtMillis: testObject.ExpireAtMillis}
+	cases := []struct {
+		strategy  crema.RevalidationStrategy
+		remaining time.Duration
+		want      string
+	}{
+		{strategy: strategy, remaining: 0, want: "expired"},
+		{strategy: strategy, remaining: 90 * time.Second, want: "before the 1m0s window, which starts in 30s"},
+		{strategy: strategy, remaining: 15 * time.Second, want: "in the 1m0s window, 75% through, revalidation probability 0.7500"},
+		{strategy: crema.NewRelativeExponentialRevalidation(0.1), remaining: time.Second, want: "no early revalidation window"},
+	}
+	for _, tc := range cases {
+		got := describeRevalidation(tc.strategy, "key", object, now.Add(-tc.remaining))
+		if !strings.HasPrefix(got, tc.want) {
+			t.Fatalf("describeRevalidation(%v) = %q, want prefix %q", tc.remaining, got, tc.want)
+		}
+	}
+}
+
+func TestDescribeRevalidation_UsesStoredLoadDuration(t *testing.T) {
+	t.Parallel()
+
+	strategy, err := strategyspec.Parse("xfetch:1", 0)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	object := crema.CacheObject[[]byte]{ExpireAtMillis: testObject.ExpireAtMillis, LoadDurationMillis: 1000}
+	now := time.UnixMilli(testObject.ExpireAtMillis).Add(-2 * time.Second)
+
+	want := "in the 6.907755278s window, 71% through, revalidation probability 0.1353"
+	if got := describeRevalidation(strategy, "key", object, now); got != want {
+		t.Fatalf("describeRevalidation() = %q, want %q", got, want)
+	}
+}
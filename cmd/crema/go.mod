@@ -0,0 +1,22 @@
+module github.com/abema/crema/cmd/crema
+
+go 1.24.9
+
+require (
+	github.com/abema/crema v0.1.3
+	github.com/abema/crema/ext/gomemcache v0.1.3
+	github.com/abema/crema/ext/protobuf v0.1.3
+	github.com/abema/crema/ext/rueidis v0.1.3
+	github.com/abema/crema/ext/valkey-go v0.1.3
+	github.com/alicebob/miniredis/v2 v2.36.1
+	github.com/bradfitz/gomemcache v0.0.0-20250403215159-8d39553ac7cf
+	github.com/redis/rueidis v1.0.71
+	github.com/valkey-io/valkey-go v1.0.71
+	google.golang.org/protobuf v1.36.11
+)
+
+require (
+	github.com/yuin/gopher-lua v1.1.1 // indirect
+	golang.org/x/sys v0.39.0 // indirect
+)
+
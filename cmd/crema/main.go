@@ -0,0 +1,149 @@
+// Command crema inspects and edits cache entries stored by crema in Redis,
+// Valkey or Memcached.
+//
+// It detects the compression type byte and the JSON or protobuf envelope of
+// an entry and prints its value, expiry and position in the revalidation
+// window, so stored entries no longer have to be decoded by hand.
+//
+// Usage:
+//
+//	crema inspect [flags] <key>
+//	crema delete [flags] <key>
+//	crema set-expiry [flags] <key> <duration|RFC 3339 time>
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/abema/crema"
+)
+
+const (
+	defaultTimeout = 5 * time.Second
+	exitUsage      = 2
+	keyArgs        = 1
+	keyValueArgs   = 2
+)
+
+// cli runs the subcommands; its fields are replaced in tests.
+type cli struct {
+	stdout io.Writer
+	stderr io.Writer
+	now    func() time.Time
+	open   func(backend, addr string) (crema.CacheProvider[[]byte], func(), error)
+}
+
+type command struct {
+	name  string
+	args  string
+	nargs int
+	usage string
+	// flags registers the flags specific to the command.
+	flags func(fs *flag.FlagSet, conn *connection)
+	run   func(ctx context.Context, c *cli, conn *connection, args []string) error
+}
+
+var commands = []command{
+	{name: "inspect", args: "<key>", nargs: keyArgs, usage: "decode and print an entry", flags: inspectFlags, run: runInspect},
+	{name: "delete", args: "<key>", nargs: keyArgs, usage: "delete an entry", run: runDelete},
+	{
+		name: "set-expiry", args: "<key> <duration|RFC 3339 time>", nargs: keyValueArgs,
+		usage: "rewrite the expiry of an entry, keeping its value and encoding", run: runSetExpiry,
+	},
+}
+
+// connection holds the command line flags and the connected provider.
+type connection struct {
+	backend  string
+	addr     string
+	timeout  time.Duration
+	strategy string
+	provider crema.CacheProvider[[]byte]
+}
+
+func main() {
+	c := &cli{stdout: os.Stdout, stderr: os.Stderr, now: time.Now, open: openProvider}
+	os.Exit(c.run(os.Args[1:]))
+}
+
+// run runs the subcommand named by args[0] and returns the exit code.
+func (c *cli) run(args []string) int {
+	if len(args) == 0 {
+		c.usage()
+
+		return exitUsage
+	}
+	for _, cmd := range commands {
+		if cmd.name == args[0] {
+			return c.runCommand(cmd, args[1:])
+		}
+	}
+	if args[0] != "-h" && args[0] != "-help" && args[0] != "help" {
+		fmt.Fprintf(c.stderr, "unknown command %q\n", args[0])
+	}
+	c.usage()
+
+	return exitUsage
+}
+
+func (c *cli) usage() {
+	fmt.Fprintln(c.stderr, "usage: crema <command> [flags] <args>")
+	fmt.Fprintln(c.stderr, "\ncommands:")
+	for _, cmd := range commands {
+		fmt.Fprintf(c.stderr, "  %-11s %s\n", cmd.name, cmd.usage)
+	}
+	fmt.Fprintln(c.stderr, "\nrun crema <command> -h for the flags of a command")
+}
+
+func (c *cli) runCommand(cmd command, args []string) int {
+	var conn connection
+	fs := flag.NewFlagSet(cmd.name, flag.ContinueOnError)
+	fs.SetOutput(c.stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(c.stderr, "usage: crema %s [flags] %s\n\n%s\n\nflags:\n", cmd.name, cmd.args, cmd.usage)
+		fs.PrintDefaults()
+	}
+	fs.StringVar(&conn.backend, "backend", backendRedis, "backend: redis, valkey or memcached")
+	fs.StringVar(&conn.addr, "addr", "", "backend address (default 127.0.0.1:6379, or 127.0.0.1:11211 for memcached)")
+	fs.DurationVar(&conn.timeout, "timeout", defaultTimeout, "timeout of the whole command")
+	if cmd.flags != nil {
+		cmd.flags(fs, &conn)
+	}
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+
+		return exitUsage
+	}
+	if fs.NArg() != cmd.nargs {
+		fs.Usage()
+
+		return exitUsage
+	}
+
+	provider, closeProvider, err := c.open(conn.backend, conn.addr)
+	if err != nil {
+		fmt.Fprintln(c.stderr, err.Error())
+
+		return 1
+	}
+	defer closeProvider()
+	conn.provider = provider
+
+	ctx, cancel := context.WithTimeout(context.Background(), conn.timeout)
+	defer cancel()
+	if err := cmd.run(ctx, c, &conn, fs.Args()); err != nil {
+		fmt.Fprintln(c.stderr, err.Error())
+
+		return 1
+	}
+
+	return 0
+}
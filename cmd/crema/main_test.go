@@ -0,0 +1,164 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/abema/crema"
+	"github.com/abema/crema/crematest"
+	"github.com/alicebob/miniredis/v2"
+)
+
+func newTestCLI(now time.Time) (*cli, *bytes.Buffer, *bytes.Buffer) {
+	var stdout, stderr bytes.Buffer
+
+	return &cli{stdout: &stdout, stderr: &stderr, now: func() time.Time { return now }, open: openProvider}, &stdout, &stderr
+}
+
+func TestInspect_Redis(t *testing.T) {
+	t.Parallel()
+
+	server := miniredis.RunT(t)
+	server.Set("user:1", string(encodeJSON(t, 0, true)))
+	now := time.UnixMilli(testObject.ExpireAtMillis).Add(-30 * time.Second)
+
+	c, stdout, stderr := newTestCLI(now)
+	if code := c.run([]string{"inspect", "-addr", server.Addr(), "-strategy", "linear:1m", "user:1"}); code != 0 {
+		t.Fatalf("exit code = %d, stderr = %s", code, stderr)
+	}
+	for _, want := range []string{
+		"key:            user:1",
+		"compression:    zlib",
+		"envelope:       json",
+		"expire_at:      2025-10-09T08:53:20Z (1760000000000)",
+		"remaining_ttl:  30s",
+		"stored_ttl:     5m0s",
+		"load_duration:  120ms",
+		"revalidation:   in the 1m0s window, 50% through, revalidation probability 0.5000",
+		"value:\n\"hello\"",
+	} {
+		if !strings.Contains(stdout.String(), want) {
+			t.Fatalf("output = %s\nwant it to contain %q", stdout, want)
+		}
+	}
+}
+
+func TestInspect_MissingKey(t *testing.T) {
+	t.Parallel()
+
+	server := miniredis.RunT(t)
+	c, _, stderr := newTestCLI(time.Now())
+	if code := c.run([]string{"inspect", "-backend", backendValkey, "-addr", server.Addr(), "missing"}); code != 1 {
+		t.Fatalf("exit code = %d, want 1", code)
+	}
+	if !strings.Contains(stderr.String(), `key "missing" not found`) {
+		t.Fatalf("stderr = %q", stderr)
+	}
+}
+
+func TestSetExpiry_Valkey(t *testing.T) {
+	t.Parallel()
+
+	server := miniredis.RunT(t)
+	original := encodeProto(t, -1, true)
+	server.Set("user:1", string(original))
+	now := time.UnixMilli(testObject.ExpireAtMillis).Add(-time.Hour)
+
+	c, stdout, stderr := newTestCLI(now)
+	if code := c.run([]string{"set-expiry", "-backend", backendValkey, "-addr", server.Addr(), "user:1", "10m"}); code != 0 {
+		t.Fatalf("exit code = %d, stderr = %s", code, stderr)
+	}
+	if !strings.Contains(stdout.String(), "user:1 now expires at") {
+		t.Fatalf("stdout = %q", stdout)
+	}
+	if ttl := server.TTL("user:1"); ttl != 10*time.Minute {
+		t.Fatalf("server TTL = %v, want 10m", ttl)
+	}
+
+	stored, err := server.Get("user:1")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	e, err := decodeEntry([]byte(stored))
+	if err != nil {
+		t.Fatalf("decodeEntry() error = %v", err)
+	}
+	if want := now.Add(10 * time.Minute).UnixMilli(); e.object.ExpireAtMillis != want {
+		t.Fatalf("ExpireAtMillis = %d, want %d", e.object.ExpireAtMillis, want)
+	}
+	if e.format.envelope != envelopeProtobuf || e.object.TTLMillis != testObject.TTLMillis || len(stored) != len(original) {
+		t.Fatalf("entry = %+v, want only the expiry to change", e)
+	}
+}
+
+func TestSetExpiry_RejectsPastExpiry(t *testing.T) {
+	t.Parallel()
+
+	provider := crematest.NewRecordingProvider[[]byte](nil)
+	c, _, stderr := newTestCLI(time.Now())
+	c.open = func(string, string) (crema.CacheProvider[[]byte], func(), error) {
+		return provider, func() {}, nil
+	}
+	if code := c.run([]string{"set-expiry", "key", "2000-01-01T00:00:00Z"}); code != 1 {
+		t.Fatalf("exit code = %d, want 1", code)
+	}
+	if !strings.Contains(stderr.String(), errExpiryNotInFuture.Error()) {
+		t.Fatalf("stderr = %q", stderr)
+	}
+	if calls := provider.Calls(); len(calls) != 0 {
+		t.Fatalf("provider calls = %+v, want none", calls)
+	}
+}
+
+func TestDelete(t *testing.T) {
+	t.Parallel()
+
+	provider := crematest.NewRecordingProvider[[]byte](nil)
+	if err := provider.Set(context.Background(), "key", []byte("value"), time.Minute); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	c, stdout, stderr := newTestCLI(time.Now())
+	var backend string
+	c.open = func(b string, _ string) (crema.CacheProvider[[]byte], func(), error) {
+		backend = b
+
+		return provider, func() {}, nil
+	}
+	if code := c.run([]string{"delete", "-backend", backendMemcached, "key"}); code != 0 {
+		t.Fatalf("exit code = %d, stderr = %s", code, stderr)
+	}
+	if backend != backendMemcached || stdout.String() != "deleted key\n" {
+		t.Fatalf("backend = %q, stdout = %q", backend, stdout)
+	}
+	if _, ok, _ := provider.Get(context.Background(), "key"); ok {
+		t.Fatal("expected the key to be deleted")
+	}
+}
+
+func TestRun_UsageErrors(t *testing.T) {
+	t.Parallel()
+
+	for _, args := range [][]string{
+		nil,
+		{"unknown"},
+		{"inspect"},
+		{"set-expiry", "key"},
+		{"delete", "-nope", "key"},
+	} {
+		c, _, _ := newTestCLI(time.Now())
+		if code := c.run(args); code != exitUsage {
+			t.Fatalf("run(%q) exit code = %d, want %d", args, code, exitUsage)
+		}
+	}
+
+	c, _, stderr := newTestCLI(time.Now())
+	if code := c.run([]string{"delete", "-backend", "etcd", "key"}); code != 1 {
+		t.Fatalf("exit code = %d, want 1", code)
+	}
+	if !strings.Contains(stderr.String(), `unknown backend "etcd"`) {
+		t.Fatalf("stderr = %q", stderr)
+	}
+}
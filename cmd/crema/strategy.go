@@ -0,0 +1,38 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/abema/crema"
+)
+
+const (
+	defaultStrategy = "exponential:5m"
+	percent         = 100
+)
+
+// describeRevalidation reports where an entry sits relative to the
+// revalidation window of strategy at now.
+func describeRevalidation(strategy crema.RevalidationStrategy, key string, object crema.CacheObject[[]byte], now time.Time) string {
+	entry := crema.RevalidationEntry{
+		Key:            key,
+		NowMillis:      now.UnixMilli(),
+		ExpireAtMillis: object.ExpireAtMillis,
+		TTL:            time.Duration(object.TTLMillis) * time.Millisecond,
+		LoadDuration:   time.Duration(object.LoadDurationMillis) * time.Millisecond,
+	}
+	remaining := time.Duration(entry.RemainingMillis()) * time.Millisecond
+	window := crema.EntryRevalidationWindow(strategy, entry)
+	switch {
+	case remaining <= 0:
+		return "expired, reloaded on the next read"
+	case window <= 0:
+		return "no early revalidation window"
+	case remaining > window:
+		return fmt.Sprintf("before the %v window, which starts in %v", window, remaining-window)
+	default:
+		return fmt.Sprintf("in the %v window, %.0f%% through, revalidation probability %.4f",
+			window, percent*(1-remaining.Seconds()/window.Seconds()), strategy.Probability(entry))
+	}
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+const protoIndent = "  "
+
+// formatValue renders a serialized value for display: indented JSON, or the
+// raw fields of a protobuf message, whose type is unknown here.
+func formatValue(envelope string, value []byte) string {
+	if envelope == envelopeJSON {
+		if len(value) == 0 {
+			return "null"
+		}
+		var b bytes.Buffer
+		if err := json.Indent(&b, value, "", protoIndent); err != nil {
+			return string(value)
+		}
+
+		return b.String()
+	}
+	if len(value) == 0 {
+		return "(empty message)"
+	}
+	var b strings.Builder
+	if err := writeProtoFields(&b, value, 0); err != nil {
+		return "0x" + hex.EncodeToString(value)
+	}
+
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// writeProtoFields writes the fields of a protobuf message one per line,
+// like protoc --decode_raw.
+func writeProtoFields(b *strings.Builder, data []byte, depth int) error {
+	indent := strings.Repeat(protoIndent, depth)
+	for len(data) > 0 {
+		num, typ, n := protowire.ConsumeTag(data)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		data = data[n:]
+		switch typ {
+		case protowire.VarintType:
+			var v uint64
+			v, n = protowire.ConsumeVarint(data)
+			fmt.Fprintf(b, "%s%d: %d\n", indent, num, v)
+		case protowire.Fixed32Type:
+			var v uint32
+			v, n = protowire.ConsumeFixed32(data)
+			fmt.Fprintf(b, "%s%d: 0x%08x\n", indent, num, v)
+		case protowire.Fixed64Type:
+			var v uint64
+			v, n = protowire.ConsumeFixed64(data)
+			fmt.Fprintf(b, "%s%d: 0x%016x\n", indent, num, v)
+		case protowire.BytesType:
+			var v []byte
+			v, n = protowire.ConsumeBytes(data)
+			if n >= 0 {
+				writeProtoBytes(b, indent, num, v, depth)
+			}
+		default:
+			n = protowire.ConsumeFieldValue(num, typ, data)
+			fmt.Fprintf(b, "%s%d: <group>\n", indent, num)
+		}
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		data = data[n:]
+	}
+
+	return nil
+}
+
+// writeProtoBytes writes a length-delimited field as a string when it is
+// printable text, as a nested message when it parses as one, or as hex.
+func writeProtoBytes(b *strings.Builder, indent string, num protowire.Number, v []byte, depth int) {
+	if isPrintable(v) {
+		fmt.Fprintf(b, "%s%d: %s\n", indent, num, strconv.Quote(string(v)))
+
+		return
+	}
+	var nested strings.Builder
+	if err := writeProtoFields(&nested, v, depth+1); err == nil {
+		fmt.Fprintf(b, "%s%d {\n%s%s}\n", indent, num, nested.String(), indent)
+
+		return
+	}
+	fmt.Fprintf(b, "%s%d: 0x%s\n", indent, num, hex.EncodeToString(v))
+}
+
+func isPrintable(v []byte) bool {
+	if !utf8.Valid(v) {
+		return false
+	}
+	for _, r := range string(v) {
+		if !unicode.IsPrint(r) && !unicode.IsSpace(r) {
+			return false
+		}
+	}
+
+	return true
+}
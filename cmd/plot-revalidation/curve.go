@@ -0,0 +1,75 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/abema/crema"
+)
+
+const (
+	strategyExponential = "exponential"
+	strategyLinear      = "linear"
+	strategyXFetch      = "xfetch"
+	minSamples          = 2
+)
+
+// curve is a revalidation probability curve sampled over the remaining time.
+type curve struct {
+	label  string
+	points []point
+}
+
+type point struct {
+	remaining   time.Duration
+	probability float64
+}
+
+// newStrategy returns the library strategy the curves are computed with, so
+// the plots match the runtime behavior exactly.
+func newStrategy(name string, window time.Duration) (crema.RevalidationStrategy, error) {
+	switch name {
+	case strategyExponential:
+		return crema.NewExponentialRevalidation(window), nil
+	case strategyLinear:
+		return crema.NewLinearRevalidation(window), nil
+	case strategyXFetch:
+		return crema.NewXFetchRevalidation(window, 0), nil
+	default:
+		return nil, fmt.Errorf("unknown strategy %q", name)
+	}
+}
+
+// sampleCurves samples one curve per window from 0 to the largest window.
+func sampleCurves(strategyName string, windows []time.Duration, samples int) ([]curve, time.Duration, error) {
+	if len(windows) == 0 {
+		return nil, 0, fmt.Errorf("at least one window is required")
+	}
+	if samples < minSamples {
+		return nil, 0, fmt.Errorf("samples must be >= %d, got %d", minSamples, samples)
+	}
+	maxWindow := windows[0]
+	for _, window := range windows {
+		if window <= 0 {
+			return nil, 0, fmt.Errorf("windows must be positive, got %v", window)
+		}
+		maxWindow = max(maxWindow, window)
+	}
+
+	curves := make([]curve, 0, len(windows))
+	for _, window := range windows {
+		strategy, err := newStrategy(strategyName, window)
+		if err != nil {
+			return nil, 0, err
+		}
+		c := curve{label: fmt.Sprintf("%v %s", window, strategyName), points: make([]point, 0, samples)}
+		for i := range samples {
+			remaining := time.Duration(float64(maxWindow) * float64(i) / float64(samples-1))
+			p := strategy.Probability(crema.RevalidationEntry{ExpireAtMillis: remaining.Milliseconds()})
+			c.points = append(c.points, point{remaining: remaining, probability: p})
+		}
+		curves = append(curves, c)
+	}
+
+	return curves, maxWindow, nil
+}
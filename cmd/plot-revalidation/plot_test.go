@@ -0,0 +1,106 @@
+package main
+
+import (
+	"bytes"
+	"image/png"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/abema/crema"
+)
+
+func TestSampleCurves_MatchesLibrary(t *testing.T) {
+	t.Parallel()
+
+	curves, maxWindow, err := sampleCurves(strategyExponential, []time.Duration{30 * time.Second, time.Minute}, 61)
+	if err != nil {
+		t.Fatalf("sampleCurves() error = %v", err)
+	}
+	if maxWindow != time.Minute {
+		t.Fatalf("maxWindow = %v, want 1m", maxWindow)
+	}
+	if len(curves) != 2 || len(curves[1].points) != 61 {
+		t.Fatalf("unexpected curves: %d curves", len(curves))
+	}
+
+	strategy := crema.NewExponentialRevalidation(time.Minute)
+	for _, p := range curves[1].points {
+		want := strategy.Probability(crema.RevalidationEntry{ExpireAtMillis: p.remaining.Milliseconds()})
+		if p.probability != want {
+			t.Fatalf("probability at %v = %v, want %v", p.remaining, p.probability, want)
+		}
+	}
+	if last := curves[0].points[60]; last.probability != 0 {
+		t.Fatalf("probability outside the window = %v, want 0", last.probability)
+	}
+}
+
+func TestSampleCurves_RejectsInvalidInput(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		strategy string
+		windows  []time.Duration
+		samples  int
+	}{
+		{strategy: "unknown", windows: []time.Duration{time.Minute}, samples: 10},
+		{strategy: strategyLinear, windows: nil, samples: 10},
+		{strategy: strategyLinear, windows: []time.Duration{0}, samples: 10},
+		{strategy: strategyLinear, windows: []time.Duration{time.Minute}, samples: 1},
+	}
+	for _, tc := range cases {
+		if _, _, err := sampleCurves(tc.strategy, tc.windows, tc.samples); err == nil {
+			t.Fatalf("sampleCurves(%q, %v, %d) error = nil, want an error", tc.strategy, tc.windows, tc.samples)
+		}
+	}
+}
+
+func TestRender_Formats(t *testing.T) {
+	t.Parallel()
+
+	curves, maxWindow, err := sampleCurves(strategyXFetch, []time.Duration{time.Minute, 2 * time.Minute}, 10)
+	if err != nil {
+		t.Fatalf("sampleCurves() error = %v", err)
+	}
+	for format, want := range map[string]string{
+		formatSVG:   "1m0s xfetch",
+		formatCSV:   "remain_seconds,1m0s xfetch,2m0s xfetch",
+		formatASCII: "+ 2m0s xfetch",
+	} {
+		var buf bytes.Buffer
+		if err := render(&buf, format, curves, maxWindow); err != nil {
+			t.Fatalf("render(%s) error = %v", format, err)
+		}
+		if !strings.Contains(buf.String(), want) {
+			t.Fatalf("render(%s) = %q, want it to contain %q", format, buf.String(), want)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := render(&buf, formatPNG, curves, maxWindow); err != nil {
+		t.Fatalf("render(png) error = %v", err)
+	}
+	if _, err := png.Decode(&buf); err != nil {
+		t.Fatalf("png.Decode() error = %v", err)
+	}
+	if err := render(&bytes.Buffer{}, "gif", curves, maxWindow); err == nil {
+		t.Fatal("expected an unknown format to be rejected")
+	}
+}
+
+func TestFormatFromPath(t *testing.T) {
+	t.Parallel()
+
+	for path, want := range map[string]string{
+		"out.png": formatPNG,
+		"out.csv": formatCSV,
+		"out.txt": formatASCII,
+		"out.svg": formatSVG,
+		"-":       formatSVG,
+	} {
+		if got := formatFromPath(path); got != want {
+			t.Fatalf("formatFromPath(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
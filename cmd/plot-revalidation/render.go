@@ -0,0 +1,271 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"html"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	formatSVG   = "svg"
+	formatPNG   = "png"
+	formatCSV   = "csv"
+	formatASCII = "ascii"
+
+	plotWidth    = 800
+	plotHeight   = 480
+	plotMargin   = 50
+	tickSize     = 6
+	xTicks       = 6
+	yTicks       = 5
+	legendWidth  = 180
+	legendOffset = 16
+	legendStep   = 18
+	swatchSize   = 10
+	curveWidth   = 3
+	titleOffset  = 45
+	labelOffset  = 12
+	labelGap     = 4
+	tickLabelGap = 14
+
+	asciiWidth  = 72
+	asciiHeight = 20
+)
+
+var (
+	curveColors = []string{"#1e5aa8", "#c2432b", "#2c8a45"}
+	curveGlyphs = []byte{'*', '+', 'o', 'x', '#'}
+)
+
+func render(w io.Writer, format string, curves []curve, maxWindow time.Duration) error {
+	switch format {
+	case formatSVG:
+		return renderSVG(w, curves, maxWindow)
+	case formatPNG:
+		return renderPNG(w, curves, maxWindow)
+	case formatCSV:
+		return renderCSV(w, curves)
+	case formatASCII:
+		return renderASCII(w, curves, maxWindow)
+	default:
+		return fmt.Errorf("unknown format %q", format)
+	}
+}
+
+// plotPosition maps a point to pixel coordinates within the plot area.
+func plotPosition(p point, maxWindow time.Duration) (float64, float64) {
+	innerW := float64(plotWidth - 2*plotMargin)
+	innerH := float64(plotHeight - 2*plotMargin)
+
+	return plotMargin + innerW*p.remaining.Seconds()/maxWindow.Seconds(), plotMargin + innerH*(1-p.probability)
+}
+
+func renderSVG(w io.Writer, curves []curve, maxWindow time.Duration) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, `<?xml version="1.0" encoding="UTF-8"?>`+"\n")
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d">`+"\n",
+		plotWidth, plotHeight, plotWidth, plotHeight)
+	fmt.Fprintf(&b, `<rect x="0" y="0" width="%d" height="%d" fill="#f7f4ef"/>`+"\n", plotWidth, plotHeight)
+	fmt.Fprintf(&b, `<rect x="%d" y="%d" width="%d" height="%d" fill="#ffffff" stroke="#222222" stroke-width="2"/>`+"\n",
+		plotMargin, plotMargin, plotWidth-2*plotMargin, plotHeight-2*plotMargin)
+	fmt.Fprintf(&b, `<text x="%d" y="%d" font-family="Verdana" font-size="14" fill="#222222">remainSeconds</text>`+"\n",
+		plotWidth/2-titleOffset, plotHeight-labelOffset)
+	fmt.Fprintf(&b, `<text x="%d" y="%d" font-family="Verdana" font-size="14" fill="#222222">p(t)</text>`+"\n",
+		legendOffset, plotMargin-legendOffset)
+	writeSVGTicks(&b, maxWindow)
+
+	for idx, c := range curves {
+		color := curveColors[idx%len(curveColors)]
+		coords := make([]string, 0, len(c.points))
+		for _, p := range c.points {
+			x, y := plotPosition(p, maxWindow)
+			coords = append(coords, fmt.Sprintf("%.2f,%.2f", x, y))
+		}
+		fmt.Fprintf(&b, `<polyline fill="none" stroke="%s" stroke-width="%d" points="%s"/>`+"\n",
+			color, curveWidth, strings.Join(coords, " "))
+		legendX := plotWidth - plotMargin - legendWidth
+		legendY := plotMargin + legendOffset + idx*legendStep
+		fmt.Fprintf(&b, `<rect x="%d" y="%d" width="%d" height="%d" fill="%s"/>`+"\n",
+			legendX, legendY-swatchSize, swatchSize, swatchSize, color)
+		fmt.Fprintf(&b, `<text x="%d" y="%d" font-family="Verdana" font-size="12" fill="#222222">%s</text>`+"\n",
+			legendX+legendOffset, legendY, html.EscapeString(c.label))
+	}
+	fmt.Fprintln(&b, `</svg>`)
+	_, err := io.WriteString(w, b.String())
+
+	return err
+}
+
+func writeSVGTicks(b *strings.Builder, maxWindow time.Duration) {
+	innerW := float64(plotWidth - 2*plotMargin)
+	innerH := float64(plotHeight - 2*plotMargin)
+	for i := 0; i <= xTicks; i++ {
+		x := plotMargin + innerW*float64(i)/xTicks
+		y := float64(plotHeight - plotMargin)
+		label := int64(maxWindow.Seconds() * float64(i) / xTicks)
+		fmt.Fprintf(b, `<line x1="%.2f" y1="%.2f" x2="%.2f" y2="%.2f" stroke="#222222" stroke-width="1"/>`+"\n", x, y, x, y+tickSize)
+		fmt.Fprintf(b, `<text x="%.2f" y="%.2f" font-family="Verdana" font-size="12" fill="#222222" text-anchor="middle">%d</text>`+"\n",
+			x, y+tickSize+tickLabelGap, label)
+	}
+	for i := 0; i <= yTicks; i++ {
+		y := plotMargin + innerH*float64(i)/yTicks
+		x := float64(plotMargin)
+		label := 1.0 - float64(i)/yTicks
+		fmt.Fprintf(b, `<line x1="%.2f" y1="%.2f" x2="%.2f" y2="%.2f" stroke="#222222" stroke-width="1"/>`+"\n", x, y, x-tickSize, y)
+		fmt.Fprintf(b, `<text x="%.2f" y="%.2f" font-family="Verdana" font-size="12" fill="#222222" `+
+			`text-anchor="end" dominant-baseline="middle">%.2f</text>`+"\n", x-tickSize-labelGap, y, label)
+	}
+}
+
+// renderPNG draws the axes and curves without text, as the standard library
+// has no font rendering; use SVG for a labeled plot.
+func renderPNG(w io.Writer, curves []curve, maxWindow time.Duration) error {
+	img := image.NewRGBA(image.Rect(0, 0, plotWidth, plotHeight))
+	fillRect(img, img.Bounds(), parseHexColor("#f7f4ef"))
+	fillRect(img, image.Rect(plotMargin, plotMargin, plotWidth-plotMargin, plotHeight-plotMargin), color.White)
+	axis := parseHexColor("#222222")
+	drawLine(img, plotMargin, plotHeight-plotMargin, plotWidth-plotMargin, plotHeight-plotMargin, axis, 1)
+	drawLine(img, plotMargin, plotMargin, plotMargin, plotHeight-plotMargin, axis, 1)
+
+	for idx, c := range curves {
+		stroke := parseHexColor(curveColors[idx%len(curveColors)])
+		for i := 1; i < len(c.points); i++ {
+			x0, y0 := plotPosition(c.points[i-1], maxWindow)
+			x1, y1 := plotPosition(c.points[i], maxWindow)
+			drawLine(img, int(x0), int(y0), int(x1), int(y1), stroke, curveWidth)
+		}
+	}
+
+	return png.Encode(w, img)
+}
+
+func fillRect(img *image.RGBA, rect image.Rectangle, c color.Color) {
+	for y := rect.Min.Y; y < rect.Max.Y; y++ {
+		for x := rect.Min.X; x < rect.Max.X; x++ {
+			img.Set(x, y, c)
+		}
+	}
+}
+
+// drawLine draws a line of the given width with Bresenham's algorithm.
+func drawLine(img *image.RGBA, x0, y0, x1, y1 int, c color.Color, width int) {
+	dx, dy := abs(x1-x0), -abs(y1-y0)
+	sx, sy := sign(x1-x0), sign(y1-y0)
+	e := dx + dy
+	for {
+		fillRect(img, image.Rect(x0-width/2, y0-width/2, x0-width/2+width, y0-width/2+width), c)
+		if x0 == x1 && y0 == y1 {
+			return
+		}
+		e2 := e + e
+		if e2 >= dy {
+			e += dy
+			x0 += sx
+		}
+		if e2 <= dx {
+			e += dx
+			y0 += sy
+		}
+	}
+}
+
+func abs(v int) int {
+	return max(v, -v)
+}
+
+func sign(v int) int {
+	switch {
+	case v > 0:
+		return 1
+	case v < 0:
+		return -1
+	default:
+		return 0
+	}
+}
+
+func parseHexColor(hex string) color.RGBA {
+	var c color.RGBA
+	c.A = 0xff
+	fmt.Sscanf(hex, "#%02x%02x%02x", &c.R, &c.G, &c.B)
+
+	return c
+}
+
+func renderCSV(w io.Writer, curves []curve) error {
+	cw := csv.NewWriter(w)
+	header := make([]string, 0, len(curves)+1)
+	header = append(header, "remain_seconds")
+	for _, c := range curves {
+		header = append(header, c.label)
+	}
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+	for i, p := range curves[0].points {
+		row := []string{strconv.FormatFloat(p.remaining.Seconds(), 'f', -1, 64)}
+		for _, c := range curves {
+			row = append(row, strconv.FormatFloat(c.points[i].probability, 'f', 6, 64))
+		}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+
+	return cw.Error()
+}
+
+// renderASCII draws the curves as a terminal chart, one glyph per curve.
+func renderASCII(w io.Writer, curves []curve, maxWindow time.Duration) error {
+	grid := make([][]byte, asciiHeight)
+	for row := range grid {
+		grid[row] = []byte(strings.Repeat(" ", asciiWidth))
+	}
+	for idx, c := range curves {
+		glyph := curveGlyphs[idx%len(curveGlyphs)]
+		for col := range asciiWidth {
+			remaining := time.Duration(float64(maxWindow) * float64(col) / float64(asciiWidth-1))
+			p := probabilityAt(c, remaining)
+			row := asciiHeight - 1 - int(math.Round(p*float64(asciiHeight-1)))
+			grid[row][col] = glyph
+		}
+	}
+
+	var b strings.Builder
+	for row, line := range grid {
+		label := "    "
+		if row == 0 || row == asciiHeight-1 {
+			label = fmt.Sprintf("%.1f ", 1-float64(row)/float64(asciiHeight-1))
+		}
+		fmt.Fprintf(&b, "%s|%s\n", label, line)
+	}
+	fmt.Fprintf(&b, "    +%s\n", strings.Repeat("-", asciiWidth))
+	fmt.Fprintf(&b, "     0%*s\n", asciiWidth-1, fmt.Sprintf("%gs remaining", maxWindow.Seconds()))
+	for idx, c := range curves {
+		fmt.Fprintf(&b, "     %c %s\n", curveGlyphs[idx%len(curveGlyphs)], c.label)
+	}
+	_, err := io.WriteString(w, b.String())
+
+	return err
+}
+
+// probabilityAt returns the probability of the sample closest to remaining.
+func probabilityAt(c curve, remaining time.Duration) float64 {
+	closest := c.points[0]
+	for _, p := range c.points[1:] {
+		if abs(int(p.remaining-remaining)) < abs(int(closest.remaining-remaining)) {
+			closest = p
+		}
+	}
+
+	return closest.probability
+}
@@ -3,9 +3,11 @@ package crema
 import (
 	"bytes"
 	"compress/zlib"
+	"encoding/binary"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"hash/crc32"
 	"sync"
 )
 
@@ -216,3 +218,90 @@ func decompressZlib(buf *bytes.Buffer, data []byte) error {
 
 	return nil
 }
+
+const (
+	// ChecksumTypeIDCRC32C marks payloads protected by a CRC-32C (Castagnoli) checksum.
+	// It is distinct from the CompressionTypeID values so the first stored byte
+	// tells a checksummed entry from a BinaryCompressionCodec one.
+	ChecksumTypeIDCRC32C byte = 0xC1
+
+	checksumCRC32CHeaderBytes = 1 + crc32.Size
+)
+
+var (
+	// ErrChecksumMismatch is returned when stored data does not match its checksum,
+	// typically because the value was truncated or corrupted.
+	ErrChecksumMismatch = errors.New("checksum mismatch")
+	// ErrUnsupportedChecksumTypeID is returned when the checksum type ID is unknown.
+	ErrUnsupportedChecksumTypeID = errors.New("unsupported checksum type ID")
+)
+
+var crc32cTable = crc32.MakeTable(crc32.Castagnoli)
+
+type checksumCodec[V any] struct {
+	inner CacheStorageCodec[V, []byte]
+}
+
+var (
+	_ CacheStorageCodec[any, []byte] = checksumCodec[any]{}
+	_ BufferReleasePolicy            = checksumCodec[any]{}
+)
+
+// NewChecksumCodec returns a codec that prefixes encoded values with a
+// CRC-32C checksum and verifies it on decode.
+// Decode returns an error wrapping ErrChecksumMismatch when verification fails.
+// Wrap it outermost (e.g. around BinaryCompressionCodec) so the checksum covers
+// the bytes actually stored by the provider. The stored layout is then
+//
+//	ChecksumTypeIDCRC32C | CRC-32C (4 bytes, big endian) | CompressionTypeID | payload
+//
+// where the checksum covers everything after itself, including the
+// compression type ID.
+func NewChecksumCodec[V any](inner CacheStorageCodec[V, []byte]) CacheStorageCodec[V, []byte] {
+	return checksumCodec[V]{inner: inner}
+}
+
+func (c checksumCodec[V]) Encode(value CacheObject[V]) ([]byte, error) {
+	innerBuf, err := c.inner.Encode(value)
+	if err != nil {
+		return nil, err
+	}
+
+	buf := make([]byte, checksumCRC32CHeaderBytes+len(innerBuf))
+	buf[0] = ChecksumTypeIDCRC32C
+	binary.BigEndian.PutUint32(buf[1:checksumCRC32CHeaderBytes], crc32.Checksum(innerBuf, crc32cTable))
+	copy(buf[checksumCRC32CHeaderBytes:], innerBuf)
+
+	return buf, nil
+}
+
+func (c checksumCodec[V]) Decode(data []byte) (CacheObject[V], error) {
+	if len(data) == 0 {
+		return CacheObject[V]{}, fmt.Errorf("%w: empty data", ErrChecksumMismatch)
+	}
+	switch data[0] {
+	case ChecksumTypeIDCRC32C:
+		if len(data) < checksumCRC32CHeaderBytes {
+			return CacheObject[V]{}, fmt.Errorf("%w: truncated header", ErrChecksumMismatch)
+		}
+		payload := data[checksumCRC32CHeaderBytes:]
+		expected := binary.BigEndian.Uint32(data[1:checksumCRC32CHeaderBytes])
+		if actual := crc32.Checksum(payload, crc32cTable); actual != expected {
+			return CacheObject[V]{}, fmt.Errorf("%w: expected %08x, got %08x", ErrChecksumMismatch, expected, actual)
+		}
+
+		return c.inner.Decode(payload)
+	default:
+		return CacheObject[V]{}, fmt.Errorf("%w: %d", ErrUnsupportedChecksumTypeID, data[0])
+	}
+}
+
+// CanReleaseBufferOnDecode delegates to the inner codec, since Decode passes
+// a sub-slice of its input through without copying.
+func (c checksumCodec[V]) CanReleaseBufferOnDecode() bool {
+	if policy, ok := any(c.inner).(BufferReleasePolicy); ok {
+		return policy.CanReleaseBufferOnDecode()
+	}
+
+	return false
+}
@@ -14,8 +14,10 @@ func TestJSONByteStringCodec_RoundTrip(t *testing.T) {
 
 	codec := JSONByteStringCodec[int]{}
 	input := &CacheObject[int]{
-		Value:          10,
-		ExpireAtMillis: 1234,
+		Value:              10,
+		ExpireAtMillis:     1234,
+		LoadDurationMillis: 56,
+		TTLMillis:          7890,
 	}
 	encoded, err := codec.Encode(*input)
 	if err != nil {
@@ -34,6 +36,19 @@ func TestJSONByteStringCodec_RoundTrip(t *testing.T) {
 	}
 }
 
+func TestJSONByteStringCodec_OmitsUnknownMetadata(t *testing.T) {
+	t.Parallel()
+
+	codec := JSONByteStringCodec[int]{}
+	encoded, err := codec.Encode(CacheObject[int]{Value: 10, ExpireAtMillis: 1234})
+	if err != nil {
+		t.Fatalf("expected encode to succeed, got %v", err)
+	}
+	if want := `{"Value":10,"ExpireAtMillis":1234}`; string(encoded) != want {
+		t.Fatalf("expected %s, got %s", want, encoded)
+	}
+}
+
 func TestJSONByteStringCodec_DecodeError(t *testing.T) {
 	t.Parallel()
 
@@ -118,6 +133,30 @@ func TestBinaryCompressionCodec_RoundTripCompressed(t *testing.T) {
 	}
 }
 
+func TestBinaryCompressionCodec_PreservesEntryMetadata(t *testing.T) {
+	t.Parallel()
+
+	codec := NewBinaryCompressionCodec(JSONByteStringCodec[string]{}, 0)
+	input := CacheObject[string]{
+		Value:              "hello",
+		ExpireAtMillis:     1234,
+		LoadDurationMillis: 56,
+		TTLMillis:          7890,
+	}
+	encoded, err := codec.Encode(input)
+	if err != nil {
+		t.Fatalf("expected encode to succeed, got %v", err)
+	}
+
+	decoded, err := codec.Decode(encoded)
+	if err != nil {
+		t.Fatalf("expected decode to succeed, got %v", err)
+	}
+	if decoded != input {
+		t.Fatalf("expected decoded value %+v, got %+v", input, decoded)
+	}
+}
+
 func TestBinaryCompressionCodec_RoundTripUncompressedUnderThreshold(t *testing.T) {
 	t.Parallel()
 
@@ -283,3 +322,103 @@ func TestBinaryCompressionCodec_CanReleaseBufferOnDecodeTrueUsesBuffer(t *testin
 		t.Fatal("expected decode to pass pooled buffer to inner codec")
 	}
 }
+
+func TestChecksumCodec_RoundTrip(t *testing.T) {
+	t.Parallel()
+
+	codec := NewChecksumCodec(binaryCompressionTestCodec{})
+	input := CacheObject[string]{
+		Value:          "hello",
+		ExpireAtMillis: 1234,
+	}
+	encoded, err := codec.Encode(input)
+	if err != nil {
+		t.Fatalf("expected encode to succeed, got %v", err)
+	}
+	if encoded[0] != ChecksumTypeIDCRC32C {
+		t.Fatalf("expected crc32c checksum prefix, got %v", encoded[0])
+	}
+
+	decoded, err := codec.Decode(encoded)
+	if err != nil {
+		t.Fatalf("expected decode to succeed, got %v", err)
+	}
+	if decoded != input {
+		t.Fatalf("expected decoded value %+v, got %+v", input, decoded)
+	}
+}
+
+func TestChecksumCodec_DetectsCorruption(t *testing.T) {
+	t.Parallel()
+
+	codec := NewChecksumCodec(binaryCompressionTestCodec{})
+	encoded, err := codec.Encode(CacheObject[string]{Value: "hello", ExpireAtMillis: 1234})
+	if err != nil {
+		t.Fatalf("expected encode to succeed, got %v", err)
+	}
+
+	flipped := append([]byte(nil), encoded...)
+	flipped[len(flipped)-1] ^= 0xff
+	tests := map[string][]byte{
+		"empty":            nil,
+		"truncated header": encoded[:3],
+		"truncated value":  encoded[:len(encoded)-2],
+		"flipped byte":     flipped,
+	}
+	for name, data := range tests {
+		if _, err := codec.Decode(data); !errors.Is(err, ErrChecksumMismatch) {
+			t.Fatalf("%s: expected checksum mismatch, got %v", name, err)
+		}
+	}
+}
+
+func TestChecksumCodec_UnsupportedChecksumType(t *testing.T) {
+	t.Parallel()
+
+	codec := NewChecksumCodec(binaryCompressionTestCodec{})
+	if _, err := codec.Decode([]byte{0xff, 0x00}); !errors.Is(err, ErrUnsupportedChecksumTypeID) {
+		t.Fatalf("expected unsupported checksum type error, got %v", err)
+	}
+}
+
+func TestChecksumCodec_WrapsCompression(t *testing.T) {
+	t.Parallel()
+
+	codec := NewChecksumCodec(NewBinaryCompressionCodec(binaryCompressionTestCodec{}, 0))
+	input := CacheObject[string]{
+		Value:          strings.Repeat("a", 64),
+		ExpireAtMillis: 1234,
+	}
+	encoded, err := codec.Encode(input)
+	if err != nil {
+		t.Fatalf("expected encode to succeed, got %v", err)
+	}
+	if encoded[checksumCRC32CHeaderBytes] != CompressionTypeIDZlib {
+		t.Fatalf("expected zlib payload after checksum header, got %v", encoded[checksumCRC32CHeaderBytes])
+	}
+	if _, err := NewBinaryCompressionCodec(binaryCompressionTestCodec{}, 0).Decode(encoded); err == nil {
+		t.Fatal("expected compression codec to reject checksummed data")
+	}
+
+	decoded, err := codec.Decode(encoded)
+	if err != nil {
+		t.Fatalf("expected decode to succeed, got %v", err)
+	}
+	if decoded != input {
+		t.Fatalf("expected decoded value %+v, got %+v", input, decoded)
+	}
+}
+
+func TestChecksumCodec_CanReleaseBufferOnDecode(t *testing.T) {
+	t.Parallel()
+
+	codec := NewChecksumCodec(binaryCompressionTestCodec{})
+	if codec.(BufferReleasePolicy).CanReleaseBufferOnDecode() {
+		t.Fatal("expected CanReleaseBufferOnDecode to be false by default")
+	}
+
+	withPolicy := NewChecksumCodec(bufferReleasePolicyCodec{canRelease: true})
+	if !withPolicy.(BufferReleasePolicy).CanReleaseBufferOnDecode() {
+		t.Fatal("expected CanReleaseBufferOnDecode to follow inner policy")
+	}
+}
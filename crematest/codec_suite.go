@@ -0,0 +1,174 @@
+package crematest
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"testing"
+
+	"github.com/abema/crema"
+)
+
+// CodecSuite configures TestCodec.
+type CodecSuite[V any, S any] struct {
+	// Codec is the codec under test. Required.
+	Codec crema.CacheStorageCodec[V, S]
+	// Values are the values to round-trip. Required.
+	Values []V
+	// Equal reports whether two values are equal. It defaults to bytes.Equal
+	// for []byte values and reflect.DeepEqual otherwise.
+	Equal func(a, b V) bool
+	// Invalid are stored values Decode must reject with an error.
+	Invalid []S
+	// SkipMetadata skips checking that CacheObject.LoadDurationMillis and
+	// CacheObject.TTLMillis survive a round trip, for codecs not storing them.
+	SkipMetadata bool
+}
+
+// TestCodec checks that a crema.CacheStorageCodec round-trips values and
+// entry metadata, rejects invalid data with errors rather than panics, is
+// safe for concurrent use under -race and, when it declares
+// crema.BufferReleasePolicy, does not retain the decoded buffer.
+func TestCodec[V any, S any](t *testing.T, suite CodecSuite[V, S]) {
+	t.Helper()
+
+	if suite.Codec == nil || len(suite.Values) == 0 {
+		t.Fatal("crematest: CodecSuite.Codec and CodecSuite.Values are required")
+	}
+	if suite.Equal == nil {
+		suite.Equal = defaultEqual[V]
+	}
+
+	t.Run("RoundTrip", suite.testRoundTrip)
+	t.Run("Metadata", suite.testMetadata)
+	t.Run("Invalid", suite.testInvalid)
+	t.Run("Concurrent", suite.testConcurrent)
+	t.Run("BufferRelease", suite.testBufferRelease)
+}
+
+func (s *CodecSuite[V, S]) roundTrip(in crema.CacheObject[V]) (crema.CacheObject[V], error) {
+	encoded, err := s.Codec.Encode(in)
+	if err != nil {
+		return crema.CacheObject[V]{}, fmt.Errorf("Encode() error = %w", err)
+	}
+	out, err := s.Codec.Decode(encoded)
+	if err != nil {
+		return crema.CacheObject[V]{}, fmt.Errorf("Decode() error = %w", err)
+	}
+	if !s.Equal(out.Value, in.Value) {
+		return out, fmt.Errorf("decoded value = %v, want %v", out.Value, in.Value)
+	}
+	if out.ExpireAtMillis != in.ExpireAtMillis {
+		return out, fmt.Errorf("decoded ExpireAtMillis = %d, want %d", out.ExpireAtMillis, in.ExpireAtMillis)
+	}
+
+	return out, nil
+}
+
+func (s *CodecSuite[V, S]) testRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	for _, expireAtMillis := range []int64{0, 1, 1700000000000, math.MaxInt64} {
+		for i, value := range s.Values {
+			if _, err := s.roundTrip(crema.CacheObject[V]{Value: value, ExpireAtMillis: expireAtMillis}); err != nil {
+				t.Fatalf("value %d expiring at %d: %v", i, expireAtMillis, err)
+			}
+		}
+	}
+}
+
+func (s *CodecSuite[V, S]) testMetadata(t *testing.T) {
+	t.Parallel()
+	if s.SkipMetadata {
+		t.Skip("codec does not store entry metadata")
+	}
+
+	in := crema.CacheObject[V]{
+		Value:              s.Values[0],
+		ExpireAtMillis:     suiteExpireAtMillis,
+		LoadDurationMillis: suiteLoadMillis,
+		TTLMillis:          suiteTTLMillis,
+	}
+	out, err := s.roundTrip(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out.LoadDurationMillis != in.LoadDurationMillis || out.TTLMillis != in.TTLMillis {
+		t.Fatalf("decoded LoadDurationMillis, TTLMillis = %d, %d, want %d, %d",
+			out.LoadDurationMillis, out.TTLMillis, in.LoadDurationMillis, in.TTLMillis)
+	}
+}
+
+func (s *CodecSuite[V, S]) testInvalid(t *testing.T) {
+	t.Parallel()
+	if len(s.Invalid) == 0 {
+		t.Skip("CodecSuite.Invalid is not set")
+	}
+
+	for i, data := range s.Invalid {
+		func() {
+			defer func() {
+				if r := recover(); r != nil {
+					t.Fatalf("Decode() of invalid data %d panicked: %v", i, r)
+				}
+			}()
+			if _, err := s.Codec.Decode(data); err == nil {
+				t.Fatalf("Decode() of invalid data %d error = nil, want an error", i)
+			}
+		}()
+	}
+}
+
+func (s *CodecSuite[V, S]) testConcurrent(t *testing.T) {
+	t.Parallel()
+
+	var wg sync.WaitGroup
+	errs := make(chan error, suiteConcurrency)
+	for worker := range suiteConcurrency {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range suiteConcurrentOps {
+				value := s.Values[(worker+i)%len(s.Values)]
+				if _, err := s.roundTrip(crema.CacheObject[V]{Value: value, ExpireAtMillis: int64(i)}); err != nil {
+					errs <- err
+
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Fatal(err)
+	}
+}
+
+func (s *CodecSuite[V, S]) testBufferRelease(t *testing.T) {
+	t.Parallel()
+	policy, ok := s.Codec.(crema.BufferReleasePolicy)
+	if !ok || !policy.CanReleaseBufferOnDecode() {
+		t.Skip("codec does not allow releasing the buffer on decode")
+	}
+
+	for i, value := range s.Values {
+		in := crema.CacheObject[V]{Value: value, ExpireAtMillis: suiteExpireAtMillis}
+		encoded, err := s.Codec.Encode(in)
+		if err != nil {
+			t.Fatalf("Encode() error = %v", err)
+		}
+		out, err := s.Codec.Decode(encoded)
+		if err != nil {
+			t.Fatalf("Decode() error = %v", err)
+		}
+		raw, ok := any(encoded).([]byte)
+		if !ok {
+			t.Skip("stored values are not []byte")
+		}
+		clear(raw)
+		if !s.Equal(out.Value, value) {
+			t.Fatalf("value %d changed after its buffer was released: got %v, want %v", i, out.Value, value)
+		}
+	}
+}
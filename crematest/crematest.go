@@ -0,0 +1,93 @@
+// Package crematest provides test doubles for deterministic tests of code
+// built on crema: a fake clock, a scripted random source and an in-memory
+// provider recording its calls.
+//
+// Pass them to a cache with crema.WithClock, crema.WithRandomSource and
+// crema.NewCache to control expiry and revalidation draws.
+package crematest
+
+import (
+	"sync"
+	"time"
+)
+
+// FakeClock is a manually advanced clock. It is safe for concurrent use.
+type FakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+// NewFakeClock returns a clock stopped at start.
+func NewFakeClock(start time.Time) *FakeClock {
+	return &FakeClock{now: start}
+}
+
+// Now returns the current time of the clock. Pass it to crema.WithClock.
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.now
+}
+
+// Advance moves the clock forward by d.
+func (c *FakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = c.now.Add(d)
+}
+
+// Set moves the clock to now.
+func (c *FakeClock) Set(now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = now
+}
+
+// ScriptedRandom returns scripted values in order. Once exhausted it keeps
+// returning the last value, or 0 when none was scripted. It is safe for
+// concurrent use.
+type ScriptedRandom struct {
+	mu     sync.Mutex
+	values []float64
+	last   float64
+	draws  int
+}
+
+// NewScriptedRandom returns a random source drawing values in order.
+func NewScriptedRandom(values ...float64) *ScriptedRandom {
+	return &ScriptedRandom{values: values}
+}
+
+// Float64 returns the next scripted value. Pass it to crema.WithRandomSource.
+// A draw of 0 always revalidates an entry in its window and a draw of 1 never does.
+func (r *ScriptedRandom) Float64() float64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.draws++
+	if len(r.values) > 0 {
+		r.last = r.values[0]
+		r.values = r.values[1:]
+	}
+
+	return r.last
+}
+
+// Push appends values to the script.
+func (r *ScriptedRandom) Push(values ...float64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.values = append(r.values, values...)
+}
+
+// Draws returns the number of values drawn so far.
+func (r *ScriptedRandom) Draws() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.draws
+}
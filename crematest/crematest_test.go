@@ -0,0 +1,41 @@
+package crematest
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFakeClock(t *testing.T) {
+	t.Parallel()
+
+	clock := NewFakeClock(time.UnixMilli(1000))
+	clock.Advance(time.Second)
+	if got := clock.Now(); !got.Equal(time.UnixMilli(2000)) {
+		t.Fatalf("expected advanced time, got %v", got)
+	}
+	clock.Set(time.UnixMilli(500))
+	if got := clock.Now(); !got.Equal(time.UnixMilli(500)) {
+		t.Fatalf("expected set time, got %v", got)
+	}
+}
+
+func TestScriptedRandom(t *testing.T) {
+	t.Parallel()
+
+	random := NewScriptedRandom(0.1, 0.2)
+	for _, want := range []float64{0.1, 0.2, 0.2} {
+		if got := random.Float64(); got != want {
+			t.Fatalf("expected %f, got %f", want, got)
+		}
+	}
+	random.Push(0.3)
+	if got := random.Float64(); got != 0.3 {
+		t.Fatalf("expected pushed value, got %f", got)
+	}
+	if got := random.Draws(); got != 4 {
+		t.Fatalf("expected 4 draws, got %d", got)
+	}
+	if got := NewScriptedRandom().Float64(); got != 0 {
+		t.Fatalf("expected 0 from an empty script, got %f", got)
+	}
+}
@@ -0,0 +1,43 @@
+package crematest_test
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/abema/crema"
+	"github.com/abema/crema/crematest"
+)
+
+func Example() {
+	ctx := context.Background()
+	clock := crematest.NewFakeClock(time.UnixMilli(0))
+	random := crematest.NewScriptedRandom(1, 0)
+	provider := crematest.NewRecordingProvider[crema.CacheObject[int]](clock.Now)
+	cache := crema.NewCache(
+		provider,
+		crema.NoopCacheStorageCodec[int]{},
+		crema.WithClock[int, crema.CacheObject[int]](clock.Now),
+		crema.WithRandomSource[int, crema.CacheObject[int]](random.Float64),
+		crema.WithRevalidationWindow[int, crema.CacheObject[int]](10*time.Second),
+	).(crema.CacheResultLoader[int])
+	load := func(value int) crema.CacheLoadFunc[int] {
+		return func(context.Context) (int, error) { return value, nil }
+	}
+
+	first, _ := cache.GetOrLoadResult(ctx, "key", time.Minute, load(1))
+	clock.Advance(55 * time.Second)
+	// Within the window, a draw of 1 keeps the cached value and a draw of 0 revalidates.
+	kept, _ := cache.GetOrLoadResult(ctx, "key", time.Minute, load(2))
+	revalidated, _ := cache.GetOrLoadResult(ctx, "key", time.Minute, load(3))
+
+	fmt.Println(first.Source, first.Value)
+	fmt.Println(kept.Source, kept.Value)
+	fmt.Println(revalidated.Source, revalidated.Value)
+	fmt.Println(len(provider.CallsOf(crematest.OpSet)), "sets")
+	// Output:
+	// miss 1
+	// hit 1
+	// revalidate 3
+	// 2 sets
+}
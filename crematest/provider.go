@@ -0,0 +1,158 @@
+package crematest
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/abema/crema"
+)
+
+// Op identifies a provider operation.
+type Op string
+
+const (
+	// OpGet is a CacheProvider.Get call.
+	OpGet Op = "get"
+	// OpSet is a CacheProvider.Set call.
+	OpSet Op = "set"
+	// OpDelete is a CacheProvider.Delete call.
+	OpDelete Op = "delete"
+)
+
+// Call is a provider call recorded by RecordingProvider.
+type Call[S any] struct {
+	// Op is the called operation.
+	Op Op
+	// Key is the key passed to the call.
+	Key string
+	// Value is the value passed to Set, or returned by a successful Get.
+	Value S
+	// TTL is the ttl passed to Set.
+	TTL time.Duration
+	// Found reports whether Get found the key.
+	Found bool
+	// Err is the error returned by the call.
+	Err error
+}
+
+type recordedItem[S any] struct {
+	value    S
+	expireAt time.Time
+}
+
+// RecordingProvider is an in-memory crema.CacheProvider recording every call.
+// Entries expire after their TTL according to the provider's clock. It is safe
+// for concurrent use.
+type RecordingProvider[S any] struct {
+	now func() time.Time
+
+	mu     sync.Mutex
+	items  map[string]recordedItem[S]
+	calls  []Call[S]
+	errors map[Op]error
+}
+
+var _ crema.CacheProvider[[]byte] = (*RecordingProvider[[]byte])(nil)
+
+// NewRecordingProvider returns an empty provider expiring entries by now,
+// typically FakeClock.Now. A nil now uses time.Now.
+func NewRecordingProvider[S any](now func() time.Time) *RecordingProvider[S] {
+	if now == nil {
+		now = time.Now
+	}
+
+	return &RecordingProvider[S]{
+		now:    now,
+		items:  make(map[string]recordedItem[S]),
+		errors: make(map[Op]error),
+	}
+}
+
+// Get implements crema.CacheProvider.
+func (p *RecordingProvider[S]) Get(_ context.Context, key string) (S, bool, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	call := Call[S]{Op: OpGet, Key: key, Err: p.errors[OpGet]}
+	if call.Err == nil {
+		if item, ok := p.items[key]; ok && p.now().Before(item.expireAt) {
+			call.Value, call.Found = item.value, true
+		}
+	}
+	p.calls = append(p.calls, call)
+
+	return call.Value, call.Found, call.Err
+}
+
+// Set implements crema.CacheProvider.
+func (p *RecordingProvider[S]) Set(_ context.Context, key string, value S, ttl time.Duration) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	call := Call[S]{Op: OpSet, Key: key, Value: value, TTL: ttl, Err: p.errors[OpSet]}
+	if call.Err == nil {
+		p.items[key] = recordedItem[S]{value: value, expireAt: p.now().Add(ttl)}
+	}
+	p.calls = append(p.calls, call)
+
+	return call.Err
+}
+
+// Delete implements crema.CacheProvider.
+func (p *RecordingProvider[S]) Delete(_ context.Context, key string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	call := Call[S]{Op: OpDelete, Key: key, Err: p.errors[OpDelete]}
+	if call.Err == nil {
+		delete(p.items, key)
+	}
+	p.calls = append(p.calls, call)
+
+	return call.Err
+}
+
+// FailWith makes calls of op return err until cleared with a nil err.
+func (p *RecordingProvider[S]) FailWith(op Op, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if err == nil {
+		delete(p.errors, op)
+
+		return
+	}
+	p.errors[op] = err
+}
+
+// Calls returns the calls recorded so far, oldest first.
+func (p *RecordingProvider[S]) Calls() []Call[S] {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return append([]Call[S](nil), p.calls...)
+}
+
+// CallsOf returns the recorded calls of op, oldest first.
+func (p *RecordingProvider[S]) CallsOf(op Op) []Call[S] {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var calls []Call[S]
+	for _, call := range p.calls {
+		if call.Op == op {
+			calls = append(calls, call)
+		}
+	}
+
+	return calls
+}
+
+// ResetCalls forgets the recorded calls, keeping the stored entries.
+func (p *RecordingProvider[S]) ResetCalls() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.calls = nil
+}
@@ -0,0 +1,350 @@
+package crematest
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/abema/crema"
+)
+
+const (
+	defaultSuiteTTL            = 50 * time.Millisecond
+	defaultSuiteLargeValueSize = 512 << 10
+	suiteConcurrency           = 8
+	suiteConcurrentOps         = 100
+	suiteConcurrentKeys        = 4
+	maxSuiteByte               = 256
+	maxSuiteKeyLength          = 250
+	// suiteExpireWaitFactor is how many ttls the default Expire sleeps.
+	suiteExpireWaitFactor = 2
+	suiteExpireAtMillis   = 1234
+	suiteLoadMillis       = 56
+	suiteTTLMillis        = 7890
+)
+
+// ProviderSuite configures TestProvider.
+type ProviderSuite[S any] struct {
+	// NewProvider returns an empty provider for a subtest. Required.
+	NewProvider func(t *testing.T) crema.CacheProvider[S]
+	// Value converts raw bytes into a stored value. Required unless S is
+	// []byte or string.
+	Value func(data []byte) S
+	// Equal reports whether two stored values are equal. It defaults to
+	// bytes.Equal for []byte values and reflect.DeepEqual otherwise.
+	Equal func(a, b S) bool
+	// TTL is the ttl entries are set with when checking expiry. It defaults
+	// to 50ms; use at least a second for providers with second granularity.
+	TTL time.Duration
+	// Expire lets entries of provider set with ttl expire, e.g. by
+	// fast-forwarding a fake server. It defaults to sleeping twice ttl.
+	Expire func(t *testing.T, provider crema.CacheProvider[S], ttl time.Duration)
+	// SkipTTL skips expiry checks, for providers ignoring per-entry ttls.
+	SkipTTL bool
+	// Break makes provider fail subsequent calls, e.g. by closing its server,
+	// to check that failures are errors rather than misses. The check is
+	// skipped when nil.
+	Break func(t *testing.T, provider crema.CacheProvider[S])
+	// Keys are the keys checked as edge cases. They default to keys with
+	// separators, non-ASCII characters and a 250 byte key, which every
+	// official provider accepts.
+	Keys []string
+	// LargeValueSize is the size in bytes of the large value checked.
+	// It defaults to 512 KiB.
+	LargeValueSize int
+}
+
+// TestProvider checks that a crema.CacheProvider behaves like the official
+// providers: get/set/delete semantics, misses are not errors, TTLs are
+// honored, values are binary-safe, large values and edge case keys work, and
+// concurrent use is safe under -race.
+func TestProvider[S any](t *testing.T, suite ProviderSuite[S]) {
+	t.Helper()
+
+	if suite.NewProvider == nil {
+		t.Fatal("crematest: ProviderSuite.NewProvider is required")
+	}
+	suite.setDefaults()
+
+	t.Run("GetSetDelete", suite.testGetSetDelete)
+	t.Run("Overwrite", suite.testOverwrite)
+	t.Run("MissIsNotError", suite.testMissIsNotError)
+	t.Run("DeleteMissing", suite.testDeleteMissing)
+	t.Run("TTL", suite.testTTL)
+	t.Run("BinarySafe", suite.testBinarySafe)
+	t.Run("LargeValue", suite.testLargeValue)
+	t.Run("Keys", suite.testKeys)
+	t.Run("Concurrent", suite.testConcurrent)
+	t.Run("ErrorIsNotMiss", suite.testErrorIsNotMiss)
+}
+
+func (s *ProviderSuite[S]) setDefaults() {
+	if s.Equal == nil {
+		s.Equal = defaultEqual[S]
+	}
+	if s.TTL <= 0 {
+		s.TTL = defaultSuiteTTL
+	}
+	if s.Expire == nil {
+		s.Expire = func(_ *testing.T, _ crema.CacheProvider[S], ttl time.Duration) {
+			time.Sleep(suiteExpireWaitFactor * ttl)
+		}
+	}
+	if s.Keys == nil {
+		s.Keys = []string{
+			"a",
+			"key:with:colons",
+			"key/with/slashes",
+			"key-with-ünïcödé-日本語",
+			strings.Repeat("k", maxSuiteKeyLength),
+		}
+	}
+	if s.LargeValueSize <= 0 {
+		s.LargeValueSize = defaultSuiteLargeValueSize
+	}
+}
+
+// defaultEqual compares []byte values with bytes.Equal, so that nil and empty
+// values are equal, and other values with reflect.DeepEqual.
+func defaultEqual[T any](a, b T) bool {
+	if raw, ok := any(a).([]byte); ok {
+		return bytes.Equal(raw, any(b).([]byte))
+	}
+
+	return reflect.DeepEqual(a, b)
+}
+
+func (s *ProviderSuite[S]) value(t *testing.T, data []byte) S {
+	t.Helper()
+
+	if s.Value != nil {
+		return s.Value(data)
+	}
+	var value S
+	switch v := any(&value).(type) {
+	case *[]byte:
+		*v = bytes.Clone(data)
+	case *string:
+		*v = string(data)
+	default:
+		t.Fatalf("crematest: ProviderSuite.Value is required for %T values", value)
+	}
+
+	return value
+}
+
+func (s *ProviderSuite[S]) mustSet(t *testing.T, provider crema.CacheProvider[S], key string, value S) {
+	t.Helper()
+
+	if err := provider.Set(context.Background(), key, value, time.Minute); err != nil {
+		t.Fatalf("Set(%q) error = %v", key, err)
+	}
+}
+
+func (s *ProviderSuite[S]) assertValue(t *testing.T, provider crema.CacheProvider[S], key string, want S) {
+	t.Helper()
+
+	got, found, err := provider.Get(context.Background(), key)
+	if err != nil {
+		t.Fatalf("Get(%q) error = %v", key, err)
+	}
+	if !found {
+		t.Fatalf("Get(%q) found = false, want true", key)
+	}
+	if !s.Equal(got, want) {
+		t.Fatalf("Get(%q) = %v, want %v", key, got, want)
+	}
+}
+
+func (s *ProviderSuite[S]) assertMissing(t *testing.T, provider crema.CacheProvider[S], key string) {
+	t.Helper()
+
+	_, found, err := provider.Get(context.Background(), key)
+	if err != nil {
+		t.Fatalf("Get(%q) error = %v, want a miss", key, err)
+	}
+	if found {
+		t.Fatalf("Get(%q) found = true, want false", key)
+	}
+}
+
+func (s *ProviderSuite[S]) testGetSetDelete(t *testing.T) {
+	t.Parallel()
+
+	provider := s.NewProvider(t)
+	value := s.value(t, []byte("value"))
+	s.mustSet(t, provider, "key", value)
+	s.mustSet(t, provider, "other", s.value(t, []byte("other")))
+	s.assertValue(t, provider, "key", value)
+
+	if err := provider.Delete(context.Background(), "key"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	s.assertMissing(t, provider, "key")
+	s.assertValue(t, provider, "other", s.value(t, []byte("other")))
+}
+
+func (s *ProviderSuite[S]) testOverwrite(t *testing.T) {
+	t.Parallel()
+
+	provider := s.NewProvider(t)
+	s.mustSet(t, provider, "key", s.value(t, []byte("first")))
+	s.mustSet(t, provider, "key", s.value(t, []byte("second")))
+	s.assertValue(t, provider, "key", s.value(t, []byte("second")))
+}
+
+func (s *ProviderSuite[S]) testMissIsNotError(t *testing.T) {
+	t.Parallel()
+
+	s.assertMissing(t, s.NewProvider(t), "missing")
+}
+
+func (s *ProviderSuite[S]) testDeleteMissing(t *testing.T) {
+	t.Parallel()
+
+	if err := s.NewProvider(t).Delete(context.Background(), "missing"); err != nil {
+		t.Fatalf("Delete() of a missing key error = %v, want nil", err)
+	}
+}
+
+func (s *ProviderSuite[S]) testTTL(t *testing.T) {
+	t.Parallel()
+	if s.SkipTTL {
+		t.Skip("provider ignores per-entry ttls")
+	}
+
+	provider := s.NewProvider(t)
+	value := s.value(t, []byte("value"))
+	if err := provider.Set(context.Background(), "key", value, s.TTL); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	s.assertValue(t, provider, "key", value)
+
+	s.Expire(t, provider, s.TTL)
+	s.assertMissing(t, provider, "key")
+}
+
+func (s *ProviderSuite[S]) testBinarySafe(t *testing.T) {
+	t.Parallel()
+
+	provider := s.NewProvider(t)
+	data := make([]byte, 0, maxSuiteByte)
+	for b := range maxSuiteByte {
+		data = append(data, byte(b))
+	}
+	value := s.value(t, data)
+	s.mustSet(t, provider, "binary", value)
+	s.assertValue(t, provider, "binary", value)
+
+	empty := s.value(t, []byte{})
+	s.mustSet(t, provider, "empty", empty)
+	s.assertValue(t, provider, "empty", empty)
+}
+
+func (s *ProviderSuite[S]) testLargeValue(t *testing.T) {
+	t.Parallel()
+
+	provider := s.NewProvider(t)
+	pattern := []byte("0123456789abcdef")
+	data := bytes.Repeat(pattern, s.LargeValueSize/len(pattern)+1)[:s.LargeValueSize]
+	value := s.value(t, data)
+	s.mustSet(t, provider, "large", value)
+	s.assertValue(t, provider, "large", value)
+}
+
+func (s *ProviderSuite[S]) testKeys(t *testing.T) {
+	t.Parallel()
+
+	provider := s.NewProvider(t)
+	for i, key := range s.Keys {
+		s.mustSet(t, provider, key, s.value(t, []byte(fmt.Sprint("value-", i))))
+	}
+	for i, key := range s.Keys {
+		s.assertValue(t, provider, key, s.value(t, []byte(fmt.Sprint("value-", i))))
+	}
+}
+
+func (s *ProviderSuite[S]) testConcurrent(t *testing.T) {
+	t.Parallel()
+
+	provider := s.NewProvider(t)
+	ctx := context.Background()
+	values := make([]S, suiteConcurrency)
+	for i := range values {
+		values[i] = s.value(t, []byte(fmt.Sprint("value-", i)))
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, suiteConcurrency)
+	for worker := range suiteConcurrency {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- s.runConcurrentOps(ctx, provider, worker, values)
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// runConcurrentOps mixes operations on keys shared with other workers and
+// checks that reads only return values some worker wrote.
+func (s *ProviderSuite[S]) runConcurrentOps(ctx context.Context, provider crema.CacheProvider[S], worker int, values []S) error {
+	for i := range suiteConcurrentOps {
+		key := fmt.Sprint("concurrent-", (worker+i)%suiteConcurrentKeys)
+		var err error
+		switch i % 3 {
+		case 0:
+			err = provider.Set(ctx, key, values[worker], time.Minute)
+		case 1:
+			var got S
+			var found bool
+			got, found, err = provider.Get(ctx, key)
+			if err == nil && found && !s.written(got, values) {
+				return fmt.Errorf("Get(%q) = %v, which no worker wrote", key, got)
+			}
+		default:
+			err = provider.Delete(ctx, key)
+		}
+		if err != nil {
+			return fmt.Errorf("concurrent operation on %q error = %w", key, err)
+		}
+	}
+
+	return nil
+}
+
+func (s *ProviderSuite[S]) written(got S, values []S) bool {
+	for _, value := range values {
+		if s.Equal(got, value) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (s *ProviderSuite[S]) testErrorIsNotMiss(t *testing.T) {
+	t.Parallel()
+	if s.Break == nil {
+		t.Skip("ProviderSuite.Break is not set")
+	}
+
+	provider := s.NewProvider(t)
+	s.mustSet(t, provider, "key", s.value(t, []byte("value")))
+	s.Break(t, provider)
+
+	if _, found, err := provider.Get(context.Background(), "key"); err == nil || found {
+		t.Fatalf("Get() on a broken provider = found %v, error %v, want an error", found, err)
+	}
+}
@@ -0,0 +1,79 @@
+package crematest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRecordingProvider_RecordsCalls(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	provider := NewRecordingProvider[string](nil)
+	if err := provider.Set(ctx, "key", "value", time.Minute); err != nil {
+		t.Fatalf("expected set to succeed, got %v", err)
+	}
+	if value, found, err := provider.Get(ctx, "key"); err != nil || !found || value != "value" {
+		t.Fatalf("expected stored value, got value=%q found=%v err=%v", value, found, err)
+	}
+	if err := provider.Delete(ctx, "key"); err != nil {
+		t.Fatalf("expected delete to succeed, got %v", err)
+	}
+	if _, found, _ := provider.Get(ctx, "key"); found {
+		t.Fatal("expected deleted key to be missing")
+	}
+
+	calls := provider.Calls()
+	ops := []Op{OpSet, OpGet, OpDelete, OpGet}
+	if len(calls) != len(ops) {
+		t.Fatalf("expected %d calls, got %+v", len(ops), calls)
+	}
+	for i, op := range ops {
+		if calls[i].Op != op || calls[i].Key != "key" {
+			t.Fatalf("call %d: expected %s key, got %+v", i, op, calls[i])
+		}
+	}
+	if set := provider.CallsOf(OpSet); len(set) != 1 || set[0].TTL != time.Minute {
+		t.Fatalf("expected one set with ttl, got %+v", set)
+	}
+	provider.ResetCalls()
+	if calls := provider.Calls(); len(calls) != 0 {
+		t.Fatalf("expected no calls after reset, got %+v", calls)
+	}
+}
+
+func TestRecordingProvider_ExpiresByClock(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	clock := NewFakeClock(time.UnixMilli(0))
+	provider := NewRecordingProvider[string](clock.Now)
+	if err := provider.Set(ctx, "key", "value", time.Second); err != nil {
+		t.Fatalf("expected set to succeed, got %v", err)
+	}
+	clock.Advance(time.Second)
+	if _, found, _ := provider.Get(ctx, "key"); found {
+		t.Fatal("expected entry to expire after its ttl")
+	}
+}
+
+func TestRecordingProvider_FailWith(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	errBoom := errors.New("boom")
+	provider := NewRecordingProvider[string](nil)
+	provider.FailWith(OpGet, errBoom)
+	if _, _, err := provider.Get(ctx, "key"); !errors.Is(err, errBoom) {
+		t.Fatalf("expected injected error, got %v", err)
+	}
+	if calls := provider.CallsOf(OpGet); len(calls) != 1 || !errors.Is(calls[0].Err, errBoom) {
+		t.Fatalf("expected failed call to be recorded, got %+v", calls)
+	}
+	provider.FailWith(OpGet, nil)
+	if _, _, err := provider.Get(ctx, "key"); err != nil {
+		t.Fatalf("expected cleared error, got %v", err)
+	}
+}
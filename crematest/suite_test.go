@@ -0,0 +1,45 @@
+package crematest
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/abema/crema"
+)
+
+func TestTestProvider_RecordingProvider(t *testing.T) {
+	t.Parallel()
+
+	clock := NewFakeClock(time.UnixMilli(0))
+	TestProvider(t, ProviderSuite[[]byte]{
+		NewProvider: func(*testing.T) crema.CacheProvider[[]byte] {
+			return NewRecordingProvider[[]byte](clock.Now)
+		},
+		Expire: func(_ *testing.T, _ crema.CacheProvider[[]byte], ttl time.Duration) { clock.Advance(ttl) },
+		Break: func(_ *testing.T, provider crema.CacheProvider[[]byte]) {
+			provider.(*RecordingProvider[[]byte]).FailWith(OpGet, errors.New("broken"))
+		},
+	})
+}
+
+func TestTestCodec_JSONByteStringCodec(t *testing.T) {
+	t.Parallel()
+
+	TestCodec(t, CodecSuite[string, []byte]{
+		Codec:   crema.JSONByteStringCodec[string]{},
+		Values:  []string{"", "value", "ünïcödé", "\x00\n\"quoted\""},
+		Invalid: [][]byte{nil, []byte("{"), []byte(`{"Value":1}`)},
+	})
+}
+
+func TestTestCodec_CompressionAndChecksumCodecs(t *testing.T) {
+	t.Parallel()
+
+	TestCodec(t, CodecSuite[string, []byte]{
+		Codec:   crema.NewChecksumCodec(crema.NewBinaryCompressionCodec(crema.JSONByteStringCodec[string]{}, 0)),
+		Values:  []string{"", "value", strings.Repeat("compressible ", 100)},
+		Invalid: [][]byte{nil, {0xff}, []byte("not a checksummed payload")},
+	})
+}
@@ -0,0 +1,99 @@
+package crema
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrDecode matches errors caused by cache entries that could not be decoded.
+	ErrDecode = errors.New("failed to decode cache entry")
+	// ErrProvider matches errors returned by the CacheProvider.
+	ErrProvider = errors.New("cache provider failed")
+	// ErrCacheClosed is returned when a load is requested after Close.
+	ErrCacheClosed = errors.New("cache is closed")
+	// ErrFollowerTimeout is returned when a caller gives up waiting for a load
+	// started by another caller, as configured by WithFollowerWaitTimeout.
+	ErrFollowerTimeout = errors.New("timed out waiting for in-flight load")
+	// ErrLoadPanic matches errors caused by a CacheLoadFunc that panicked.
+	ErrLoadPanic = errors.New("cache loader panicked")
+)
+
+// DecodeError reports a stored entry that the codec could not decode.
+// It matches ErrDecode with errors.Is and unwraps to the codec error.
+type DecodeError struct {
+	// Key is the cache key of the undecodable entry.
+	Key string
+	// Err is the error returned by the codec.
+	Err error
+}
+
+// Error implements error.
+func (e *DecodeError) Error() string {
+	return ErrDecode.Error() + " " + e.Key + ": " + e.Err.Error()
+}
+
+// Unwrap returns the codec error.
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether target is ErrDecode.
+func (e *DecodeError) Is(target error) bool {
+	return target == ErrDecode
+}
+
+// ProviderError reports a failed CacheProvider operation, such as a storage outage.
+// It matches ErrProvider with errors.Is and unwraps to the provider error.
+type ProviderError struct {
+	// Op is the provider operation: "get", "set" or "delete".
+	Op string
+	// Key is the cache key passed to the provider.
+	Key string
+	// Err is the error returned by the provider.
+	Err error
+}
+
+// Error implements error.
+func (e *ProviderError) Error() string {
+	return ErrProvider.Error() + " on " + e.Op + " " + e.Key + ": " + e.Err.Error()
+}
+
+// Unwrap returns the provider error.
+func (e *ProviderError) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether target is ErrProvider.
+func (e *ProviderError) Is(target error) bool {
+	return target == ErrProvider
+}
+
+// LoadPanicError reports a panic recovered from a CacheLoadFunc run by a
+// singleflight leader. It is delivered to every caller waiting on the load
+// and matches ErrLoadPanic with errors.Is.
+type LoadPanicError struct {
+	// Value is the value passed to panic.
+	Value any
+	// Stack is the stack trace of the panicking goroutine.
+	Stack []byte
+}
+
+// Error implements error.
+func (e *LoadPanicError) Error() string {
+	return fmt.Sprintf("%s: %v", ErrLoadPanic.Error(), e.Value)
+}
+
+// Unwrap returns Value when the loader panicked with an error.
+func (e *LoadPanicError) Unwrap() error {
+	if err, ok := e.Value.(error); ok {
+		return err
+	}
+
+	return nil
+}
+
+// Is reports whether target is ErrLoadPanic.
+func (e *LoadPanicError) Is(target error) bool {
+	return target == ErrLoadPanic
+}
@@ -5,15 +5,28 @@ import (
 	"testing"
 
 	"github.com/abema/crema"
+	"github.com/abema/crema/crematest"
 )
 
+func TestJSONByteStringCodec_Conformance(t *testing.T) {
+	t.Parallel()
+
+	crematest.TestCodec(t, crematest.CodecSuite[string, []byte]{
+		Codec:   JSONByteStringCodec[string]{},
+		Values:  []string{"", "value", "ünïcödé", "\x00\n\"quoted\""},
+		Invalid: [][]byte{nil, []byte("{"), []byte(`{"Value":1}`)},
+	})
+}
+
 func TestJSONByteStringCodec_RoundTrip(t *testing.T) {
 	t.Parallel()
 
 	codec := JSONByteStringCodec[int]{}
 	input := &crema.CacheObject[int]{
-		Value:          10,
-		ExpireAtMillis: 1234,
+		Value:              10,
+		ExpireAtMillis:     1234,
+		LoadDurationMillis: 56,
+		TTLMillis:          7890,
 	}
 	encoded, err := codec.Encode(*input)
 	if err != nil {
@@ -4,8 +4,23 @@ import (
 	"context"
 	"testing"
 	"time"
+
+	"github.com/abema/crema"
+	"github.com/abema/crema/crematest"
 )
 
+func TestCacheProvider_Conformance(t *testing.T) {
+	t.Parallel()
+
+	crematest.TestProvider(t, crematest.ProviderSuite[[]byte]{
+		NewProvider: func(*testing.T) crema.CacheProvider[[]byte] {
+			return NewCacheProvider[[]byte](1024, time.Hour)
+		},
+		// Entries expire after the provider's default TTL instead of their own.
+		SkipTTL: true,
+	})
+}
+
 func TestCacheProvider_GetSetDelete(t *testing.T) {
 	t.Parallel()
 
@@ -5,8 +5,29 @@ import (
 	"errors"
 	"testing"
 	"time"
+
+	"github.com/abema/crema"
+	"github.com/abema/crema/crematest"
 )
 
+func TestMemcachedCacheProvider_Conformance(t *testing.T) {
+	t.Parallel()
+
+	crematest.TestProvider(t, crematest.ProviderSuite[[]byte]{
+		NewProvider: func(*testing.T) crema.CacheProvider[[]byte] {
+			return NewMemcachedCacheProvider(newTestMemcacheClient())
+		},
+		// Memcached expirations have second granularity.
+		TTL: time.Second,
+		Expire: func(_ *testing.T, _ crema.CacheProvider[[]byte], ttl time.Duration) {
+			time.Sleep(ttl + 100*time.Millisecond)
+		},
+		Break: func(_ *testing.T, provider crema.CacheProvider[[]byte]) {
+			provider.(*MemcachedCacheProvider).client.(*testMemcacheClient).getErr = errors.New("broken")
+		},
+	})
+}
+
 func TestMemcachedCacheProvider_GetSetDelete(t *testing.T) {
 	t.Parallel()
 
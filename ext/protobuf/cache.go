@@ -53,6 +53,8 @@ func (p ProtobufCodec[V]) Encode(value crema.CacheObject[V]) ([]byte, error) {
 	envelope.SetVersion(protoCacheEnvelopeVersion)
 	envelope.SetSerializedValue(serializedValue)
 	envelope.SetExpireAtMillis(value.ExpireAtMillis)
+	envelope.SetLoadDurationMillis(value.LoadDurationMillis)
+	envelope.SetTtlMillis(value.TTLMillis)
 	encoded, err := marshalOptions.MarshalAppend(nil, envelope)
 	if err != nil {
 		return nil, err
@@ -74,8 +76,10 @@ func (p ProtobufCodec[V]) Decode(data []byte) (crema.CacheObject[V], error) {
 	}
 
 	return crema.CacheObject[V]{
-		Value:          msg,
-		ExpireAtMillis: envelope.GetExpireAtMillis(),
+		Value:              msg,
+		ExpireAtMillis:     envelope.GetExpireAtMillis(),
+		LoadDurationMillis: envelope.GetLoadDurationMillis(),
+		TTLMillis:          envelope.GetTtlMillis(),
 	}, nil
 }
 
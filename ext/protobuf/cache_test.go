@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/abema/crema"
+	"github.com/abema/crema/crematest"
 	testproto "github.com/abema/crema/ext/protobuf/internal/proto"
 	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/reflect/protoreflect"
@@ -21,8 +22,10 @@ func TestProtobufCodec_EncodeDecodeRoundTrip(t *testing.T) {
 	value.SetValue(123)
 
 	in := crema.CacheObject[*testproto.ProtoTestObject]{
-		Value:          value,
-		ExpireAtMillis: 456,
+		Value:              value,
+		ExpireAtMillis:     456,
+		LoadDurationMillis: 78,
+		TTLMillis:          90,
 	}
 
 	encoded, err := codec.Encode(in)
@@ -41,6 +44,34 @@ func TestProtobufCodec_EncodeDecodeRoundTrip(t *testing.T) {
 	if out.ExpireAtMillis != 456 {
 		t.Fatalf("decoded expiration = %d, want %d", out.ExpireAtMillis, 456)
 	}
+	if out.LoadDurationMillis != 78 {
+		t.Fatalf("decoded load duration = %d, want %d", out.LoadDurationMillis, 78)
+	}
+	if out.TTLMillis != 90 {
+		t.Fatalf("decoded ttl = %d, want %d", out.TTLMillis, 90)
+	}
+}
+
+func TestProtobufCodec_Conformance(t *testing.T) {
+	t.Parallel()
+
+	codec, err := NewProtobufCodec(&testproto.ProtoTestObject{})
+	if err != nil {
+		t.Fatalf("NewProtobufCodec() error = %v", err)
+	}
+	values := make([]*testproto.ProtoTestObject, 0, 3)
+	for _, v := range []int64{0, 1, -42} {
+		value := &testproto.ProtoTestObject{}
+		value.SetValue(v)
+		values = append(values, value)
+	}
+
+	crematest.TestCodec(t, crematest.CodecSuite[*testproto.ProtoTestObject, []byte]{
+		Codec:   codec,
+		Values:  values,
+		Equal:   func(a, b *testproto.ProtoTestObject) bool { return proto.Equal(a, b) },
+		Invalid: [][]byte{[]byte("not-a-proto")},
+	})
 }
 
 func TestNewProtobufCodec_RejectsNilPrototype(t *testing.T) {
@@ -22,14 +22,16 @@ const (
 )
 
 type ProtoCacheObject struct {
-	state                      protoimpl.MessageState `protogen:"opaque.v1"`
-	xxx_hidden_Version         int32                  `protobuf:"varint,1,opt,name=version"`
-	xxx_hidden_SerializedValue []byte                 `protobuf:"bytes,2,opt,name=serialized_value,json=serializedValue"`
-	xxx_hidden_ExpireAtMillis  int64                  `protobuf:"varint,3,opt,name=expire_at_millis,json=expireAtMillis"`
-	XXX_raceDetectHookData     protoimpl.RaceDetectHookData
-	XXX_presence               [1]uint32
-	unknownFields              protoimpl.UnknownFields
-	sizeCache                  protoimpl.SizeCache
+	state                         protoimpl.MessageState `protogen:"opaque.v1"`
+	xxx_hidden_Version            int32                  `protobuf:"varint,1,opt,name=version"`
+	xxx_hidden_SerializedValue    []byte                 `protobuf:"bytes,2,opt,name=serialized_value,json=serializedValue"`
+	xxx_hidden_ExpireAtMillis     int64                  `protobuf:"varint,3,opt,name=expire_at_millis,json=expireAtMillis"`
+	xxx_hidden_LoadDurationMillis int64                  `protobuf:"varint,4,opt,name=load_duration_millis,json=loadDurationMillis"`
+	xxx_hidden_TtlMillis          int64                  `protobuf:"varint,5,opt,name=ttl_millis,json=ttlMillis"`
+	XXX_raceDetectHookData        protoimpl.RaceDetectHookData
+	XXX_presence                  [1]uint32
+	unknownFields                 protoimpl.UnknownFields
+	sizeCache                     protoimpl.SizeCache
 }
 
 func (x *ProtoCacheObject) Reset() {
@@ -78,9 +80,23 @@ func (x *ProtoCacheObject) GetExpireAtMillis() int64 {
 	return 0
 }
 
+func (x *ProtoCacheObject) GetLoadDurationMillis() int64 {
+	if x != nil {
+		return x.xxx_hidden_LoadDurationMillis
+	}
+	return 0
+}
+
+func (x *ProtoCacheObject) GetTtlMillis() int64 {
+	if x != nil {
+		return x.xxx_hidden_TtlMillis
+	}
+	return 0
+}
+
 func (x *ProtoCacheObject) SetVersion(v int32) {
 	x.xxx_hidden_Version = v
-	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 0, 3)
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 0, 5)
 }
 
 func (x *ProtoCacheObject) SetSerializedValue(v []byte) {
@@ -88,12 +104,22 @@ func (x *ProtoCacheObject) SetSerializedValue(v []byte) {
 		v = []byte{}
 	}
 	x.xxx_hidden_SerializedValue = v
-	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 1, 3)
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 1, 5)
 }
 
 func (x *ProtoCacheObject) SetExpireAtMillis(v int64) {
 	x.xxx_hidden_ExpireAtMillis = v
-	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 2, 3)
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 2, 5)
+}
+
+func (x *ProtoCacheObject) SetLoadDurationMillis(v int64) {
+	x.xxx_hidden_LoadDurationMillis = v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 3, 5)
+}
+
+func (x *ProtoCacheObject) SetTtlMillis(v int64) {
+	x.xxx_hidden_TtlMillis = v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 4, 5)
 }
 
 func (x *ProtoCacheObject) HasVersion() bool {
@@ -117,6 +143,20 @@ func (x *ProtoCacheObject) HasExpireAtMillis() bool {
 	return protoimpl.X.Present(&(x.XXX_presence[0]), 2)
 }
 
+func (x *ProtoCacheObject) HasLoadDurationMillis() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 3)
+}
+
+func (x *ProtoCacheObject) HasTtlMillis() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 4)
+}
+
 func (x *ProtoCacheObject) ClearVersion() {
 	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 0)
 	x.xxx_hidden_Version = 0
@@ -132,12 +172,24 @@ func (x *ProtoCacheObject) ClearExpireAtMillis() {
 	x.xxx_hidden_ExpireAtMillis = 0
 }
 
+func (x *ProtoCacheObject) ClearLoadDurationMillis() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 3)
+	x.xxx_hidden_LoadDurationMillis = 0
+}
+
+func (x *ProtoCacheObject) ClearTtlMillis() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 4)
+	x.xxx_hidden_TtlMillis = 0
+}
+
 type ProtoCacheObject_builder struct {
 	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
 
-	Version         *int32
-	SerializedValue []byte
-	ExpireAtMillis  *int64
+	Version            *int32
+	SerializedValue    []byte
+	ExpireAtMillis     *int64
+	LoadDurationMillis *int64
+	TtlMillis          *int64
 }
 
 func (b0 ProtoCacheObject_builder) Build() *ProtoCacheObject {
@@ -145,17 +197,25 @@ func (b0 ProtoCacheObject_builder) Build() *ProtoCacheObject {
 	b, x := &b0, m0
 	_, _ = b, x
 	if b.Version != nil {
-		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 0, 3)
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 0, 5)
 		x.xxx_hidden_Version = *b.Version
 	}
 	if b.SerializedValue != nil {
-		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 1, 3)
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 1, 5)
 		x.xxx_hidden_SerializedValue = b.SerializedValue
 	}
 	if b.ExpireAtMillis != nil {
-		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 2, 3)
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 2, 5)
 		x.xxx_hidden_ExpireAtMillis = *b.ExpireAtMillis
 	}
+	if b.LoadDurationMillis != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 3, 5)
+		x.xxx_hidden_LoadDurationMillis = *b.LoadDurationMillis
+	}
+	if b.TtlMillis != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 4, 5)
+		x.xxx_hidden_TtlMillis = *b.TtlMillis
+	}
 	return m0
 }
 
@@ -163,11 +223,14 @@ var File_internal_proto_cache_object_proto protoreflect.FileDescriptor
 
 const file_internal_proto_cache_object_proto_rawDesc = "" +
 	"\n" +
-	"!internal/proto/cache_object.proto\x12\x05proto\x1a!google/protobuf/go_features.proto\"\x81\x01\n" +
+	"!internal/proto/cache_object.proto\x12\x05proto\x1a!google/protobuf/go_features.proto\"\xd2\x01\n" +
 	"\x10ProtoCacheObject\x12\x18\n" +
 	"\aversion\x18\x01 \x01(\x05R\aversion\x12)\n" +
 	"\x10serialized_value\x18\x02 \x01(\fR\x0fserializedValue\x12(\n" +
-	"\x10expire_at_millis\x18\x03 \x01(\x03R\x0eexpireAtMillisB\x8d\x01\n" +
+	"\x10expire_at_millis\x18\x03 \x01(\x03R\x0eexpireAtMillis\x120\n" +
+	"\x14load_duration_millis\x18\x04 \x01(\x03R\x12loadDurationMillis\x12\x1d\n" +
+	"\n" +
+	"ttl_millis\x18\x05 \x01(\x03R\tttlMillisB\x8d\x01\n" +
 	"\tcom.protoB\x10CacheObjectProtoP\x01Z2github.com/abema/crema/ext/protobuf/internal/proto\xa2\x02\x03PXX\xaa\x02\x05Proto\xca\x02\x05Proto\xe2\x02\x11Proto\\GPBMetadata\xea\x02\x05Proto\x92\x03\x05\xd2>\x02\x10\x03b\beditionsp\xe8\a"
 
 var file_internal_proto_cache_object_proto_msgTypes = make([]protoimpl.MessageInfo, 1)
@@ -5,6 +5,8 @@ import (
 	"testing"
 	"time"
 
+	"github.com/abema/crema"
+	"github.com/abema/crema/crematest"
 	dgraphristretto "github.com/dgraph-io/ristretto"
 )
 
@@ -23,6 +25,38 @@ func newTestCache(t *testing.T) *dgraphristretto.Cache {
 	return cache
 }
 
+// waitingCacheProvider waits for ristretto's buffered writes after each Set,
+// which the conformance suite expects to be visible immediately.
+type waitingCacheProvider struct {
+	*RistrettoCacheProvider[[]byte]
+}
+
+func (p waitingCacheProvider) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	err := p.RistrettoCacheProvider.Set(ctx, key, value, ttl)
+	p.cache.Wait()
+
+	return err
+}
+
+func TestRistrettoCacheProvider_Conformance(t *testing.T) {
+	t.Parallel()
+
+	crematest.TestProvider(t, crematest.ProviderSuite[[]byte]{
+		NewProvider: func(t *testing.T) crema.CacheProvider[[]byte] {
+			t.Helper()
+
+			cache := newTestCache(t)
+			t.Cleanup(cache.Close)
+			provider, err := NewRistrettoCacheProvider[[]byte](cache)
+			if err != nil {
+				t.Fatalf("create provider: %v", err)
+			}
+
+			return waitingCacheProvider{provider}
+		},
+	})
+}
+
 func TestNewRistrettoCacheProvider_NilCache(t *testing.T) {
 	t.Parallel()
 
@@ -5,6 +5,8 @@ import (
 	"testing"
 	"time"
 
+	"github.com/abema/crema"
+	"github.com/abema/crema/crematest"
 	"github.com/alicebob/miniredis/v2"
 	"github.com/redis/rueidis"
 )
@@ -86,6 +88,30 @@ func TestRedisCacheProvider_GetWrongType(t *testing.T) {
 	}
 }
 
+// conformanceProvider keeps the server of a provider for the conformance suite.
+type conformanceProvider struct {
+	*RedisCacheProvider
+	server *miniredis.Miniredis
+}
+
+func TestRedisCacheProvider_Conformance(t *testing.T) {
+	t.Parallel()
+
+	crematest.TestProvider(t, crematest.ProviderSuite[[]byte]{
+		NewProvider: func(t *testing.T) crema.CacheProvider[[]byte] {
+			server, _, provider := newTestRedisProvider(t)
+
+			return conformanceProvider{RedisCacheProvider: provider, server: server}
+		},
+		Expire: func(_ *testing.T, provider crema.CacheProvider[[]byte], ttl time.Duration) {
+			provider.(conformanceProvider).server.FastForward(ttl)
+		},
+		Break: func(_ *testing.T, provider crema.CacheProvider[[]byte]) {
+			provider.(conformanceProvider).server.SetError("broken")
+		},
+	})
+}
+
 func newTestRedisProvider(t *testing.T) (*miniredis.Miniredis, rueidis.Client, *RedisCacheProvider) {
 	t.Helper()
 
@@ -0,0 +1,232 @@
+package crema
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+)
+
+const (
+	hedgeLatencySamples    = 128
+	minHedgeLatencySamples = 16
+	maxHedgeCalls          = 2 // the original call and one hedge
+)
+
+// HedgePolicy configures hedged loads started by singleflight leaders when the
+// loader is slow to respond.
+type HedgePolicy struct {
+	// Delay is how long the leader waits for the loader before starting a second,
+	// hedged call. With Percentile set, it is used until enough latencies are observed.
+	// Zero disables hedging until then.
+	Delay time.Duration
+	// Percentile, in (0, 1), derives the delay from recent successful load
+	// latencies, e.g. 0.95 hedges loads slower than the observed p95.
+	Percentile float64
+	// MaxHedgesPerSecond caps how often hedged calls are started, so a slow
+	// backend is not overloaded with duplicate calls. Zero means uncapped.
+	MaxHedgesPerSecond float64
+}
+
+// loadHedger decides when singleflight leaders start hedged loads.
+type loadHedger struct {
+	_          noCopy
+	policy     HedgePolicy
+	limiter    *rateLimiter
+	mu         sync.Mutex
+	latencies  []time.Duration // ring buffer of recent successful load latencies
+	next       int
+	percentile time.Duration
+	stale      bool
+}
+
+type hedgeResult[V any] struct {
+	val    V
+	err    error
+	hedged bool
+}
+
+func newLoadHedger(policy HedgePolicy) *loadHedger {
+	hedger := &loadHedger{policy: policy}
+	if policy.MaxHedgesPerSecond > 0 {
+		hedger.limiter = newRateLimiter(LoadRateLimit{Rate: policy.MaxHedgesPerSecond})
+	}
+
+	return hedger
+}
+
+// delay returns how long to wait before hedging, or zero to not hedge.
+func (h *loadHedger) delay() time.Duration {
+	if h.policy.Percentile <= 0 || h.policy.Percentile >= 1 {
+		return h.policy.Delay
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if len(h.latencies) < minHedgeLatencySamples {
+		return h.policy.Delay
+	}
+	if h.stale {
+		sorted := slices.Clone(h.latencies)
+		slices.Sort(sorted)
+		h.percentile = sorted[int(h.policy.Percentile*float64(len(sorted)))]
+		h.stale = false
+	}
+
+	return h.percentile
+}
+
+// allow reports whether a hedged call may start under MaxHedgesPerSecond.
+func (h *loadHedger) allow() bool {
+	if h.limiter == nil {
+		return true
+	}
+
+	return h.limiter.take("")
+}
+
+func (h *loadHedger) observe(latency time.Duration) {
+	if h.policy.Percentile <= 0 {
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if len(h.latencies) < hedgeLatencySamples {
+		h.latencies = append(h.latencies, latency)
+	} else {
+		h.latencies[h.next] = latency
+		h.next = (h.next + 1) % hedgeLatencySamples
+	}
+	h.stale = true
+}
+
+// loadHedged runs loader and, if it has not returned after the hedge delay,
+// starts a second call, returning the first success and canceling the other.
+// Without a hedge policy or delay it simply runs loader. The original call
+// holds slot until it returns, even when the hedge wins.
+func (l *singleflightLoader[V]) loadHedged(
+	ctx context.Context,
+	key string,
+	loadCtx context.Context,
+	slot *loadSlot,
+	loader CacheLoadFunc[V],
+) (V, error) {
+	var delay time.Duration
+	if l.hedger != nil {
+		delay = l.hedger.delay()
+	}
+	if delay <= 0 {
+		return l.call(loadCtx, key, loader)
+	}
+
+	hedgeCtx, cancel := context.WithCancel(loadCtx)
+	defer cancel()
+	results := make(chan hedgeResult[V], maxHedgeCalls)
+	l.lifecycle.extend()
+	go l.runHedgeCall(hedgeCtx, key, loader, false, slot.hold(), results)
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case result := <-results:
+		return result.val, result.err
+	case <-timer.C:
+	}
+
+	pending := 1
+	if release, ok := l.startHedge(ctx, key); ok {
+		go l.runHedgeCall(hedgeCtx, key, loader, true, release, results)
+		pending++
+	}
+
+	return l.awaitHedged(ctx, results, pending)
+}
+
+// startHedge reports whether a hedged call may start. Like any other loader
+// call, it must be admitted by the rate and concurrency limits, without
+// waiting, as well as by MaxHedgesPerSecond. When it may start, startHedge
+// acquires a lifecycle unit and returns a function releasing its concurrency
+// slots.
+func (l *singleflightLoader[V]) startHedge(ctx context.Context, key string) (func(), bool) {
+	release := func() {}
+	if l.limiter != nil {
+		var ok bool
+		if release, ok = l.limiter.tryAcquire(key); !ok {
+			return nil, false
+		}
+	}
+	if !l.rateLimiters.take(key) {
+		release()
+
+		return nil, false
+	}
+	if !l.hedger.allow() || !l.lifecycle.acquire() {
+		l.rateLimiters.refund(key)
+		release()
+
+		return nil, false
+	}
+	if metrics, ok := l.metrics.(HedgeMetricsProvider); ok {
+		metrics.RecordLoadHedge(ctx)
+	}
+
+	return release, true
+}
+
+// awaitHedged returns the first successful result of the pending calls, or
+// the last error when they all fail.
+func (l *singleflightLoader[V]) awaitHedged(ctx context.Context, results <-chan hedgeResult[V], pending int) (V, error) {
+	var result hedgeResult[V]
+	for ; pending > 0; pending-- {
+		if result = <-results; result.err == nil {
+			break
+		}
+	}
+	if result.err == nil && result.hedged {
+		if metrics, ok := l.metrics.(HedgeMetricsProvider); ok {
+			metrics.RecordLoadHedgeWin(ctx)
+		}
+	}
+
+	return result.val, result.err
+}
+
+// runHedgeCall runs one of the calls of a hedged load, reporting its result
+// to results. It releases the lifecycle unit acquired for it and calls release.
+func (l *singleflightLoader[V]) runHedgeCall(
+	ctx context.Context,
+	key string,
+	loader CacheLoadFunc[V],
+	hedged bool,
+	release func(),
+	results chan<- hedgeResult[V],
+) {
+	defer l.lifecycle.release()
+	defer release()
+
+	v, err := l.call(ctx, key, loader)
+	results <- hedgeResult[V]{val: v, err: err, hedged: hedged}
+}
+
+// call runs loader once, converting a panic into a *LoadPanicError, so hedged
+// and unhedged calls fail alike, and recording the latency of successful calls
+// for percentile hedge delays.
+func (l *singleflightLoader[V]) call(loadCtx context.Context, key string, loader CacheLoadFunc[V]) (v V, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			var zero V
+			v, err = zero, l.loadPanicError(key, r)
+		}
+	}()
+
+	start := time.Now()
+	v, err = loader(loadCtx)
+	if err == nil && l.hedger != nil {
+		l.hedger.observe(time.Since(start))
+	}
+
+	return v, err
+}
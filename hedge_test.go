@@ -0,0 +1,254 @@
+package crema
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type hedgeTestMetrics struct {
+	BaseMetricsProvider
+
+	hedges atomic.Int32
+	wins   atomic.Int32
+}
+
+func (m *hedgeTestMetrics) RecordLoadHedge(context.Context) {
+	m.hedges.Add(1)
+}
+
+func (m *hedgeTestMetrics) RecordLoadHedgeWin(context.Context) {
+	m.wins.Add(1)
+}
+
+// slowFirstLoader blocks its first call until canceled and answers later calls with value.
+func slowFirstLoader(value int) (CacheLoadFunc[int], *atomic.Int32, <-chan struct{}) {
+	calls := &atomic.Int32{}
+	canceled := make(chan struct{})
+
+	return func(ctx context.Context) (int, error) {
+		if calls.Add(1) == 1 {
+			<-ctx.Done()
+			close(canceled)
+
+			return 0, ctx.Err()
+		}
+
+		return value, nil
+	}, calls, canceled
+}
+
+func TestLoadHedging_HedgeWinsWhenFirstCallIsSlow(t *testing.T) {
+	t.Parallel()
+
+	metrics := &hedgeTestMetrics{}
+	loaderImpl := newSingleflightLoader[int](metrics, 0)
+	loaderImpl.hedger = newLoadHedger(HedgePolicy{Delay: 5 * time.Millisecond})
+	loader, calls, canceled := slowFirstLoader(3)
+
+	got, _, err := loaderImpl.load(context.Background(), "key", loader)
+	if err != nil || got != 3 {
+		t.Fatalf("expected hedged value 3, got value=%d err=%v", got, err)
+	}
+	if got := calls.Load(); got != 2 {
+		t.Fatalf("expected 2 loader calls, got %d", got)
+	}
+	if hedges, wins := metrics.hedges.Load(), metrics.wins.Load(); hedges != 1 || wins != 1 {
+		t.Fatalf("expected one hedge and one win, got hedges=%d wins=%d", hedges, wins)
+	}
+	select {
+	case <-canceled:
+	case <-time.After(time.Second):
+		t.Fatal("expected the slow call to be canceled")
+	}
+	if err := loaderImpl.drain(context.Background()); err != nil {
+		t.Fatalf("expected drain to succeed, got %v", err)
+	}
+}
+
+func TestLoadHedging_HedgesRespectLoadLimits(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]CacheOption[int, CacheObject[int]]{
+		"concurrency limit": WithLoadConcurrencyLimit[int, CacheObject[int]](LoadConcurrencyLimit{MaxConcurrency: 1}),
+		"rate limit":        WithLoadRateLimit[int, CacheObject[int]](LoadRateLimit{Rate: 0.001, Burst: 1}),
+	}
+	for name, limit := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			metrics := &hedgeTestMetrics{}
+			provider := &testMemoryProvider[int]{items: make(map[string]CacheObject[int])}
+			cache := NewCache(provider, NoopCacheStorageCodec[int]{},
+				WithMetricsProvider[int, CacheObject[int]](metrics),
+				WithLoadHedging[int, CacheObject[int]](HedgePolicy{Delay: time.Millisecond}),
+				limit,
+			)
+			var calls atomic.Int32
+			got, err := cache.GetOrLoad(context.Background(), "key", time.Minute, func(context.Context) (int, error) {
+				calls.Add(1)
+				time.Sleep(20 * time.Millisecond)
+
+				return 3, nil
+			})
+			if err != nil || got != 3 {
+				t.Fatalf("expected value 3, got value=%d err=%v", got, err)
+			}
+			if calls, hedges := calls.Load(), metrics.hedges.Load(); calls != 1 || hedges != 0 {
+				t.Fatalf("expected the limit to skip the hedge, got calls=%d hedges=%d", calls, hedges)
+			}
+		})
+	}
+}
+
+func TestLoadHedging_FastLoadIsNotHedged(t *testing.T) {
+	t.Parallel()
+
+	metrics := &hedgeTestMetrics{}
+	loaderImpl := newSingleflightLoader[int](metrics, 0)
+	loaderImpl.hedger = newLoadHedger(HedgePolicy{Delay: time.Second})
+	var calls atomic.Int32
+
+	got, _, err := loaderImpl.load(context.Background(), "key", func(context.Context) (int, error) {
+		calls.Add(1)
+
+		return 1, nil
+	})
+	if err != nil || got != 1 {
+		t.Fatalf("expected value 1, got value=%d err=%v", got, err)
+	}
+	if calls.Load() != 1 || metrics.hedges.Load() != 0 {
+		t.Fatalf("expected a single unhedged call, got calls=%d hedges=%d", calls.Load(), metrics.hedges.Load())
+	}
+}
+
+func TestLoadHedging_RecoversHedgedPanic(t *testing.T) {
+	t.Parallel()
+
+	loaderImpl := newSingleflightLoader[int](NoopMetricsProvider{}, 0)
+	loaderImpl.hedger = newLoadHedger(HedgePolicy{Delay: time.Millisecond})
+	var calls atomic.Int32
+
+	got, _, err := loaderImpl.load(context.Background(), "key", func(context.Context) (int, error) {
+		if calls.Add(1) == 1 {
+			time.Sleep(20 * time.Millisecond)
+
+			return 1, nil
+		}
+		panic("boom")
+	})
+	if err != nil || got != 1 {
+		t.Fatalf("expected the original call to succeed after the hedge panicked, got value=%d err=%v", got, err)
+	}
+}
+
+func TestLoadHedging_PanicsAreNotRetried(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]*loadHedger{
+		"unhedged": nil,
+		"hedged":   newLoadHedger(HedgePolicy{Delay: time.Second}),
+	}
+	for name, hedger := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			loaderImpl := newSingleflightLoader[int](NoopMetricsProvider{}, 0)
+			loaderImpl.hedger = hedger
+			loaderImpl.retry = &RetryPolicy{MaxAttempts: 3}
+			var calls atomic.Int32
+
+			_, _, err := loaderImpl.load(context.Background(), "key", func(context.Context) (int, error) {
+				calls.Add(1)
+				panic("boom")
+			})
+			if !errors.Is(err, ErrLoadPanic) {
+				t.Fatalf("expected ErrLoadPanic, got %v", err)
+			}
+			if got := calls.Load(); got != 1 {
+				t.Fatalf("expected the panic not to be retried, got %d calls", got)
+			}
+		})
+	}
+}
+
+func TestLoadHedging_HoldsSlotUntilLosingCallReturns(t *testing.T) {
+	t.Parallel()
+
+	loaderImpl := newSingleflightLoader[int](NoopMetricsProvider{}, 0)
+	loaderImpl.hedger = newLoadHedger(HedgePolicy{Delay: time.Millisecond})
+	loaderImpl.limiter = newLoadLimiter(LoadConcurrencyLimit{MaxConcurrency: 2})
+	var calls atomic.Int32
+	unblock := make(chan struct{})
+	returned := make(chan struct{})
+
+	got, _, err := loaderImpl.load(context.Background(), "key", func(context.Context) (int, error) {
+		if calls.Add(1) == 1 {
+			// Ignores cancellation, like a loader stuck in a non-cancelable call.
+			<-unblock
+			defer close(returned)
+
+			return 0, context.Canceled
+		}
+
+		return 3, nil
+	})
+	if err != nil || got != 3 {
+		t.Fatalf("expected hedged value 3, got value=%d err=%v", got, err)
+	}
+	// The winning hedge frees its slot right after reporting its result.
+	deadline := time.Now().Add(time.Second)
+	for len(loaderImpl.limiter.global.slots) > 1 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if held := len(loaderImpl.limiter.global.slots); held != 1 {
+		t.Fatalf("expected the losing call to hold its slot, got %d held slots", held)
+	}
+
+	close(unblock)
+	<-returned
+	if err := loaderImpl.drain(context.Background()); err != nil {
+		t.Fatalf("expected drain to succeed, got %v", err)
+	}
+	if held := len(loaderImpl.limiter.global.slots); held != 0 {
+		t.Fatalf("expected the slot to be released after the losing call returned, got %d held slots", held)
+	}
+}
+
+func TestLoadHedger_MaxHedgesPerSecond(t *testing.T) {
+	t.Parallel()
+
+	hedger := newLoadHedger(HedgePolicy{Delay: time.Millisecond, MaxHedgesPerSecond: 1})
+	now := time.UnixMilli(1000)
+	hedger.limiter.now = func() time.Time { return now }
+
+	if !hedger.allow() {
+		t.Fatal("expected first hedge to be allowed")
+	}
+	if hedger.allow() {
+		t.Fatal("expected second hedge within a second to be refused")
+	}
+	now = now.Add(time.Second)
+	if !hedger.allow() {
+		t.Fatal("expected hedge to be allowed after a second")
+	}
+}
+
+func TestLoadHedger_PercentileDelay(t *testing.T) {
+	t.Parallel()
+
+	hedger := newLoadHedger(HedgePolicy{Delay: time.Second, Percentile: 0.5})
+	for i := 1; i < minHedgeLatencySamples; i++ {
+		hedger.observe(time.Duration(i) * time.Millisecond)
+	}
+	if got := hedger.delay(); got != time.Second {
+		t.Fatalf("expected fallback delay before enough samples, got %v", got)
+	}
+
+	hedger.observe(minHedgeLatencySamples * time.Millisecond)
+	if got, want := hedger.delay(), (minHedgeLatencySamples/2+1)*time.Millisecond; got != want {
+		t.Fatalf("expected p50 delay %v, got %v", want, got)
+	}
+}
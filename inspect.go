@@ -0,0 +1,178 @@
+package crema
+
+import (
+	"cmp"
+	"slices"
+	"sync/atomic"
+	"time"
+)
+
+// CacheInspector reports a snapshot of a cache for diagnostics.
+// Caches built by NewCache implement it; use a type assertion to access it.
+type CacheInspector interface {
+	// Inspect returns the current configuration, counters and in-flight loads.
+	Inspect() CacheInfo
+}
+
+// CacheInfo is a snapshot of a cache returned by CacheInspector.
+type CacheInfo struct {
+	Config CacheConfig
+	Stats  CacheStats
+	// InFlight lists the singleflight loads in progress, with the most waited
+	// on first. It is empty with WithDirectLoader.
+	InFlight []InFlightLoad
+}
+
+// CacheConfig is the configuration a cache was built with.
+type CacheConfig struct {
+	// Singleflight reports whether concurrent loads of a key are shared.
+	// It is false with WithDirectLoader.
+	Singleflight bool
+	// RevalidationStrategy is the configured revalidation strategy.
+	RevalidationStrategy RevalidationStrategy
+	// RevalidationWindow is the strategy's window for entries with an unknown TTL.
+	RevalidationWindow time.Duration
+	// RevalidationSteepness is k of p(t)=1-exp(-k*t), with t in milliseconds,
+	// for NewExponentialRevalidation. It is zero for other strategies.
+	RevalidationSteepness float64
+	// Clock is the source of the current time for expiry and revalidation
+	// decisions, time.Now unless set by WithClock.
+	Clock func() time.Time
+	// MaxLoadTimeout bounds loader execution, set by WithMaxLoadTimeout.
+	// Non-positive means unbounded.
+	MaxLoadTimeout time.Duration
+	// FollowerWaitTimeout bounds how long calls wait for a load led by another
+	// call, set by WithFollowerWaitTimeout. Non-positive means unbounded.
+	FollowerWaitTimeout time.Duration
+	// ProviderGetTimeout bounds provider Get calls, set by WithProviderGetTimeout.
+	// Non-positive means unbounded.
+	ProviderGetTimeout time.Duration
+	// ProviderSetTimeout bounds provider Set calls, set by WithProviderSetTimeout.
+	// Non-positive means unbounded.
+	ProviderSetTimeout time.Duration
+	// AsyncSet reports whether loaded values are stored in the background,
+	// set by WithAsyncSet.
+	AsyncSet bool
+	// WriteBehind reports whether loaded values are stored through the
+	// write-behind queue of WithWriteBehind.
+	WriteBehind bool
+}
+
+// CacheStats counts cache operations since the cache was built.
+type CacheStats struct {
+	// Gets counts Get calls, including the lookups of GetOrLoad.
+	Gets int64
+	// Hits counts Get calls that returned a cached entry.
+	Hits int64
+	// Sets counts Set calls.
+	Sets int64
+	// Deletes counts Delete calls.
+	Deletes int64
+	// Loads counts loads led by GetOrLoad, Refresh, Warm and WarmBatch calls,
+	// whether or not they succeeded.
+	Loads int64
+	// SharedLoads counts GetOrLoad and Refresh calls that waited for a load
+	// led by another call.
+	SharedLoads int64
+	// Revalidations counts GetOrLoad loads of entries that were cached but due
+	// for revalidation.
+	Revalidations int64
+	// StaleServed counts GetOrLoad calls that served a cached value after the load failed.
+	StaleServed int64
+	// LoadErrors counts GetOrLoad and Refresh calls whose load failed.
+	LoadErrors int64
+}
+
+// InFlightLoad is a singleflight load in progress.
+type InFlightLoad struct {
+	// Key is the key being loaded.
+	Key string
+	// Waiters is the number of calls waiting for the load, including its leader.
+	Waiters int
+}
+
+type cacheStats struct {
+	gets          atomic.Int64
+	hits          atomic.Int64
+	sets          atomic.Int64
+	deletes       atomic.Int64
+	loads         atomic.Int64
+	sharedLoads   atomic.Int64
+	revalidations atomic.Int64
+	staleServed   atomic.Int64
+	loadErrors    atomic.Int64
+}
+
+func (s *cacheStats) snapshot() CacheStats {
+	return CacheStats{
+		Gets:          s.gets.Load(),
+		Hits:          s.hits.Load(),
+		Sets:          s.sets.Load(),
+		Deletes:       s.deletes.Load(),
+		Loads:         s.loads.Load(),
+		SharedLoads:   s.sharedLoads.Load(),
+		Revalidations: s.revalidations.Load(),
+		StaleServed:   s.staleServed.Load(),
+		LoadErrors:    s.loadErrors.Load(),
+	}
+}
+
+// recordLoad counts a load by the calling GetOrLoad or Refresh.
+func (s *cacheStats) recordLoad(leader bool, err error) {
+	if leader {
+		s.loads.Add(1)
+	} else {
+		s.sharedLoads.Add(1)
+	}
+	if err != nil {
+		s.loadErrors.Add(1)
+	}
+}
+
+var _ CacheInspector = (*cacheImpl[any, any])(nil)
+
+// Inspect returns the current configuration, counters and in-flight loads.
+func (c *cacheImpl[V, S]) Inspect() CacheInfo {
+	config := CacheConfig{
+		RevalidationStrategy: c.revalidation,
+		Clock:                c.now,
+		RevalidationWindow:   c.revalidation.Window(0),
+		MaxLoadTimeout:       c.maxLoadTimeout,
+		ProviderGetTimeout:   c.providerGetTimeout,
+		ProviderSetTimeout:   c.providerSetTimeout,
+		AsyncSet:             c.asyncSet,
+		WriteBehind:          c.writeBehind != nil,
+	}
+	if exponential, ok := c.revalidation.(*exponentialRevalidation); ok {
+		config.RevalidationSteepness = exponential.steepness
+	}
+	var inFlight []InFlightLoad
+	if loader, ok := c.internalLoader.(*singleflightLoader[V]); ok {
+		config.Singleflight = true
+		config.FollowerWaitTimeout = loader.followerTimeout
+		inFlight = loader.inFlightLoads()
+	}
+
+	return CacheInfo{Config: config, Stats: c.stats.snapshot(), InFlight: inFlight}
+}
+
+// inFlightLoads returns the loads that have not finished yet, with the most
+// waited on first.
+func (l *singleflightLoader[V]) inFlightLoads() []InFlightLoad {
+	var loads []InFlightLoad
+	for i := range l.shards {
+		shard := &l.shards[i]
+		shard.mu.Lock()
+		for key, inf := range shard.inflight {
+			if !inf.done {
+				loads = append(loads, InFlightLoad{Key: key, Waiters: inf.refs})
+			}
+		}
+		shard.mu.Unlock()
+	}
+	slices.SortFunc(loads, func(a, b InFlightLoad) int {
+		return cmp.Or(cmp.Compare(b.Waiters, a.Waiters), cmp.Compare(a.Key, b.Key))
+	})
+
+	return loads
+}
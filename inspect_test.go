@@ -0,0 +1,132 @@
+package crema
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestInspect_ReportsConfig(t *testing.T) {
+	t.Parallel()
+
+	provider := &testMemoryProvider[int]{items: make(map[string]CacheObject[int])}
+	cache := NewCache(provider, NoopCacheStorageCodec[int]{},
+		WithRevalidationWindow[int, CacheObject[int]](time.Minute),
+		WithMaxLoadTimeout[int, CacheObject[int]](3*time.Second),
+		WithFollowerWaitTimeout[int, CacheObject[int]](time.Second),
+		WithProviderGetTimeout[int, CacheObject[int]](100*time.Millisecond),
+		WithAsyncSet[int, CacheObject[int]](),
+	)
+	inspector, ok := cache.(CacheInspector)
+	if !ok {
+		t.Fatal("expected NewCache to return a CacheInspector")
+	}
+
+	config := inspector.Inspect().Config
+	if !config.Singleflight || !config.AsyncSet || config.WriteBehind {
+		t.Fatalf("unexpected config flags: %+v", config)
+	}
+	wantWindow := NewExponentialRevalidation(time.Minute).Window(0)
+	if config.RevalidationWindow != wantWindow || config.RevalidationSteepness <= 0 {
+		t.Fatalf("unexpected revalidation config: window=%v steepness=%v", config.RevalidationWindow, config.RevalidationSteepness)
+	}
+	if config.MaxLoadTimeout != 3*time.Second || config.FollowerWaitTimeout != time.Second ||
+		config.ProviderGetTimeout != 100*time.Millisecond || config.ProviderSetTimeout != 0 {
+		t.Fatalf("unexpected timeouts: %+v", config)
+	}
+
+	direct := NewCache(provider, NoopCacheStorageCodec[int]{},
+		WithDirectLoader[int, CacheObject[int]](),
+		WithRevalidationStrategy[int, CacheObject[int]](NewLinearRevalidation(time.Minute)),
+	).(CacheInspector).Inspect().Config
+	if direct.Singleflight || direct.RevalidationSteepness != 0 || direct.RevalidationWindow != time.Minute {
+		t.Fatalf("unexpected direct loader config: %+v", direct)
+	}
+}
+
+func TestInspect_CountsOperations(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1000, 0)
+	provider := &testMemoryProvider[int]{items: make(map[string]CacheObject[int])}
+	cache := NewCache(provider, NoopCacheStorageCodec[int]{},
+		WithRevalidationWindow[int, CacheObject[int]](time.Minute),
+		WithClock[int, CacheObject[int]](func() time.Time { return now }),
+		WithRandomSource[int, CacheObject[int]](func() float64 { return 0 }),
+	)
+	ctx := context.Background()
+	loader := func(context.Context) (int, error) { return 1, nil }
+	loadErr := errors.New("boom")
+
+	if _, err := cache.GetOrLoad(ctx, "key", time.Hour, loader); err != nil { // miss
+		t.Fatalf("GetOrLoad() error = %v", err)
+	}
+	if _, err := cache.GetOrLoad(ctx, "key", time.Hour, loader); err != nil { // hit
+		t.Fatalf("GetOrLoad() error = %v", err)
+	}
+	now = now.Add(time.Hour - time.Second)
+	if _, err := cache.GetOrLoad(ctx, "key", time.Hour, loader); err != nil { // revalidation
+		t.Fatalf("GetOrLoad() error = %v", err)
+	}
+	failing := func(context.Context) (int, error) { return 0, loadErr }
+	if err := cache.(CacheRefresher[int]).Refresh(ctx, "key", time.Hour, failing); !errors.Is(err, loadErr) {
+		t.Fatalf("Refresh() error = %v, want %v", err, loadErr)
+	}
+	if err := cache.Delete(ctx, "key"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+
+	want := CacheStats{Gets: 3, Hits: 2, Sets: 2, Deletes: 1, Loads: 3, Revalidations: 1, LoadErrors: 1}
+	if got := cache.(CacheInspector).Inspect().Stats; got != want {
+		t.Fatalf("Stats = %+v, want %+v", got, want)
+	}
+}
+
+func TestInspect_ListsInFlightLoads(t *testing.T) {
+	t.Parallel()
+
+	provider := &testMemoryProvider[int]{items: make(map[string]CacheObject[int])}
+	cache := NewCache(provider, NoopCacheStorageCodec[int]{})
+	inspector := cache.(CacheInspector)
+	unblock := make(chan struct{})
+	loader := func(context.Context) (int, error) {
+		<-unblock
+
+		return 1, nil
+	}
+
+	var wg sync.WaitGroup
+	for _, key := range []string{"a", "b", "b", "b"} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, _ = cache.GetOrLoad(context.Background(), key, time.Minute, loader)
+		}()
+	}
+
+	want := []InFlightLoad{{Key: "b", Waiters: 3}, {Key: "a", Waiters: 1}}
+	deadline := time.After(time.Second)
+	for {
+		got := inspector.Inspect().InFlight
+		if len(got) == len(want) && got[0] == want[0] && got[1] == want[1] {
+			break
+		}
+		select {
+		case <-deadline:
+			t.Fatalf("InFlight = %+v, want %+v", got, want)
+		default:
+			time.Sleep(time.Millisecond)
+		}
+	}
+
+	close(unblock)
+	wg.Wait()
+	if got := inspector.Inspect().InFlight; len(got) != 0 {
+		t.Fatalf("InFlight = %+v after the loads finished, want none", got)
+	}
+	if stats := inspector.Inspect().Stats; stats.Loads != 2 || stats.SharedLoads != 2 {
+		t.Fatalf("Stats = %+v, want 2 loads and 2 shared loads", stats)
+	}
+}
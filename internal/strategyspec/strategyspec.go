@@ -0,0 +1,70 @@
+// Package strategyspec parses the revalidation strategy specs accepted by the
+// crema and crema-sim commands, so both read a spec the same way.
+package strategyspec
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/abema/crema"
+)
+
+// Parse parses a strategy spec of the form name[:param]:
+//
+//	none                 no early revalidation
+//	exponential:<window> crema.NewExponentialRevalidation
+//	linear:<window>      crema.NewLinearRevalidation
+//	adaptive:<window>    crema.NewAdaptiveRevalidation
+//	relative:<fraction>  crema.NewRelativeExponentialRevalidation
+//	fixed:<fraction>     crema.NewFixedPercentageRevalidation
+//	xfetch:<beta>        crema.NewMeasuredXFetchRevalidation with defaultDelta
+//
+// xfetch uses the load duration recorded in each entry as delta, and
+// defaultDelta for entries without one.
+func Parse(spec string, defaultDelta time.Duration) (crema.RevalidationStrategy, error) {
+	name, param, _ := strings.Cut(spec, ":")
+	switch name {
+	case "none":
+		return crema.NewExponentialRevalidation(0), nil
+	case "exponential", "linear", "adaptive":
+		window, err := time.ParseDuration(param)
+		if err != nil {
+			return nil, fmt.Errorf("strategy %q: invalid window: %w", spec, err)
+		}
+
+		return windowStrategy(name, window), nil
+	case "relative", "fixed", "xfetch":
+		value, err := strconv.ParseFloat(param, 64)
+		if err != nil {
+			return nil, fmt.Errorf("strategy %q: invalid parameter: %w", spec, err)
+		}
+
+		return ratioStrategy(name, value, defaultDelta), nil
+	default:
+		return nil, fmt.Errorf("unknown strategy %q", spec)
+	}
+}
+
+func windowStrategy(name string, window time.Duration) crema.RevalidationStrategy {
+	switch name {
+	case "linear":
+		return crema.NewLinearRevalidation(window)
+	case "adaptive":
+		return crema.NewAdaptiveRevalidation(window)
+	default:
+		return crema.NewExponentialRevalidation(window)
+	}
+}
+
+func ratioStrategy(name string, value float64, defaultDelta time.Duration) crema.RevalidationStrategy {
+	switch name {
+	case "fixed":
+		return crema.NewFixedPercentageRevalidation(value)
+	case "xfetch":
+		return crema.NewMeasuredXFetchRevalidation(defaultDelta, value)
+	default:
+		return crema.NewRelativeExponentialRevalidation(value)
+	}
+}
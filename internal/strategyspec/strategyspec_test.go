@@ -0,0 +1,38 @@
+package strategyspec
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	for spec, want := range map[string]string{
+		"none":            "exponential(window=0s)",
+		"linear:1m":       "linear(window=1m0s)",
+		"relative:0.1":    "relative-exponential(fraction=0.1)",
+		"fixed:0.25":      "fixed-percentage(fraction=0.25)",
+		"xfetch:2":        "measured-xfetch(default_delta=100ms, beta=2)",
+		"exponential:10m": "exponential(window=7m40.206s)",
+	} {
+		strategy, err := Parse(spec, 100*time.Millisecond)
+		if err != nil {
+			t.Fatalf("Parse(%q) error = %v", spec, err)
+		}
+		if got := fmt.Sprint(strategy); got != want {
+			t.Fatalf("Parse(%q) = %s, want %s", spec, got, want)
+		}
+	}
+}
+
+func TestParse_RejectsInvalidSpecs(t *testing.T) {
+	t.Parallel()
+
+	for _, spec := range []string{"unknown", "exponential", "exponential:soon", "relative:x"} {
+		if _, err := Parse(spec, time.Second); err == nil {
+			t.Fatalf("Parse(%q) error = nil, want an error", spec)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package crema
+
+import (
+	"context"
+	"sync"
+)
+
+// lifecycle tracks in-flight work so Close can stop accepting new work and
+// wait for the rest to drain.
+type lifecycle struct {
+	_      noCopy
+	mu     sync.RWMutex
+	closed bool
+	wg     sync.WaitGroup
+}
+
+// acquire registers a unit of work, returning false once closed.
+// Each successful acquire must be paired with release.
+func (l *lifecycle) acquire() bool {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	if l.closed {
+		return false
+	}
+	l.wg.Add(1)
+
+	return true
+}
+
+// extend registers a unit of work spawned by work that is already acquired,
+// so it cannot be refused. It must be paired with release.
+func (l *lifecycle) extend() {
+	l.wg.Add(1)
+}
+
+func (l *lifecycle) release() {
+	l.wg.Done()
+}
+
+// close stops accepting new work. It is safe to call multiple times.
+func (l *lifecycle) close() {
+	l.mu.Lock()
+	l.closed = true
+	l.mu.Unlock()
+}
+
+// wait blocks until all acquired work is released or ctx is done.
+func (l *lifecycle) wait(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		l.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,166 @@
+package crema
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCache_CloseRejectsLoadsButServesHits(t *testing.T) {
+	t.Parallel()
+
+	provider := &testMemoryProvider[int]{items: make(map[string]CacheObject[int])}
+	provider.items["hit"] = CacheObject[int]{Value: 1, ExpireAtMillis: time.Now().Add(time.Hour).UnixMilli()}
+	cache := NewCache(provider, NoopCacheStorageCodec[int]{})
+	impl := cache.(*cacheImpl[int, CacheObject[int]])
+	impl.random = fakeRandom(1)
+
+	if err := cache.(CacheCloser).Close(context.Background()); err != nil {
+		t.Fatalf("expected close to succeed, got %v", err)
+	}
+
+	value, err := cache.GetOrLoad(context.Background(), "hit", time.Minute, constLoader(2))
+	if err != nil || value != 1 {
+		t.Fatalf("expected cached value 1 after close, got value=%d err=%v", value, err)
+	}
+	if _, err := cache.GetOrLoad(context.Background(), "miss", time.Minute, constLoader(2)); !errors.Is(err, ErrCacheClosed) {
+		t.Fatalf("expected ErrCacheClosed, got %v", err)
+	}
+	if err := cache.(CacheCloser).Close(context.Background()); err != nil {
+		t.Fatalf("expected repeated close to succeed, got %v", err)
+	}
+}
+
+func TestCache_CloseWaitsForDetachedLoad(t *testing.T) {
+	t.Parallel()
+
+	provider := &testMemoryProvider[int]{items: make(map[string]CacheObject[int])}
+	cache := NewCache(provider, NoopCacheStorageCodec[int]{})
+	started := make(chan struct{})
+	release := make(chan struct{})
+	finished := make(chan struct{})
+	loader := func(context.Context) (int, error) {
+		close(started)
+		<-release
+		close(finished)
+
+		return 1, nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errCh := make(chan error, 1)
+	go func() {
+		_, err := cache.GetOrLoad(ctx, "key", time.Minute, loader)
+		errCh <- err
+	}()
+	<-started
+	cancel()
+	if err := <-errCh; !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected caller to observe cancellation, got %v", err)
+	}
+
+	closeErr := make(chan error, 1)
+	go func() {
+		closeErr <- cache.(CacheCloser).Close(context.Background())
+	}()
+	select {
+	case err := <-closeErr:
+		t.Fatalf("expected close to wait for the detached load, got %v", err)
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	close(release)
+	if err := <-closeErr; err != nil {
+		t.Fatalf("expected close to succeed, got %v", err)
+	}
+	select {
+	case <-finished:
+	default:
+		t.Fatal("expected loader to finish before close returned")
+	}
+}
+
+func TestCache_CloseCancelsLoadsAfterDeadline(t *testing.T) {
+	t.Parallel()
+
+	provider := &testMemoryProvider[int]{items: make(map[string]CacheObject[int])}
+	cache := NewCache(provider, NoopCacheStorageCodec[int]{})
+	started := make(chan struct{})
+	loader := func(ctx context.Context) (int, error) {
+		close(started)
+		<-ctx.Done()
+
+		return 0, ctx.Err()
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		_, err := cache.GetOrLoad(context.Background(), "key", time.Minute, loader)
+		errCh <- err
+	}()
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := cache.(CacheCloser).Close(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+
+	select {
+	case err := <-errCh:
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("expected in-flight load to be canceled, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for canceled load")
+	}
+}
+
+func TestCache_CloseWaitsForAsyncSet(t *testing.T) {
+	t.Parallel()
+
+	provider := newBlockingSetProvider()
+	cache := NewCache(provider, NoopCacheStorageCodec[int]{}, WithAsyncSet[int, CacheObject[int]]())
+
+	if _, err := cache.GetOrLoad(context.Background(), "key", time.Minute, constLoader(1)); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	<-provider.started
+
+	closeErr := make(chan error, 1)
+	go func() {
+		closeErr <- cache.(CacheCloser).Close(context.Background())
+	}()
+	select {
+	case err := <-closeErr:
+		t.Fatalf("expected close to wait for async set, got %v", err)
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	close(provider.release)
+	if err := <-closeErr; err != nil {
+		t.Fatalf("expected close to succeed, got %v", err)
+	}
+	if !provider.stored("key") {
+		t.Fatal("expected async set to complete before close returned")
+	}
+}
+
+func TestSingleflightLoader_RejectsLeaderAfterStop(t *testing.T) {
+	t.Parallel()
+
+	loaderImpl := newSingleflightLoader[int](NoopMetricsProvider{}, 0)
+	loaderImpl.stop()
+
+	_, leader, err := loaderImpl.load(context.Background(), "key", constLoader(1))
+	if !errors.Is(err, ErrCacheClosed) {
+		t.Fatalf("expected ErrCacheClosed, got %v", err)
+	}
+	if !leader {
+		t.Fatal("expected leader=true")
+	}
+	if err := loaderImpl.drain(context.Background()); err != nil {
+		t.Fatalf("expected drain to succeed, got %v", err)
+	}
+}
@@ -0,0 +1,209 @@
+package crema
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// ErrLoadRejected is returned when a load is shed by the loader concurrency limit.
+var ErrLoadRejected = errors.New("load rejected by concurrency limit")
+
+// LoadShedPolicy selects what GetOrLoad returns when a load is shed by a
+// concurrency or rate limit.
+type LoadShedPolicy int
+
+const (
+	// LoadShedPolicyReject returns the shedding error, ErrLoadRejected or ErrLoadRateLimited.
+	LoadShedPolicyReject LoadShedPolicy = iota
+	// LoadShedPolicyServeStale returns the cached value, even if expired, when one
+	// was found, and the shedding error otherwise.
+	LoadShedPolicyServeStale
+)
+
+// LoadConcurrencyLimit bounds concurrent singleflight leader loads.
+type LoadConcurrencyLimit struct {
+	// MaxConcurrency caps leader loads across all keys. Zero means unlimited.
+	MaxConcurrency int
+	// PrefixLimits caps leader loads per key prefix. A key uses the longest
+	// matching prefix, in addition to MaxConcurrency.
+	PrefixLimits map[string]int
+	// MaxQueueLength is the number of loads allowed to wait for a free slot.
+	// Loads beyond it are rejected immediately. Zero disables waiting.
+	MaxQueueLength int
+	// QueueTimeout bounds how long a load waits for its prefix and global slots
+	// together before it is rejected. Zero waits until the load context is done.
+	QueueTimeout time.Duration
+	// ShedPolicy selects what GetOrLoad returns for rejected loads.
+	ShedPolicy LoadShedPolicy
+}
+
+type loadSemaphore struct {
+	slots chan struct{}
+}
+
+type prefixSemaphore struct {
+	prefix    string
+	semaphore loadSemaphore
+}
+
+type loadLimiter struct {
+	global         *loadSemaphore
+	prefixes       []prefixSemaphore // sorted by descending prefix length
+	maxQueueLength int64
+	queueTimeout   time.Duration
+	waiting        atomic.Int64
+}
+
+func newLoadLimiter(limit LoadConcurrencyLimit) *loadLimiter {
+	limiter := &loadLimiter{
+		maxQueueLength: int64(limit.MaxQueueLength),
+		queueTimeout:   limit.QueueTimeout,
+	}
+	if limit.MaxConcurrency > 0 {
+		limiter.global = &loadSemaphore{slots: make(chan struct{}, limit.MaxConcurrency)}
+	}
+	for prefix, maxConcurrency := range limit.PrefixLimits {
+		if maxConcurrency <= 0 {
+			continue
+		}
+		limiter.prefixes = append(limiter.prefixes, prefixSemaphore{
+			prefix:    prefix,
+			semaphore: loadSemaphore{slots: make(chan struct{}, maxConcurrency)},
+		})
+	}
+	sort.Slice(limiter.prefixes, func(i, j int) bool {
+		return len(limiter.prefixes[i].prefix) > len(limiter.prefixes[j].prefix)
+	})
+
+	return limiter
+}
+
+// acquire reserves the prefix and global slots for key. It returns a release
+// function, the time spent waiting and ErrLoadRejected when the load is shed.
+// QueueTimeout bounds the wait for all the slots together.
+func (l *loadLimiter) acquire(ctx context.Context, key string) (func(), time.Duration, error) {
+	start := time.Now()
+	var acquired []*loadSemaphore
+	release := func() {
+		for _, semaphore := range acquired {
+			<-semaphore.slots
+		}
+	}
+	for _, semaphore := range l.semaphoresFor(key) {
+		if err := l.acquireSlot(ctx, semaphore, start); err != nil {
+			release()
+
+			return nil, time.Since(start), err
+		}
+		acquired = append(acquired, semaphore)
+	}
+
+	return release, time.Since(start), nil
+}
+
+// tryAcquire reserves the prefix and global slots for key if they are all free
+// now, without queueing. It returns a release function and whether it succeeded.
+func (l *loadLimiter) tryAcquire(key string) (func(), bool) {
+	var acquired []*loadSemaphore
+	release := func() {
+		for _, semaphore := range acquired {
+			<-semaphore.slots
+		}
+	}
+	for _, semaphore := range l.semaphoresFor(key) {
+		select {
+		case semaphore.slots <- struct{}{}:
+			acquired = append(acquired, semaphore)
+		default:
+			release()
+
+			return nil, false
+		}
+	}
+
+	return release, true
+}
+
+// semaphoresFor returns the semaphores limiting key: its longest matching
+// prefix, if any, and the global one.
+func (l *loadLimiter) semaphoresFor(key string) []*loadSemaphore {
+	var semaphores []*loadSemaphore
+	for i := range l.prefixes {
+		if strings.HasPrefix(key, l.prefixes[i].prefix) {
+			semaphores = append(semaphores, &l.prefixes[i].semaphore)
+
+			break
+		}
+	}
+	if l.global != nil {
+		semaphores = append(semaphores, l.global)
+	}
+
+	return semaphores
+}
+
+// acquireSlot reserves a slot of semaphore, waiting at most until QueueTimeout
+// after start.
+func (l *loadLimiter) acquireSlot(ctx context.Context, semaphore *loadSemaphore, start time.Time) error {
+	select {
+	case semaphore.slots <- struct{}{}:
+		return nil
+	default:
+	}
+
+	if l.waiting.Add(1) > l.maxQueueLength {
+		l.waiting.Add(-1)
+
+		return ErrLoadRejected
+	}
+	defer l.waiting.Add(-1)
+
+	var timeout <-chan time.Time
+	if l.queueTimeout > 0 {
+		timer := time.NewTimer(l.queueTimeout - time.Since(start))
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
+	select {
+	case semaphore.slots <- struct{}{}:
+		return nil
+	case <-timeout:
+		return ErrLoadRejected
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// loadSlot holds the concurrency slots admitting a load until the leader and
+// every call still running on them, such as the losing call of a hedged load,
+// are done.
+type loadSlot struct {
+	refs    atomic.Int32
+	release func()
+}
+
+func newLoadSlot(release func()) *loadSlot {
+	slot := &loadSlot{release: release}
+	slot.refs.Store(1)
+
+	return slot
+}
+
+// hold adds a holder of the slots, returning the function it calls when done.
+func (s *loadSlot) hold() func() {
+	s.refs.Add(1)
+
+	return s.done
+}
+
+// done releases a hold, freeing the slots after the last one.
+func (s *loadSlot) done() {
+	if s.refs.Add(-1) == 0 {
+		s.release()
+	}
+}
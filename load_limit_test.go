@@ -0,0 +1,187 @@
+package crema
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type loadLimitTestMetrics struct {
+	BaseMetricsProvider
+
+	rejected atomic.Int32
+	waited   atomic.Int64
+}
+
+func (m *loadLimitTestMetrics) RecordLoadQueueWait(_ context.Context, wait time.Duration) {
+	m.waited.Add(int64(wait))
+}
+
+func (m *loadLimitTestMetrics) RecordLoadRejected(context.Context) {
+	m.rejected.Add(1)
+}
+
+// startBlockedLoad starts a load for key that holds its concurrency slot until release is closed.
+func startBlockedLoad(t *testing.T, loader *singleflightLoader[int], key string, release <-chan struct{}) <-chan error {
+	t.Helper()
+
+	started := make(chan struct{})
+	errCh := make(chan error, 1)
+	go func() {
+		_, _, err := loader.load(context.Background(), key, func(context.Context) (int, error) {
+			close(started)
+			<-release
+
+			return 1, nil
+		})
+		errCh <- err
+	}()
+	<-started
+
+	return errCh
+}
+
+func TestLoadConcurrencyLimit_RejectsWhenSaturated(t *testing.T) {
+	t.Parallel()
+
+	metrics := &loadLimitTestMetrics{}
+	loaderImpl := newSingleflightLoader[int](metrics, 0)
+	loaderImpl.limiter = newLoadLimiter(LoadConcurrencyLimit{MaxConcurrency: 1})
+	release := make(chan struct{})
+	busy := startBlockedLoad(t, loaderImpl, "a", release)
+
+	_, _, err := loaderImpl.load(context.Background(), "b", constLoader(2))
+	if !errors.Is(err, ErrLoadRejected) {
+		t.Fatalf("expected ErrLoadRejected, got %v", err)
+	}
+	if rejected := metrics.rejected.Load(); rejected != 1 {
+		t.Fatalf("expected one rejection, got %d", rejected)
+	}
+
+	close(release)
+	if err := <-busy; err != nil {
+		t.Fatalf("expected blocked load to succeed, got %v", err)
+	}
+	if got, _, err := loaderImpl.load(context.Background(), "b", constLoader(2)); err != nil || got != 2 {
+		t.Fatalf("expected load to succeed once a slot is free, got value=%d err=%v", got, err)
+	}
+}
+
+func TestLoadConcurrencyLimit_QueuesUntilSlotIsFree(t *testing.T) {
+	t.Parallel()
+
+	metrics := &loadLimitTestMetrics{}
+	loaderImpl := newSingleflightLoader[int](metrics, 0)
+	loaderImpl.limiter = newLoadLimiter(LoadConcurrencyLimit{MaxConcurrency: 1, MaxQueueLength: 1})
+	release := make(chan struct{})
+	busy := startBlockedLoad(t, loaderImpl, "a", release)
+
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		close(release)
+	}()
+	got, _, err := loaderImpl.load(context.Background(), "b", constLoader(2))
+	if err != nil || got != 2 {
+		t.Fatalf("expected queued load to succeed, got value=%d err=%v", got, err)
+	}
+	if err := <-busy; err != nil {
+		t.Fatalf("expected blocked load to succeed, got %v", err)
+	}
+	if waited := time.Duration(metrics.waited.Load()); waited < 10*time.Millisecond {
+		t.Fatalf("expected queue wait to be recorded, got %v", waited)
+	}
+}
+
+func TestLoadConcurrencyLimit_QueueTimeoutRejects(t *testing.T) {
+	t.Parallel()
+
+	loaderImpl := newSingleflightLoader[int](NoopMetricsProvider{}, 0)
+	loaderImpl.limiter = newLoadLimiter(LoadConcurrencyLimit{
+		MaxConcurrency: 1,
+		MaxQueueLength: 1,
+		QueueTimeout:   10 * time.Millisecond,
+	})
+	release := make(chan struct{})
+	defer close(release)
+	startBlockedLoad(t, loaderImpl, "a", release)
+
+	if _, _, err := loaderImpl.load(context.Background(), "b", constLoader(2)); !errors.Is(err, ErrLoadRejected) {
+		t.Fatalf("expected ErrLoadRejected after queue timeout, got %v", err)
+	}
+}
+
+func TestLoadConcurrencyLimit_QueueTimeoutCoversAllSlots(t *testing.T) {
+	t.Parallel()
+
+	limiter := newLoadLimiter(LoadConcurrencyLimit{
+		MaxConcurrency: 1,
+		PrefixLimits:   map[string]int{"user:": 1},
+		MaxQueueLength: 1,
+		QueueTimeout:   100 * time.Millisecond,
+	})
+	if _, ok := limiter.tryAcquire("user:1"); !ok {
+		t.Fatal("expected the first user load to be admitted")
+	}
+	// Frees only the prefix slot, so the next load goes on to wait for the global one.
+	time.AfterFunc(80*time.Millisecond, func() { <-limiter.prefixes[0].semaphore.slots })
+
+	_, waited, err := limiter.acquire(context.Background(), "user:2")
+	if !errors.Is(err, ErrLoadRejected) {
+		t.Fatalf("expected ErrLoadRejected, got %v", err)
+	}
+	if waited >= 160*time.Millisecond {
+		t.Fatalf("expected a single queue timeout for both slots, waited %v", waited)
+	}
+}
+
+func TestLoadConcurrencyLimit_PrefixLimits(t *testing.T) {
+	t.Parallel()
+
+	loaderImpl := newSingleflightLoader[int](NoopMetricsProvider{}, 0)
+	loaderImpl.limiter = newLoadLimiter(LoadConcurrencyLimit{
+		PrefixLimits: map[string]int{"user:": 1, "user:vip:": 1},
+	})
+	release := make(chan struct{})
+	defer close(release)
+	startBlockedLoad(t, loaderImpl, "user:1", release)
+
+	if _, _, err := loaderImpl.load(context.Background(), "user:2", constLoader(2)); !errors.Is(err, ErrLoadRejected) {
+		t.Fatalf("expected prefix limit to reject, got %v", err)
+	}
+	if _, _, err := loaderImpl.load(context.Background(), "user:vip:1", constLoader(2)); err != nil {
+		t.Fatalf("expected longest prefix to use its own limit, got %v", err)
+	}
+	if _, _, err := loaderImpl.load(context.Background(), "item:1", constLoader(2)); err != nil {
+		t.Fatalf("expected unmatched key to be unlimited, got %v", err)
+	}
+}
+
+func TestLoadConcurrencyLimit_ServeStale(t *testing.T) {
+	t.Parallel()
+
+	provider := &testMemoryProvider[int]{items: make(map[string]CacheObject[int])}
+	provider.items["stale"] = CacheObject[int]{Value: 7, ExpireAtMillis: 900}
+	cache := NewCache(
+		provider,
+		NoopCacheStorageCodec[int]{},
+		WithLoadConcurrencyLimit[int, CacheObject[int]](LoadConcurrencyLimit{
+			MaxConcurrency: 1,
+			ShedPolicy:     LoadShedPolicyServeStale,
+		}),
+	)
+	impl := cache.(*cacheImpl[int, CacheObject[int]])
+	impl.now = func() time.Time { return time.UnixMilli(1000) }
+	release := make(chan struct{})
+	defer close(release)
+	startBlockedLoad(t, impl.internalLoader.(*singleflightLoader[int]), "busy", release)
+
+	value, err := cache.GetOrLoad(context.Background(), "stale", time.Minute, constLoader(8))
+	if err != nil || value != 7 {
+		t.Fatalf("expected stale value 7, got value=%d err=%v", value, err)
+	}
+	if _, err := cache.GetOrLoad(context.Background(), "missing", time.Minute, constLoader(8)); !errors.Is(err, ErrLoadRejected) {
+		t.Fatalf("expected ErrLoadRejected without a cached value, got %v", err)
+	}
+}
@@ -0,0 +1,53 @@
+package crema
+
+import "time"
+
+// LoadSource describes where a GetOrLoadResult value came from.
+type LoadSource int
+
+const (
+	// LoadSourceHit is a cached value that did not need revalidation.
+	LoadSourceHit LoadSource = iota
+	// LoadSourceMiss is a value loaded by this caller because none was cached.
+	LoadSourceMiss
+	// LoadSourceRevalidate is a value loaded by this caller to revalidate a cached one.
+	LoadSourceRevalidate
+	// LoadSourceShared is a value loaded by another caller for the same key and
+	// shared through singleflight.
+	LoadSourceShared
+	// LoadSourceStale is a cached value, possibly expired, returned because the
+	// load was shed or its wait timed out.
+	LoadSourceStale
+)
+
+// String returns the lowercase name of the source, e.g. "hit".
+func (s LoadSource) String() string {
+	switch s {
+	case LoadSourceHit:
+		return "hit"
+	case LoadSourceMiss:
+		return "miss"
+	case LoadSourceRevalidate:
+		return "revalidate"
+	case LoadSourceShared:
+		return "shared"
+	case LoadSourceStale:
+		return "stale"
+	default:
+		return "unknown"
+	}
+}
+
+// LoadResult is a value returned by GetOrLoadResult with details on how it was obtained.
+type LoadResult[V any] struct {
+	// Value is the returned value.
+	Value V
+	// Source tells whether Value was cached, loaded or shared.
+	Source LoadSource
+	// ExpireAtMillis is the absolute expiration time of Value in milliseconds
+	// since epoch. For loaded and shared values it is computed from the caller's ttl.
+	ExpireAtMillis int64
+	// LoadDuration is how long the caller waited for the load, including
+	// singleflight, limit and retry waits. It is zero for cache hits.
+	LoadDuration time.Duration
+}
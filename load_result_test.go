@@ -0,0 +1,135 @@
+package crema
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestCache_GetOrLoadResultSources(t *testing.T) {
+	t.Parallel()
+
+	provider := &testMemoryProvider[int]{items: make(map[string]CacheObject[int])}
+	provider.items["fresh"] = CacheObject[int]{Value: 1, ExpireAtMillis: 1000 + time.Hour.Milliseconds()}
+	provider.items["expired"] = CacheObject[int]{Value: 2, ExpireAtMillis: 900}
+	cache := NewCache(provider, NoopCacheStorageCodec[int]{})
+	impl := cache.(*cacheImpl[int, CacheObject[int]])
+	impl.now = func() time.Time { return time.UnixMilli(1000) }
+	impl.random = fakeRandom(1)
+
+	tests := []struct {
+		key        string
+		wantValue  int
+		wantSource LoadSource
+		wantExpire int64
+	}{
+		{key: "fresh", wantValue: 1, wantSource: LoadSourceHit, wantExpire: 1000 + time.Hour.Milliseconds()},
+		{key: "missing", wantValue: 5, wantSource: LoadSourceMiss, wantExpire: 1000 + time.Minute.Milliseconds()},
+		{key: "expired", wantValue: 5, wantSource: LoadSourceRevalidate, wantExpire: 1000 + time.Minute.Milliseconds()},
+	}
+	for _, tt := range tests {
+		result, err := cache.(CacheResultLoader[int]).GetOrLoadResult(context.Background(), tt.key, time.Minute, constLoader(5))
+		if err != nil {
+			t.Fatalf("%s: expected no error, got %v", tt.key, err)
+		}
+		if result.Value != tt.wantValue || result.Source != tt.wantSource || result.ExpireAtMillis != tt.wantExpire {
+			t.Fatalf("%s: expected value=%d source=%s expireAt=%d, got value=%d source=%s expireAt=%d",
+				tt.key, tt.wantValue, tt.wantSource, tt.wantExpire, result.Value, result.Source, result.ExpireAtMillis)
+		}
+	}
+}
+
+func TestCache_GetOrLoadResultSharedAndStale(t *testing.T) {
+	t.Parallel()
+
+	provider := &testMemoryProvider[int]{items: make(map[string]CacheObject[int])}
+	provider.items["stale"] = CacheObject[int]{Value: 7, ExpireAtMillis: 900}
+	cache := NewCache(
+		provider,
+		NoopCacheStorageCodec[int]{},
+		WithFollowerWaitTimeout[int, CacheObject[int]](10*time.Millisecond),
+	)
+	impl := cache.(*cacheImpl[int, CacheObject[int]])
+	impl.now = func() time.Time { return time.UnixMilli(1000) }
+	loaderImpl := impl.internalLoader.(*singleflightLoader[int])
+
+	release := make(chan struct{})
+	leaderErr := startBlockedLoad(t, loaderImpl, "stale", release)
+	result, err := cache.(CacheResultLoader[int]).GetOrLoadResult(context.Background(), "stale", time.Minute, constLoader(8))
+	if err != nil || result.Source != LoadSourceStale || result.Value != 7 || result.ExpireAtMillis != 900 {
+		t.Fatalf("expected stale value 7, got %+v err=%v", result, err)
+	}
+	close(release)
+	if err := <-leaderErr; err != nil {
+		t.Fatalf("expected leader load to succeed, got %v", err)
+	}
+
+	loaderImpl.followerTimeout = 0
+	release = make(chan struct{})
+	leaderErr = startBlockedLoad(t, loaderImpl, "shared", release)
+	type outcome struct {
+		result LoadResult[int]
+		err    error
+	}
+	outcomes := make(chan outcome, 1)
+	go func() {
+		result, err := cache.(CacheResultLoader[int]).GetOrLoadResult(context.Background(), "shared", time.Minute, constLoader(8))
+		outcomes <- outcome{result: result, err: err}
+	}()
+	waitForRefs(t, loaderImpl, "shared", 2)
+	close(release)
+	shared := <-outcomes
+	result, err = shared.result, shared.err
+	if err != nil || result.Source != LoadSourceShared || result.Value != 1 {
+		t.Fatalf("expected shared value 1, got %+v err=%v", result, err)
+	}
+	if err := <-leaderErr; err != nil {
+		t.Fatalf("expected leader load to succeed, got %v", err)
+	}
+	if _, ok := provider.items["shared"]; ok {
+		t.Fatal("expected a follower not to store the shared value")
+	}
+}
+
+func TestCache_GetOrLoadResultMeasuresLoadsInRealTime(t *testing.T) {
+	t.Parallel()
+
+	provider := &testMemoryProvider[int]{items: make(map[string]CacheObject[int])}
+	cache := NewCache(provider, NoopCacheStorageCodec[int]{},
+		WithClock[int, CacheObject[int]](func() time.Time { return time.UnixMilli(1000) }),
+	).(CacheResultLoader[int])
+
+	const latency = 20 * time.Millisecond
+	result, err := cache.GetOrLoadResult(context.Background(), "key", time.Minute, func(context.Context) (int, error) {
+		time.Sleep(latency)
+
+		return 1, nil
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if result.LoadDuration < latency {
+		t.Fatalf("expected load duration of at least %v with a frozen clock, got %v", latency, result.LoadDuration)
+	}
+	if got := provider.items["key"].LoadDurationMillis; got < latency.Milliseconds() {
+		t.Fatalf("expected stored load duration of at least %dms, got %dms", latency.Milliseconds(), got)
+	}
+}
+
+func TestLoadSource_String(t *testing.T) {
+	t.Parallel()
+
+	tests := map[LoadSource]string{
+		LoadSourceHit:        "hit",
+		LoadSourceMiss:       "miss",
+		LoadSourceRevalidate: "revalidate",
+		LoadSourceShared:     "shared",
+		LoadSourceStale:      "stale",
+		LoadSource(-1):       "unknown",
+	}
+	for source, want := range tests {
+		if got := source.String(); got != want {
+			t.Fatalf("expected %q, got %q", want, got)
+		}
+	}
+}
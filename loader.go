@@ -2,8 +2,12 @@ package crema
 
 import (
 	"context"
+	"errors"
 	"hash/maphash"
+	"log/slog"
+	"math/rand/v2"
 	"runtime"
+	"runtime/debug"
 	"sync"
 	"time"
 )
@@ -21,6 +25,10 @@ var (
 
 type internalLoader[V any] interface {
 	load(ctx context.Context, key string, loader CacheLoadFunc[V]) (V, bool, error)
+	// stop rejects loads that would start after it is called.
+	stop()
+	// drain waits for running loads, canceling them once ctx is done.
+	drain(ctx context.Context) error
 }
 
 type inflight[V any] struct {
@@ -37,11 +45,19 @@ type inflight[V any] struct {
 var _ internalLoader[any] = (*singleflightLoader[any])(nil)
 
 type singleflightLoader[V any] struct {
-	_              noCopy
-	shards         []singleflightShard[V]
-	inflightPool   sync.Pool
-	metrics        MetricsProvider
-	maxLoadTimeout time.Duration
+	_               noCopy
+	shards          []singleflightShard[V]
+	inflightPool    sync.Pool
+	metrics         MetricsProvider
+	maxLoadTimeout  time.Duration
+	followerTimeout time.Duration
+	limiter         *loadLimiter
+	rateLimiters    rateLimiters
+	retry           *RetryPolicy
+	hedger          *loadHedger
+	logger          *slog.Logger
+	random          func() float64 // must goroutine safe
+	lifecycle       lifecycle
 }
 
 type singleflightShard[V any] struct {
@@ -64,6 +80,8 @@ func newSingleflightLoader[V any](metrics MetricsProvider, maxLoadTimeout time.D
 		shards:         shards,
 		metrics:        metrics,
 		maxLoadTimeout: maxLoadTimeout,
+		logger:         slog.New(noopLogHandler{}),
+		random:         rand.Float64,
 		inflightPool:   sync.Pool{New: func() any { return &inflight[V]{} }},
 	}
 }
@@ -159,12 +177,24 @@ func (l *singleflightLoader[V]) releaseInflight(key string, inf *inflight[V], sh
 func (l *singleflightLoader[V]) load(ctx context.Context, key string, loader CacheLoadFunc[V]) (V, bool, error) {
 	inf, leader, shard := l.acquireInflight(ctx, key)
 	if leader {
-		go func() {
-			l.metrics.RecordLoad(ctx)
+		if l.lifecycle.acquire() {
+			go func() {
+				defer l.lifecycle.release()
+
+				v, err := l.invokeRecover(ctx, key, inf.ctx, loader)
+				l.finishInflight(inf, shard, v, err)
+			}()
+		} else {
+			var zero V
+			l.finishInflight(inf, shard, zero, ErrCacheClosed)
+		}
+	}
 
-			v, err := loader(inf.ctx)
-			l.finishInflight(inf, shard, v, err)
-		}()
+	var timeout <-chan time.Time
+	if !leader && l.followerTimeout > 0 {
+		timer := time.NewTimer(l.followerTimeout)
+		defer timer.Stop()
+		timeout = timer.C
 	}
 
 	select {
@@ -173,6 +203,14 @@ func (l *singleflightLoader[V]) load(ctx context.Context, key string, loader Cac
 		var zero V
 
 		return zero, leader, ctx.Err()
+	case <-timeout:
+		l.releaseInflight(key, inf, shard)
+		if metrics, ok := l.metrics.(FollowerMetricsProvider); ok {
+			metrics.RecordFollowerTimeout(ctx)
+		}
+		var zero V
+
+		return zero, leader, ErrFollowerTimeout
 	case <-inf.doneCh:
 	}
 	v := inf.val
@@ -188,6 +226,105 @@ func (l *singleflightLoader[V]) load(ctx context.Context, key string, loader Cac
 	return v, leader, nil
 }
 
+// invoke runs loader with loadCtx once admitted by the rate and concurrency limits,
+// retrying failures within the same concurrency admission. Metrics are recorded
+// with the leader caller's ctx.
+func (l *singleflightLoader[V]) invoke(ctx context.Context, key string, loadCtx context.Context, loader CacheLoadFunc[V]) (V, error) {
+	release, err := l.admit(ctx, key, loadCtx)
+	if err != nil {
+		var zero V
+
+		return zero, err
+	}
+	slot := newLoadSlot(release)
+	defer slot.done()
+	l.metrics.RecordLoad(ctx)
+
+	return l.loadWithRetry(ctx, key, loadCtx, slot, loader)
+}
+
+// admit waits until a load of key passes the rate and concurrency limits, or
+// loadCtx is done. It returns a function releasing the concurrency slots.
+func (l *singleflightLoader[V]) admit(ctx context.Context, key string, loadCtx context.Context) (func(), error) {
+	if err := l.rateLimiters.wait(loadCtx, key); err != nil {
+		return nil, err
+	}
+	if l.limiter == nil {
+		return func() {}, nil
+	}
+	release, waited, err := l.limiter.acquire(loadCtx, key)
+	if metrics, ok := l.metrics.(LoadLimitMetricsProvider); ok {
+		metrics.RecordLoadQueueWait(ctx, waited)
+		if errors.Is(err, ErrLoadRejected) {
+			metrics.RecordLoadRejected(ctx)
+		}
+	}
+	if err != nil {
+		l.rateLimiters.refund(key)
+
+		return nil, err
+	}
+
+	return release, nil
+}
+
+// invokeRecover runs invoke, converting a panic into a *LoadPanicError so it
+// reaches all waiters instead of crashing the process. Loader panics are
+// already converted per call; this also covers hooks such as Retryable.
+func (l *singleflightLoader[V]) invokeRecover(
+	ctx context.Context,
+	key string,
+	loadCtx context.Context,
+	loader CacheLoadFunc[V],
+) (v V, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			var zero V
+			v, err = zero, l.loadPanicError(key, r)
+		}
+	}()
+
+	return l.invoke(ctx, key, loadCtx, loader)
+}
+
+// loadPanicError logs and wraps a value recovered from a loader panic.
+// It must be called from the deferred function that recovered it.
+func (l *singleflightLoader[V]) loadPanicError(key string, r any) *LoadPanicError {
+	panicErr := &LoadPanicError{Value: r, Stack: debug.Stack()}
+	l.logger.Error("cache loader panicked",
+		slog.String("key", key),
+		slog.String("error", panicErr.Error()),
+		slog.String("stack", string(panicErr.Stack)),
+	)
+
+	return panicErr
+}
+
+func (l *singleflightLoader[V]) stop() {
+	l.lifecycle.close()
+}
+
+func (l *singleflightLoader[V]) drain(ctx context.Context) error {
+	err := l.lifecycle.wait(ctx)
+	if err != nil {
+		l.cancelInflight()
+	}
+
+	return err
+}
+
+// cancelInflight cancels the contexts of all registered loads.
+func (l *singleflightLoader[V]) cancelInflight() {
+	for i := range l.shards {
+		shard := &l.shards[i]
+		shard.mu.Lock()
+		for _, inf := range shard.inflight {
+			inf.cancel()
+		}
+		shard.mu.Unlock()
+	}
+}
+
 type directLoader[V any] struct{}
 
 var _ internalLoader[any] = directLoader[any]{}
@@ -202,3 +339,9 @@ func (directLoader[V]) load(ctx context.Context, key string, loader CacheLoadFun
 
 	return v, true, nil
 }
+
+func (directLoader[V]) stop() {}
+
+func (directLoader[V]) drain(context.Context) error {
+	return nil
+}
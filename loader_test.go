@@ -1,8 +1,11 @@
 package crema
 
 import (
+	"bytes"
 	"context"
 	"errors"
+	"log/slog"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"testing"
@@ -438,6 +441,63 @@ func TestSingleflightLoader_NewInflightNoTimeout(t *testing.T) {
 	}
 }
 
+func TestSingleflightLoader_RecoversLoaderPanic(t *testing.T) {
+	t.Parallel()
+
+	var logs bytes.Buffer
+	loaderImpl := newSingleflightLoader[int](NoopMetricsProvider{}, 0)
+	loaderImpl.logger = slog.New(slog.NewTextHandler(&logs, nil))
+	loaderImpl.limiter = newLoadLimiter(LoadConcurrencyLimit{MaxConcurrency: 1})
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	loader := func(context.Context) (int, error) {
+		close(started)
+		<-release
+		panic("boom")
+	}
+
+	errs := make(chan error, 2)
+	go func() {
+		_, _, err := loaderImpl.load(context.Background(), "key", loader)
+		errs <- err
+	}()
+	<-started
+	go func() {
+		_, _, err := loaderImpl.load(context.Background(), "key", loader)
+		errs <- err
+	}()
+	waitForRefs(t, loaderImpl, "key", 2)
+	close(release)
+
+	for i := 0; i < 2; i++ {
+		err := <-errs
+		var panicErr *LoadPanicError
+		if !errors.As(err, &panicErr) || !errors.Is(err, ErrLoadPanic) {
+			t.Fatalf("expected *LoadPanicError, got %v", err)
+		}
+		if panicErr.Value != "boom" || len(panicErr.Stack) == 0 {
+			t.Fatalf("expected panic value and stack, got value=%v stack=%d bytes", panicErr.Value, len(panicErr.Stack))
+		}
+	}
+	if !strings.Contains(logs.String(), "cache loader panicked") {
+		t.Fatalf("expected panic to be logged, got %q", logs.String())
+	}
+	if got, _, err := loaderImpl.load(context.Background(), "key", constLoader(2)); err != nil || got != 2 {
+		t.Fatalf("expected loader to recover after a panic, got value=%d err=%v", got, err)
+	}
+}
+
+func TestLoadPanicError_UnwrapsErrorValue(t *testing.T) {
+	t.Parallel()
+
+	cause := errors.New("cause")
+	err := error(&LoadPanicError{Value: cause})
+	if !errors.Is(err, cause) || !errors.Is(err, ErrLoadPanic) {
+		t.Fatalf("expected error to match both the panic value and ErrLoadPanic, got %v", err)
+	}
+}
+
 func TestDirectLoader_LoadSuccess(t *testing.T) {
 	t.Parallel()
 
@@ -504,3 +564,80 @@ func TestDirectLoader_LoadUsesContext(t *testing.T) {
 		t.Fatalf("expected value \"ok\", got %q", got)
 	}
 }
+
+type followerTestMetrics struct {
+	BaseMetricsProvider
+
+	timeouts atomic.Int32
+}
+
+func (m *followerTestMetrics) RecordFollowerTimeout(context.Context) {
+	m.timeouts.Add(1)
+}
+
+func TestSingleflightLoader_FollowerWaitTimeout(t *testing.T) {
+	t.Parallel()
+
+	metrics := &followerTestMetrics{}
+	loaderImpl := newSingleflightLoader[int](metrics, 0)
+	loaderImpl.followerTimeout = 10 * time.Millisecond
+	release := make(chan struct{})
+	leaderErr := startBlockedLoad(t, loaderImpl, "key", release)
+
+	_, leader, err := loaderImpl.load(context.Background(), "key", constLoader(2))
+	if !errors.Is(err, ErrFollowerTimeout) {
+		t.Fatalf("expected ErrFollowerTimeout, got %v", err)
+	}
+	if leader {
+		t.Fatal("expected leader=false")
+	}
+	if got := metrics.timeouts.Load(); got != 1 {
+		t.Fatalf("expected one follower timeout, got %d", got)
+	}
+
+	close(release)
+	if err := <-leaderErr; err != nil {
+		t.Fatalf("expected leader to keep loading, got %v", err)
+	}
+}
+
+func TestCache_FollowerWaitTimeoutServesStale(t *testing.T) {
+	t.Parallel()
+
+	provider := &testMemoryProvider[int]{items: make(map[string]CacheObject[int])}
+	provider.items["key"] = CacheObject[int]{Value: 7, ExpireAtMillis: 900}
+	cache := NewCache(
+		provider,
+		NoopCacheStorageCodec[int]{},
+		WithFollowerWaitTimeout[int, CacheObject[int]](10*time.Millisecond),
+	)
+	impl := cache.(*cacheImpl[int, CacheObject[int]])
+	impl.now = func() time.Time { return time.UnixMilli(1000) }
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	leaderErr := make(chan error, 1)
+	go func() {
+		_, err := cache.GetOrLoad(context.Background(), "key", time.Minute, func(context.Context) (int, error) {
+			close(started)
+			<-release
+
+			return 8, nil
+		})
+		leaderErr <- err
+	}()
+	<-started
+
+	value, err := cache.GetOrLoad(context.Background(), "key", time.Minute, constLoader(9))
+	if err != nil || value != 7 {
+		t.Fatalf("expected stale value 7, got value=%d err=%v", value, err)
+	}
+
+	close(release)
+	if err := <-leaderErr; err != nil {
+		t.Fatalf("expected leader load to succeed, got %v", err)
+	}
+	if got := provider.items["key"].Value; got != 8 {
+		t.Fatalf("expected leader to refresh the cache with 8, got %d", got)
+	}
+}
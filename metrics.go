@@ -1,6 +1,9 @@
 package crema
 
-import "context"
+import (
+	"context"
+	"time"
+)
 
 // MetricsProvider receives cache and loader events for instrumentation.
 // Implementations must be safe for concurrent use and should avoid blocking.
@@ -19,8 +22,65 @@ type MetricsProvider interface {
 	RecordLoadConcurrency(ctx context.Context, concurrency int)
 }
 
+// CircuitBreakerMetricsProvider is an optional extension of MetricsProvider
+// notified when a CircuitBreakerProvider changes state.
+type CircuitBreakerMetricsProvider interface {
+	// RecordCircuitBreakerStateChange is called when the circuit moves from one state to another.
+	RecordCircuitBreakerStateChange(ctx context.Context, from, to CircuitBreakerState)
+}
+
+// WriteBehindMetricsProvider is an optional extension of MetricsProvider
+// notified about the write-behind queue configured by WithWriteBehind.
+type WriteBehindMetricsProvider interface {
+	// RecordWriteBehindQueueDepth is called with the number of pending writes when the queue changes.
+	RecordWriteBehindQueueDepth(ctx context.Context, depth int)
+	// RecordWriteBehindDropped is called when a write is discarded because the queue is full.
+	RecordWriteBehindDropped(ctx context.Context)
+}
+
+// LoadLimitMetricsProvider is an optional extension of MetricsProvider
+// notified about the loader concurrency limit configured by WithLoadConcurrencyLimit.
+type LoadLimitMetricsProvider interface {
+	// RecordLoadQueueWait is called with the time a leader load waited for a concurrency slot.
+	RecordLoadQueueWait(ctx context.Context, wait time.Duration)
+	// RecordLoadRejected is called when a leader load is rejected by the concurrency limit.
+	RecordLoadRejected(ctx context.Context)
+}
+
+// RetryMetricsProvider is an optional extension of MetricsProvider
+// notified about load retries configured by WithLoadRetry.
+type RetryMetricsProvider interface {
+	// RecordLoadRetry is called before a failed leader load is retried, with the retry number starting at 1.
+	RecordLoadRetry(ctx context.Context, retry int)
+}
+
+// FollowerMetricsProvider is an optional extension of MetricsProvider
+// notified about follower waits bounded by WithFollowerWaitTimeout.
+type FollowerMetricsProvider interface {
+	// RecordFollowerTimeout is called when a follower stops waiting for the leader's load.
+	RecordFollowerTimeout(ctx context.Context)
+}
+
+// HedgeMetricsProvider is an optional extension of MetricsProvider
+// notified about hedged loads configured by WithLoadHedging.
+type HedgeMetricsProvider interface {
+	// RecordLoadHedge is called when a leader starts a hedged loader call.
+	RecordLoadHedge(ctx context.Context)
+	// RecordLoadHedgeWin is called when the hedged call's result is used.
+	RecordLoadHedgeWin(ctx context.Context)
+}
+
 type BaseMetricsProvider struct{}
 
+var (
+	_ CircuitBreakerMetricsProvider = BaseMetricsProvider{}
+	_ WriteBehindMetricsProvider    = BaseMetricsProvider{}
+	_ LoadLimitMetricsProvider      = BaseMetricsProvider{}
+	_ RetryMetricsProvider          = BaseMetricsProvider{}
+	_ FollowerMetricsProvider       = BaseMetricsProvider{}
+	_ HedgeMetricsProvider          = BaseMetricsProvider{}
+)
+
 func (BaseMetricsProvider) RecordCacheHit(context.Context)             {}
 func (BaseMetricsProvider) RecordCacheGet(context.Context)             {}
 func (BaseMetricsProvider) RecordCacheSet(context.Context)             {}
@@ -28,6 +88,22 @@ func (BaseMetricsProvider) RecordCacheDelete(context.Context)          {}
 func (BaseMetricsProvider) RecordLoad(context.Context)                 {}
 func (BaseMetricsProvider) RecordLoadConcurrency(context.Context, int) {}
 
+func (BaseMetricsProvider) RecordCircuitBreakerStateChange(context.Context, CircuitBreakerState, CircuitBreakerState) {
+}
+
+func (BaseMetricsProvider) RecordWriteBehindQueueDepth(context.Context, int) {}
+func (BaseMetricsProvider) RecordWriteBehindDropped(context.Context)         {}
+
+func (BaseMetricsProvider) RecordLoadQueueWait(context.Context, time.Duration) {}
+func (BaseMetricsProvider) RecordLoadRejected(context.Context)                 {}
+
+func (BaseMetricsProvider) RecordLoadRetry(context.Context, int) {}
+
+func (BaseMetricsProvider) RecordFollowerTimeout(context.Context) {}
+
+func (BaseMetricsProvider) RecordLoadHedge(context.Context)    {}
+func (BaseMetricsProvider) RecordLoadHedgeWin(context.Context) {}
+
 type NoopMetricsProvider struct {
 	BaseMetricsProvider
 }
@@ -0,0 +1,222 @@
+package crema
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrLoadRateLimited is returned when a load exceeds the loader rate limit.
+var ErrLoadRateLimited = errors.New("load rate limit exceeded")
+
+// maxIdleRateLimitPartitions is the partition count above which idle buckets are evicted.
+const maxIdleRateLimitPartitions = 1024
+
+// LoadRateLimit limits how often singleflight leaders invoke loaders, using a
+// token bucket refilled at Rate tokens per second up to Burst tokens.
+type LoadRateLimit struct {
+	// Rate is the number of loads allowed per second. Non-positive disables the limit.
+	Rate float64
+	// Burst is the bucket capacity. Values below 1 are treated as 1.
+	Burst int
+	// Partition maps a key to its bucket, e.g. the third-party API it calls.
+	// Keys share a single global bucket when nil. Its cardinality should be bounded.
+	Partition func(key string) string
+	// Wait makes loads wait for a token instead of failing fast with ErrLoadRateLimited.
+	Wait bool
+	// MaxWait bounds the wait for a token when Wait is set; loads that would wait
+	// longer fail with ErrLoadRateLimited. Zero waits until the load context is done.
+	MaxWait time.Duration
+	// ShedPolicy selects what GetOrLoad returns for rate limited loads.
+	ShedPolicy LoadShedPolicy
+}
+
+// rateLimitError is ErrLoadRateLimited carrying the shed policy of the limit
+// that rejected the load.
+type rateLimitError struct {
+	shedPolicy LoadShedPolicy
+}
+
+func (e *rateLimitError) Error() string {
+	return ErrLoadRateLimited.Error()
+}
+
+func (e *rateLimitError) Unwrap() error {
+	return ErrLoadRateLimited
+}
+
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+type rateLimiter struct {
+	_       noCopy
+	limit   LoadRateLimit
+	burst   float64
+	now     func() time.Time
+	mu      sync.Mutex
+	buckets map[string]*tokenBucket
+}
+
+func newRateLimiter(limit LoadRateLimit) *rateLimiter {
+	return &rateLimiter{
+		limit:   limit,
+		burst:   float64(max(limit.Burst, 1)),
+		now:     time.Now,
+		buckets: make(map[string]*tokenBucket),
+	}
+}
+
+// wait takes a token for key, waiting for it when configured to.
+func (r *rateLimiter) wait(ctx context.Context, key string) error {
+	partition := r.partition(key)
+	delay, ok := r.reserve(partition)
+	if !ok {
+		return &rateLimitError{shedPolicy: r.limit.ShedPolicy}
+	}
+	if delay <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		r.refund(partition)
+
+		return ctx.Err()
+	}
+}
+
+// reserve takes a token from the partition bucket and returns how long the
+// caller must wait before using it. It returns false when the token cannot be
+// taken without exceeding the configured wait.
+func (r *rateLimiter) reserve(partition string) (time.Duration, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.now()
+	bucket := r.refill(partition, now)
+	if bucket.tokens >= 1 {
+		bucket.tokens--
+
+		return 0, true
+	}
+	if !r.limit.Wait {
+		return 0, false
+	}
+
+	delay := time.Duration((1 - bucket.tokens) / r.limit.Rate * float64(time.Second))
+	if r.limit.MaxWait > 0 && delay > r.limit.MaxWait {
+		return 0, false
+	}
+	bucket.tokens--
+
+	return delay, true
+}
+
+// take takes a token for key if one is available now, without waiting.
+func (r *rateLimiter) take(key string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	bucket := r.refill(r.partition(key), r.now())
+	if bucket.tokens < 1 {
+		return false
+	}
+	bucket.tokens--
+
+	return true
+}
+
+func (r *rateLimiter) partition(key string) string {
+	if r.limit.Partition == nil {
+		return ""
+	}
+
+	return r.limit.Partition(key)
+}
+
+func (r *rateLimiter) refund(partition string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if bucket, ok := r.buckets[partition]; ok {
+		bucket.tokens = min(bucket.tokens+1, r.burst)
+	}
+}
+
+// refill returns the bucket for partition with tokens accrued up to now.
+// It must be called with r.mu held.
+func (r *rateLimiter) refill(partition string, now time.Time) *tokenBucket {
+	bucket, ok := r.buckets[partition]
+	if !ok {
+		if len(r.buckets) >= maxIdleRateLimitPartitions {
+			r.evictIdle(now)
+		}
+		bucket = &tokenBucket{tokens: r.burst, last: now}
+		r.buckets[partition] = bucket
+
+		return bucket
+	}
+
+	elapsed := now.Sub(bucket.last).Seconds()
+	if elapsed > 0 {
+		bucket.tokens = min(bucket.tokens+elapsed*r.limit.Rate, r.burst)
+		bucket.last = now
+	}
+
+	return bucket
+}
+
+// evictIdle drops buckets that have refilled completely, since a fresh bucket
+// behaves identically. It must be called with r.mu held.
+func (r *rateLimiter) evictIdle(now time.Time) {
+	for partition, bucket := range r.buckets {
+		if bucket.tokens+now.Sub(bucket.last).Seconds()*r.limit.Rate >= r.burst {
+			delete(r.buckets, partition)
+		}
+	}
+}
+
+// rateLimiters are the limits a load must pass, all of them or none.
+type rateLimiters []*rateLimiter
+
+// wait takes a token for key from every limiter, returning the tokens already
+// taken when a later limiter rejects the load.
+func (rs rateLimiters) wait(ctx context.Context, key string) error {
+	for i, r := range rs {
+		if err := r.wait(ctx, key); err != nil {
+			rs[:i].refund(key)
+
+			return err
+		}
+	}
+
+	return nil
+}
+
+// take takes a token for key from every limiter if all of them have one
+// available now, and none otherwise.
+func (rs rateLimiters) take(key string) bool {
+	for i, r := range rs {
+		if !r.take(key) {
+			rs[:i].refund(key)
+
+			return false
+		}
+	}
+
+	return true
+}
+
+// refund returns a token for key to every limiter.
+func (rs rateLimiters) refund(key string) {
+	for _, r := range rs {
+		r.refund(r.partition(key))
+	}
+}
@@ -0,0 +1,277 @@
+package crema
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newTestRateLimiter(limit LoadRateLimit) (*rateLimiter, *time.Time) {
+	limiter := newRateLimiter(limit)
+	now := time.UnixMilli(1000)
+	limiter.now = func() time.Time { return now }
+
+	return limiter, &now
+}
+
+func TestRateLimiter_FailFast(t *testing.T) {
+	t.Parallel()
+
+	limiter, now := newTestRateLimiter(LoadRateLimit{Rate: 1, Burst: 2})
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		if err := limiter.wait(ctx, "key"); err != nil {
+			t.Fatalf("expected burst token %d, got %v", i, err)
+		}
+	}
+	if err := limiter.wait(ctx, "key"); !errors.Is(err, ErrLoadRateLimited) {
+		t.Fatalf("expected ErrLoadRateLimited, got %v", err)
+	}
+
+	*now = now.Add(time.Second)
+	if err := limiter.wait(ctx, "key"); err != nil {
+		t.Fatalf("expected refilled token, got %v", err)
+	}
+}
+
+func TestRateLimiter_Partitions(t *testing.T) {
+	t.Parallel()
+
+	limiter, _ := newTestRateLimiter(LoadRateLimit{
+		Rate: 1,
+		Partition: func(key string) string {
+			return strings.SplitN(key, ":", 2)[0]
+		},
+	})
+	ctx := context.Background()
+
+	if err := limiter.wait(ctx, "a:1"); err != nil {
+		t.Fatalf("expected token for partition a, got %v", err)
+	}
+	if err := limiter.wait(ctx, "b:1"); err != nil {
+		t.Fatalf("expected token for partition b, got %v", err)
+	}
+	if err := limiter.wait(ctx, "a:2"); !errors.Is(err, ErrLoadRateLimited) {
+		t.Fatalf("expected partition a to be limited, got %v", err)
+	}
+}
+
+func TestRateLimiter_WaitsForToken(t *testing.T) {
+	t.Parallel()
+
+	limiter := newRateLimiter(LoadRateLimit{Rate: 50, Wait: true})
+	ctx := context.Background()
+
+	if err := limiter.wait(ctx, "key"); err != nil {
+		t.Fatalf("expected first token, got %v", err)
+	}
+	start := time.Now()
+	if err := limiter.wait(ctx, "key"); err != nil {
+		t.Fatalf("expected to wait for token, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 10*time.Millisecond {
+		t.Fatalf("expected to wait about 20ms for a token, waited %v", elapsed)
+	}
+}
+
+func TestRateLimiter_MaxWait(t *testing.T) {
+	t.Parallel()
+
+	limiter, _ := newTestRateLimiter(LoadRateLimit{Rate: 1, Wait: true, MaxWait: 100 * time.Millisecond})
+	ctx := context.Background()
+
+	if err := limiter.wait(ctx, "key"); err != nil {
+		t.Fatalf("expected first token, got %v", err)
+	}
+	if err := limiter.wait(ctx, "key"); !errors.Is(err, ErrLoadRateLimited) {
+		t.Fatalf("expected ErrLoadRateLimited beyond max wait, got %v", err)
+	}
+}
+
+func TestRateLimiter_WaitCanceledRefundsToken(t *testing.T) {
+	t.Parallel()
+
+	limiter, _ := newTestRateLimiter(LoadRateLimit{Rate: 1, Wait: true})
+	if err := limiter.wait(context.Background(), "key"); err != nil {
+		t.Fatalf("expected first token, got %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := limiter.wait(ctx, "key"); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context canceled, got %v", err)
+	}
+	if tokens := limiter.buckets[""].tokens; tokens != 0 {
+		t.Fatalf("expected reserved token to be refunded, got %f tokens", tokens)
+	}
+}
+
+func TestRateLimiter_EvictsIdlePartitions(t *testing.T) {
+	t.Parallel()
+
+	limiter, now := newTestRateLimiter(LoadRateLimit{
+		Rate:      1,
+		Partition: func(key string) string { return key },
+	})
+	for i := 0; i < maxIdleRateLimitPartitions; i++ {
+		_ = limiter.wait(context.Background(), strings.Repeat("k", i+1))
+	}
+	*now = now.Add(time.Second)
+	_ = limiter.wait(context.Background(), "new")
+
+	if size := len(limiter.buckets); size != 1 {
+		t.Fatalf("expected idle buckets to be evicted, got %d buckets", size)
+	}
+}
+
+func TestLoadRateLimit_FollowersShareToken(t *testing.T) {
+	t.Parallel()
+
+	loaderImpl := newSingleflightLoader[int](NoopMetricsProvider{}, 0)
+	loaderImpl.rateLimiters = rateLimiters{newRateLimiter(LoadRateLimit{Rate: 0.001})}
+
+	release := make(chan struct{})
+	started := make(chan struct{})
+	var calls atomic.Int32
+	loader := func(context.Context) (int, error) {
+		if calls.Add(1) == 1 {
+			close(started)
+		}
+		<-release
+
+		return 5, nil
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 3)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		_, _, err := loaderImpl.load(context.Background(), "key", loader)
+		errs <- err
+	}()
+	<-started
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, _, err := loaderImpl.load(context.Background(), "key", loader)
+			errs <- err
+		}()
+	}
+	waitForRefs(t, loaderImpl, "key", 3)
+	close(release)
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("expected followers to share the leader's token, got %v", err)
+		}
+	}
+	if _, _, err := loaderImpl.load(context.Background(), "other", loader); !errors.Is(err, ErrLoadRateLimited) {
+		t.Fatalf("expected next leader to be rate limited, got %v", err)
+	}
+}
+
+func TestLoadRateLimit_ServeStale(t *testing.T) {
+	t.Parallel()
+
+	provider := &testMemoryProvider[int]{items: make(map[string]CacheObject[int])}
+	provider.items["stale"] = CacheObject[int]{Value: 7, ExpireAtMillis: 900}
+	cache := NewCache(
+		provider,
+		NoopCacheStorageCodec[int]{},
+		WithLoadRateLimit[int, CacheObject[int]](LoadRateLimit{Rate: 0.001, ShedPolicy: LoadShedPolicyServeStale}),
+	)
+	impl := cache.(*cacheImpl[int, CacheObject[int]])
+	impl.now = func() time.Time { return time.UnixMilli(1000) }
+
+	if _, err := cache.GetOrLoad(context.Background(), "fresh", time.Minute, constLoader(1)); err != nil {
+		t.Fatalf("expected first load to take the only token, got %v", err)
+	}
+	value, err := cache.GetOrLoad(context.Background(), "stale", time.Minute, constLoader(8))
+	if err != nil || value != 7 {
+		t.Fatalf("expected stale value 7, got value=%d err=%v", value, err)
+	}
+}
+
+func TestLoadRateLimit_RejectedLoadRefundsEarlierLimits(t *testing.T) {
+	t.Parallel()
+
+	loaderImpl := newSingleflightLoader[int](NoopMetricsProvider{}, 0)
+	loaderImpl.rateLimiters = rateLimiters{
+		newRateLimiter(LoadRateLimit{Rate: 0.001, Burst: 2}),
+		newRateLimiter(LoadRateLimit{Rate: 0.001, Partition: func(key string) string { return key }}),
+	}
+	ctx := context.Background()
+
+	if _, _, err := loaderImpl.load(ctx, "a", constLoader(1)); err != nil {
+		t.Fatalf("expected first load of a to pass, got %v", err)
+	}
+	if _, _, err := loaderImpl.load(ctx, "a", constLoader(1)); !errors.Is(err, ErrLoadRateLimited) {
+		t.Fatalf("expected partition a to be limited, got %v", err)
+	}
+	if _, _, err := loaderImpl.load(ctx, "b", constLoader(1)); err != nil {
+		t.Fatalf("expected the global token to be refunded, got %v", err)
+	}
+	if _, _, err := loaderImpl.load(ctx, "c", constLoader(1)); !errors.Is(err, ErrLoadRateLimited) {
+		t.Fatalf("expected the global limit to be exhausted, got %v", err)
+	}
+}
+
+func TestLoadRateLimit_ShedPolicyPerLimit(t *testing.T) {
+	t.Parallel()
+
+	provider := &testMemoryProvider[int]{items: make(map[string]CacheObject[int])}
+	provider.items["stale"] = CacheObject[int]{Value: 7, ExpireAtMillis: 900}
+	cache := NewCache(
+		provider,
+		NoopCacheStorageCodec[int]{},
+		WithLoadRateLimit[int, CacheObject[int]](LoadRateLimit{Rate: 0.001, ShedPolicy: LoadShedPolicyServeStale}),
+		WithLoadRateLimit[int, CacheObject[int]](LoadRateLimit{
+			Rate:       0.001,
+			Partition:  func(key string) string { return key },
+			ShedPolicy: LoadShedPolicyReject,
+		}),
+	)
+	impl := cache.(*cacheImpl[int, CacheObject[int]])
+	impl.now = func() time.Time { return time.UnixMilli(1000) }
+
+	if _, err := cache.GetOrLoad(context.Background(), "fresh", time.Minute, constLoader(1)); err != nil {
+		t.Fatalf("expected first load to take the global token, got %v", err)
+	}
+	value, err := cache.GetOrLoad(context.Background(), "stale", time.Minute, constLoader(8))
+	if err != nil || value != 7 {
+		t.Fatalf("expected the global limit to serve stale value 7, got value=%d err=%v", value, err)
+	}
+}
+
+func waitForRefs(t *testing.T, loader *singleflightLoader[int], key string, refs int) {
+	t.Helper()
+
+	deadline := time.After(time.Second)
+	shard := loader.shardFor(key)
+	for {
+		shard.mu.Lock()
+		current := 0
+		if inf := shard.inflight[key]; inf != nil {
+			current = inf.refs
+		}
+		shard.mu.Unlock()
+		if current >= refs {
+			return
+		}
+		select {
+		case <-deadline:
+			t.Fatalf("timed out waiting for %d callers to join", refs)
+		default:
+			time.Sleep(time.Millisecond)
+		}
+	}
+}
@@ -0,0 +1,335 @@
+package crema
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"math/rand/v2"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrRefreshSchedulerStopped is returned when registering a key after Stop.
+	ErrRefreshSchedulerStopped = errors.New("refresh scheduler is stopped")
+	// ErrInvalidRefreshTTL is returned when registering a key with a non-positive TTL.
+	ErrInvalidRefreshTTL = errors.New("refresh TTL must be positive")
+)
+
+const (
+	defaultRefreshJitter         = 0.1
+	defaultRefreshMaxConcurrency = 4
+	defaultRefreshRetryInterval  = time.Second
+	// maxRefreshLeadTimeDivisor caps the lead time at half the TTL, so a key is
+	// not refreshed again right after being refreshed.
+	maxRefreshLeadTimeDivisor = 2
+)
+
+type refreshSchedulerConfig struct {
+	leadTime       time.Duration
+	jitter         float64
+	maxConcurrency int
+	retryInterval  time.Duration
+	logger         *slog.Logger
+}
+
+// RefreshSchedulerOption configures a RefreshScheduler.
+type RefreshSchedulerOption func(*refreshSchedulerConfig)
+
+// WithRefreshLeadTime sets how long before expiry keys are refreshed.
+// It defaults to the revalidation window of the stored entry, see
+// EntryRevalidationWindow, under the cache's RevalidationStrategy as reported
+// by CacheInspector, or under the default strategy for caches not implementing it.
+func WithRefreshLeadTime(duration time.Duration) RefreshSchedulerOption {
+	return func(c *refreshSchedulerConfig) {
+		if duration > 0 {
+			c.leadTime = duration
+		}
+	}
+}
+
+// WithRefreshJitter sets the fraction of the lead time by which refreshes are
+// randomly brought forward, so keys registered together do not reload together.
+// It defaults to 0.1.
+func WithRefreshJitter(jitter float64) RefreshSchedulerOption {
+	return func(c *refreshSchedulerConfig) {
+		c.jitter = min(max(jitter, 0), 1)
+	}
+}
+
+// WithRefreshMaxConcurrency caps concurrent refreshes. It defaults to 4.
+func WithRefreshMaxConcurrency(n int) RefreshSchedulerOption {
+	return func(c *refreshSchedulerConfig) {
+		if n > 0 {
+			c.maxConcurrency = n
+		}
+	}
+}
+
+// WithRefreshRetryInterval sets how long to wait before retrying a failed refresh.
+// It defaults to one second.
+func WithRefreshRetryInterval(duration time.Duration) RefreshSchedulerOption {
+	return func(c *refreshSchedulerConfig) {
+		if duration > 0 {
+			c.retryInterval = duration
+		}
+	}
+}
+
+// WithRefreshLogger sets the logger used to report failed refreshes.
+func WithRefreshLogger(logger *slog.Logger) RefreshSchedulerOption {
+	return func(c *refreshSchedulerConfig) {
+		if logger != nil {
+			c.logger = logger
+		}
+	}
+}
+
+type refreshEntry[V any] struct {
+	ttl    time.Duration
+	loader CacheLoadFunc[V]
+	timer  *time.Timer
+}
+
+// RefreshScheduler proactively refreshes registered keys shortly before they
+// expire, so rarely read but critical keys stay warm regardless of traffic.
+// Refreshes go through CacheRefresher.Refresh when the cache implements it,
+// sharing loads with concurrent GetOrLoad calls for the same key, and call the
+// loader and Cache.Set otherwise.
+type RefreshScheduler[V any, S any] struct {
+	_            noCopy
+	cache        Cache[V, S]
+	config       refreshSchedulerConfig
+	revalidation RevalidationStrategy // provides the default lead time
+	now          func() time.Time
+	random       func() float64 // must goroutine safe
+	slots        chan struct{}
+	ctx          context.Context
+	cancel       context.CancelFunc
+	lifecycle    lifecycle
+
+	mu      sync.Mutex
+	entries map[string]*refreshEntry[V]
+	stopped bool
+}
+
+// NewRefreshScheduler creates a scheduler refreshing keys registered on it
+// through cache. It runs until Stop is called. Due times are computed with the
+// cache's WithClock clock, as reported by CacheInspector, or with time.Now for
+// caches not implementing it.
+func NewRefreshScheduler[V any, S any](cache Cache[V, S], opts ...RefreshSchedulerOption) *RefreshScheduler[V, S] {
+	config := refreshSchedulerConfig{
+		jitter:         defaultRefreshJitter,
+		maxConcurrency: defaultRefreshMaxConcurrency,
+		retryInterval:  defaultRefreshRetryInterval,
+		logger:         slog.New(noopLogHandler{}),
+	}
+	for _, opt := range opts {
+		if opt == nil {
+			continue
+		}
+		opt(&config)
+	}
+
+	revalidation := NewExponentialRevalidation(defaultRevalidationWindowMilliseconds * time.Millisecond)
+	now := time.Now
+	if inspector, ok := cache.(CacheInspector); ok {
+		cacheConfig := inspector.Inspect().Config
+		if cacheConfig.RevalidationStrategy != nil {
+			revalidation = cacheConfig.RevalidationStrategy
+		}
+		if cacheConfig.Clock != nil {
+			now = cacheConfig.Clock
+		}
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &RefreshScheduler[V, S]{
+		cache:        cache,
+		config:       config,
+		revalidation: revalidation,
+		now:          now,
+		random:       rand.Float64,
+		slots:        make(chan struct{}, config.maxConcurrency),
+		ctx:          ctx,
+		cancel:       cancel,
+		entries:      make(map[string]*refreshEntry[V]),
+	}
+}
+
+// Register schedules key to be refreshed with loader and ttl before it expires,
+// replacing any previous registration. A missing key is loaded right away.
+// It returns ErrInvalidRefreshTTL when ttl is not positive.
+func (s *RefreshScheduler[V, S]) Register(key string, ttl time.Duration, loader CacheLoadFunc[V]) error {
+	if ttl <= 0 {
+		return ErrInvalidRefreshTTL
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.stopped {
+		return ErrRefreshSchedulerStopped
+	}
+	if previous, ok := s.entries[key]; ok {
+		previous.timer.Stop()
+	}
+	entry := &refreshEntry[V]{ttl: ttl, loader: loader}
+	s.entries[key] = entry
+	entry.timer = time.AfterFunc(0, func() { s.run(key, entry) })
+
+	return nil
+}
+
+// Unregister stops refreshing key. A refresh already running is not interrupted.
+func (s *RefreshScheduler[V, S]) Unregister(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if entry, ok := s.entries[key]; ok {
+		entry.timer.Stop()
+		delete(s.entries, key)
+	}
+}
+
+// Keys returns the registered keys in no particular order.
+func (s *RefreshScheduler[V, S]) Keys() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	keys := make([]string, 0, len(s.entries))
+	for key := range s.entries {
+		keys = append(keys, key)
+	}
+
+	return keys
+}
+
+// Stop cancels scheduled refreshes and waits for running ones to finish or
+// ctx to be done, canceling those still running then.
+func (s *RefreshScheduler[V, S]) Stop(ctx context.Context) error {
+	s.mu.Lock()
+	s.stopped = true
+	for _, entry := range s.entries {
+		entry.timer.Stop()
+	}
+	s.mu.Unlock()
+
+	s.lifecycle.close()
+	err := s.lifecycle.wait(ctx)
+	s.cancel()
+
+	return err
+}
+
+// run refreshes key if it is due and schedules its next run. Once the cache
+// is closed, key is unregistered instead.
+func (s *RefreshScheduler[V, S]) run(key string, entry *refreshEntry[V]) {
+	if !s.lifecycle.acquire() {
+		return
+	}
+	defer s.lifecycle.release()
+	if !s.registered(key, entry) {
+		return
+	}
+
+	select {
+	case s.slots <- struct{}{}:
+	case <-s.ctx.Done():
+		return
+	}
+	next, ok := s.refreshIfDue(key, entry)
+	<-s.slots
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.stopped || s.entries[key] != entry {
+		return
+	}
+	if !ok {
+		delete(s.entries, key)
+
+		return
+	}
+	entry.timer = time.AfterFunc(next, func() { s.run(key, entry) })
+}
+
+func (s *RefreshScheduler[V, S]) registered(key string, entry *refreshEntry[V]) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return !s.stopped && s.entries[key] == entry
+}
+
+// refreshIfDue refreshes key when it is missing or within the lead time, plus
+// the maximum jitter, of its expiry, and returns the delay until the next run.
+// It waits at least the retry interval after a refresh, so a value that is due
+// again right away is not reloaded in a loop. It returns false once the cache
+// is closed.
+func (s *RefreshScheduler[V, S]) refreshIfDue(key string, entry *refreshEntry[V]) (time.Duration, bool) {
+	value, found, err := s.cache.Get(s.ctx, key)
+	if err != nil {
+		s.config.logger.Warn("failed to get cache for refresh", slog.String("key", key), slog.String("error", err.Error()))
+
+		return s.config.retryInterval, true
+	}
+	leadTime := s.leadTime(key, entry, value)
+	maxJitter := time.Duration(s.config.jitter * float64(leadTime))
+	if found && s.now().UnixMilli() < value.ExpireAtMillis-(leadTime+maxJitter).Milliseconds() {
+		return s.delayUntilDue(value.ExpireAtMillis, leadTime), true
+	}
+
+	if err := s.refresh(key, entry); err != nil {
+		if errors.Is(err, ErrCacheClosed) {
+			s.config.logger.Warn("stopped refreshing key of closed cache", slog.String("key", key))
+
+			return 0, false
+		}
+		s.config.logger.Warn("failed to refresh cache", slog.String("key", key), slog.String("error", err.Error()))
+
+		return s.config.retryInterval, true
+	}
+	if next := s.delayUntilDue(s.now().Add(entry.ttl).UnixMilli(), leadTime); next > 0 {
+		return next, true
+	}
+
+	return s.config.retryInterval, true
+}
+
+// leadTime returns how long before expiry key is refreshed: the configured
+// lead time, or else the revalidation window of the stored value, capped to a
+// fraction of the TTL.
+func (s *RefreshScheduler[V, S]) leadTime(key string, entry *refreshEntry[V], value CacheObject[V]) time.Duration {
+	leadTime := s.config.leadTime
+	if leadTime <= 0 {
+		leadTime = EntryRevalidationWindow(s.revalidation, newRevalidationEntry(key, s.now().UnixMilli(), value, entry.ttl))
+	}
+
+	return min(leadTime, entry.ttl/maxRefreshLeadTimeDivisor)
+}
+
+// delayUntilDue returns the time until leadTime before expireAtMillis, brought
+// forward by a random fraction of the lead time up to the configured jitter.
+func (s *RefreshScheduler[V, S]) delayUntilDue(expireAtMillis int64, leadTime time.Duration) time.Duration {
+	jitter := time.Duration(s.config.jitter * s.random() * float64(leadTime))
+	due := time.UnixMilli(expireAtMillis).Add(-leadTime - jitter)
+
+	return max(due.Sub(s.now()), 0)
+}
+
+// refresh reloads key through the cache, or stores the loaded value with Set
+// when the cache does not implement CacheRefresher.
+func (s *RefreshScheduler[V, S]) refresh(key string, entry *refreshEntry[V]) error {
+	if refresher, ok := s.cache.(CacheRefresher[V]); ok {
+		return refresher.Refresh(s.ctx, key, entry.ttl, entry.loader)
+	}
+	value, err := entry.loader(s.ctx)
+	if err != nil {
+		return err
+	}
+
+	return s.cache.Set(s.ctx, key, CacheObject[V]{
+		Value:          value,
+		ExpireAtMillis: s.now().Add(entry.ttl).UnixMilli(),
+		TTLMillis:      entry.ttl.Milliseconds(),
+	})
+}
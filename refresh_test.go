@@ -0,0 +1,335 @@
+package crema
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func countingLoader(value int) (CacheLoadFunc[int], *atomic.Int32) {
+	calls := &atomic.Int32{}
+
+	return func(context.Context) (int, error) {
+		calls.Add(1)
+
+		return value, nil
+	}, calls
+}
+
+func waitForCalls(t *testing.T, calls *atomic.Int32, want int32) {
+	t.Helper()
+
+	deadline := time.After(time.Second)
+	for calls.Load() < want {
+		select {
+		case <-deadline:
+			t.Fatalf("timed out waiting for %d calls, got %d", want, calls.Load())
+		default:
+			time.Sleep(time.Millisecond)
+		}
+	}
+}
+
+func TestCache_RefreshOverwritesFreshValue(t *testing.T) {
+	t.Parallel()
+
+	provider := &testMemoryProvider[int]{items: make(map[string]CacheObject[int])}
+	provider.items["key"] = CacheObject[int]{Value: 1, ExpireAtMillis: time.Now().Add(time.Hour).UnixMilli()}
+	cache := NewCache(provider, NoopCacheStorageCodec[int]{})
+
+	if err := cache.(CacheRefresher[int]).Refresh(context.Background(), "key", time.Minute, constLoader(2)); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if got := provider.items["key"].Value; got != 2 {
+		t.Fatalf("expected refreshed value 2, got %d", got)
+	}
+}
+
+func TestRefreshScheduler_LoadsMissingKeyAndRefreshesBeforeExpiry(t *testing.T) {
+	t.Parallel()
+
+	provider := &testMemoryProvider[int]{items: make(map[string]CacheObject[int])}
+	cache := NewCache(provider, NoopCacheStorageCodec[int]{})
+	scheduler := NewRefreshScheduler[int, CacheObject[int]](cache, WithRefreshLeadTime(40*time.Millisecond), WithRefreshJitter(0))
+	defer func() { _ = scheduler.Stop(context.Background()) }()
+
+	loader, calls := countingLoader(1)
+	if err := scheduler.Register("key", 100*time.Millisecond, loader); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	waitForCalls(t, calls, 1)
+	start := time.Now()
+	waitForCalls(t, calls, 2)
+	if elapsed := time.Since(start); elapsed < 30*time.Millisecond {
+		t.Fatalf("expected refresh to wait until the lead time, refreshed after %v", elapsed)
+	}
+	value, found, err := cache.Get(context.Background(), "key")
+	if err != nil || !found || value.ExpireAtMillis <= time.Now().UnixMilli() {
+		t.Fatalf("expected a fresh cached value, got %+v found=%v err=%v", value, found, err)
+	}
+}
+
+func TestRefreshScheduler_SetsWithoutCacheRefresher(t *testing.T) {
+	t.Parallel()
+
+	provider := &testMemoryProvider[int]{items: make(map[string]CacheObject[int])}
+	// Embedding the interface hides the CacheRefresher implementation.
+	cache := struct{ Cache[int, CacheObject[int]] }{NewCache(provider, NoopCacheStorageCodec[int]{})}
+	scheduler := NewRefreshScheduler[int, CacheObject[int]](cache, WithRefreshLeadTime(time.Minute))
+	defer func() { _ = scheduler.Stop(context.Background()) }()
+
+	loader, calls := countingLoader(3)
+	if err := scheduler.Register("key", time.Hour, loader); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	waitForCalls(t, calls, 1)
+	deadline := time.After(time.Second)
+	for {
+		value, found, err := cache.Get(context.Background(), "key")
+		if err == nil && found && value.Value == 3 {
+			break
+		}
+		select {
+		case <-deadline:
+			t.Fatalf("expected the loaded value to be set, got %+v found=%v err=%v", value, found, err)
+		default:
+			time.Sleep(time.Millisecond)
+		}
+	}
+}
+
+func TestRefreshScheduler_SkipsFreshKey(t *testing.T) {
+	t.Parallel()
+
+	provider := &testMemoryProvider[int]{items: make(map[string]CacheObject[int])}
+	provider.items["key"] = CacheObject[int]{Value: 1, ExpireAtMillis: time.Now().Add(time.Hour).UnixMilli()}
+	cache := NewCache(provider, NoopCacheStorageCodec[int]{})
+	scheduler := NewRefreshScheduler[int, CacheObject[int]](cache, WithRefreshLeadTime(time.Minute))
+	defer func() { _ = scheduler.Stop(context.Background()) }()
+
+	loader, calls := countingLoader(2)
+	if err := scheduler.Register("key", time.Hour, loader); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	time.Sleep(20 * time.Millisecond)
+	if got := calls.Load(); got != 0 {
+		t.Fatalf("expected fresh key not to be refreshed, got %d loads", got)
+	}
+}
+
+func TestRefreshScheduler_UsesCacheClock(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+	provider := &testMemoryProvider[int]{items: make(map[string]CacheObject[int])}
+	provider.items["key"] = CacheObject[int]{Value: 1, ExpireAtMillis: now.Add(time.Hour).UnixMilli()}
+	cache := NewCache(provider, NoopCacheStorageCodec[int]{},
+		WithClock[int, CacheObject[int]](func() time.Time { return now }))
+	scheduler := NewRefreshScheduler[int, CacheObject[int]](cache, WithRefreshLeadTime(time.Minute))
+	defer func() { _ = scheduler.Stop(context.Background()) }()
+
+	loader, calls := countingLoader(2)
+	if err := scheduler.Register("key", time.Hour, loader); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	time.Sleep(20 * time.Millisecond)
+	if got := calls.Load(); got != 0 {
+		t.Fatalf("expected key fresh by the cache clock not to be refreshed, got %d loads", got)
+	}
+}
+
+func TestRefreshScheduler_UnregisterStopsRefreshing(t *testing.T) {
+	t.Parallel()
+
+	provider := &testMemoryProvider[int]{items: make(map[string]CacheObject[int])}
+	cache := NewCache(provider, NoopCacheStorageCodec[int]{})
+	scheduler := NewRefreshScheduler[int, CacheObject[int]](cache, WithRefreshLeadTime(5*time.Millisecond), WithRefreshJitter(0))
+	defer func() { _ = scheduler.Stop(context.Background()) }()
+
+	loader, calls := countingLoader(1)
+	if err := scheduler.Register("key", 10*time.Millisecond, loader); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	waitForCalls(t, calls, 1)
+	scheduler.Unregister("key")
+	if keys := scheduler.Keys(); len(keys) != 0 {
+		t.Fatalf("expected no registered keys, got %v", keys)
+	}
+	time.Sleep(10 * time.Millisecond)
+	settled := calls.Load()
+	time.Sleep(30 * time.Millisecond)
+	if got := calls.Load(); got != settled {
+		t.Fatalf("expected no refreshes after unregister, got %d more", got-settled)
+	}
+}
+
+func TestRefreshScheduler_MaxConcurrency(t *testing.T) {
+	t.Parallel()
+
+	provider := &testMemoryProvider[int]{items: make(map[string]CacheObject[int])}
+	cache := NewCache(provider, NoopCacheStorageCodec[int]{})
+	scheduler := NewRefreshScheduler[int, CacheObject[int]](cache, WithRefreshMaxConcurrency(1))
+	defer func() { _ = scheduler.Stop(context.Background()) }()
+
+	var running, peak, calls atomic.Int32
+	loader := func(context.Context) (int, error) {
+		current := running.Add(1)
+		defer running.Add(-1)
+		for {
+			previous := peak.Load()
+			if current <= previous || peak.CompareAndSwap(previous, current) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+		calls.Add(1)
+
+		return 1, nil
+	}
+	for _, key := range []string{"a", "b", "c"} {
+		if err := scheduler.Register(key, time.Hour, loader); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	}
+	waitForCalls(t, &calls, 3)
+	if got := peak.Load(); got != 1 {
+		t.Fatalf("expected at most one concurrent refresh, got %d", got)
+	}
+}
+
+func TestRefreshScheduler_StopWaitsAndRejectsRegistration(t *testing.T) {
+	t.Parallel()
+
+	provider := &testMemoryProvider[int]{items: make(map[string]CacheObject[int])}
+	cache := NewCache(provider, NoopCacheStorageCodec[int]{})
+	scheduler := NewRefreshScheduler[int, CacheObject[int]](cache)
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	if err := scheduler.Register("key", time.Hour, func(context.Context) (int, error) {
+		close(started)
+		<-release
+
+		return 1, nil
+	}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	<-started
+
+	stopErr := make(chan error, 1)
+	go func() {
+		stopErr <- scheduler.Stop(context.Background())
+	}()
+	select {
+	case err := <-stopErr:
+		t.Fatalf("expected stop to wait for the running refresh, got %v", err)
+	case <-time.After(20 * time.Millisecond):
+	}
+	close(release)
+	if err := <-stopErr; err != nil {
+		t.Fatalf("expected stop to succeed, got %v", err)
+	}
+	if err := scheduler.Register("other", time.Hour, constLoader(1)); !errors.Is(err, ErrRefreshSchedulerStopped) {
+		t.Fatalf("expected ErrRefreshSchedulerStopped, got %v", err)
+	}
+}
+
+func TestRefreshScheduler_RejectsNonPositiveTTL(t *testing.T) {
+	t.Parallel()
+
+	provider := &testMemoryProvider[int]{items: make(map[string]CacheObject[int])}
+	scheduler := NewRefreshScheduler[int, CacheObject[int]](NewCache(provider, NoopCacheStorageCodec[int]{}))
+	defer func() { _ = scheduler.Stop(context.Background()) }()
+
+	for _, ttl := range []time.Duration{0, -time.Second} {
+		if err := scheduler.Register("key", ttl, constLoader(1)); !errors.Is(err, ErrInvalidRefreshTTL) {
+			t.Fatalf("ttl %v: expected ErrInvalidRefreshTTL, got %v", ttl, err)
+		}
+	}
+	if keys := scheduler.Keys(); len(keys) != 0 {
+		t.Fatalf("expected no registered keys, got %v", keys)
+	}
+}
+
+func TestRefreshScheduler_WaitsRetryIntervalWhenDueRightAfterRefresh(t *testing.T) {
+	t.Parallel()
+
+	provider := &testMemoryProvider[int]{items: make(map[string]CacheObject[int])}
+	cache := NewCache(provider, NoopCacheStorageCodec[int]{})
+	scheduler := NewRefreshScheduler[int, CacheObject[int]](cache,
+		WithRefreshLeadTime(time.Hour), WithRefreshJitter(1), WithRefreshRetryInterval(time.Minute))
+	defer func() { _ = scheduler.Stop(context.Background()) }()
+	now := time.UnixMilli(1000)
+	scheduler.now = func() time.Time { return now }
+	scheduler.random = func() float64 { return 1 }
+
+	// The lead time is capped at half the TTL, and the full jitter brings the
+	// next refresh forward by the other half.
+	next, ok := scheduler.refreshIfDue("key", &refreshEntry[int]{ttl: time.Second, loader: constLoader(1)})
+	if !ok || next != time.Minute {
+		t.Fatalf("expected the retry interval, got next=%v ok=%v", next, ok)
+	}
+}
+
+func TestRefreshScheduler_StopsRefreshingKeysOfClosedCache(t *testing.T) {
+	t.Parallel()
+
+	provider := &testMemoryProvider[int]{items: make(map[string]CacheObject[int])}
+	cache := NewCache(provider, NoopCacheStorageCodec[int]{})
+	if err := cache.(CacheCloser).Close(context.Background()); err != nil {
+		t.Fatalf("expected close to succeed, got %v", err)
+	}
+	scheduler := NewRefreshScheduler[int, CacheObject[int]](cache, WithRefreshRetryInterval(time.Millisecond))
+	defer func() { _ = scheduler.Stop(context.Background()) }()
+
+	if err := scheduler.Register("key", time.Hour, constLoader(1)); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	deadline := time.After(time.Second)
+	for len(scheduler.Keys()) != 0 {
+		select {
+		case <-deadline:
+			t.Fatal("expected the key to be unregistered once the cache is closed")
+		default:
+			time.Sleep(time.Millisecond)
+		}
+	}
+}
+
+func TestRefreshScheduler_LeadTimeFromCacheStrategy(t *testing.T) {
+	t.Parallel()
+
+	provider := &testMemoryProvider[int]{items: make(map[string]CacheObject[int])}
+	cache := NewCache(provider, NoopCacheStorageCodec[int]{},
+		WithRevalidationStrategy[int, CacheObject[int]](NewLinearRevalidation(time.Minute)))
+	if got := NewRefreshScheduler[int, CacheObject[int]](cache).revalidation.Window(time.Hour); got != time.Minute {
+		t.Fatalf("expected the cache's window of 1m, got %v", got)
+	}
+
+	// Embedding the interface hides the CacheInspector implementation.
+	wrapped := struct{ Cache[int, CacheObject[int]] }{cache}
+	want := NewExponentialRevalidation(defaultRevalidationWindowMilliseconds * time.Millisecond).Window(time.Hour)
+	if got := NewRefreshScheduler[int, CacheObject[int]](wrapped).revalidation.Window(time.Hour); got != want {
+		t.Fatalf("expected the default window of %v, got %v", want, got)
+	}
+}
+
+func TestRefreshScheduler_LeadTimeFromStoredLoadDuration(t *testing.T) {
+	t.Parallel()
+
+	provider := &testMemoryProvider[int]{items: make(map[string]CacheObject[int])}
+	cache := NewCache(provider, NoopCacheStorageCodec[int]{},
+		WithRevalidationStrategy[int, CacheObject[int]](NewMeasuredXFetchRevalidation(0, 1)))
+	scheduler := NewRefreshScheduler[int, CacheObject[int]](cache)
+	entry := &refreshEntry[int]{ttl: time.Hour}
+
+	if got := scheduler.leadTime("key", entry, CacheObject[int]{}); got != 0 {
+		t.Fatalf("expected no lead time without a recorded load duration, got %v", got)
+	}
+	want := xfetchWindow(time.Second, 1)
+	if got := scheduler.leadTime("key", entry, CacheObject[int]{LoadDurationMillis: 1000}); got != want {
+		t.Fatalf("expected the window of the recorded load duration %v, got %v", want, got)
+	}
+}
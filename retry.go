@@ -0,0 +1,110 @@
+package crema
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+)
+
+// maxRetryBackoff bounds the wait between attempts, also without a MaxBackoff,
+// so doubling and jittering it cannot overflow time.Duration.
+const maxRetryBackoff = time.Duration(1) << 62
+
+// RetryPolicy configures how singleflight leaders retry failed loads.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of loader invocations, including the first.
+	// Values below 2 disable retries.
+	MaxAttempts int
+	// BaseBackoff is the wait before the first retry. It doubles on each further retry.
+	BaseBackoff time.Duration
+	// MaxBackoff caps the wait between attempts. Zero means uncapped.
+	MaxBackoff time.Duration
+	// Jitter randomly shortens each wait by up to this fraction, in [0, 1].
+	Jitter float64
+	// Retryable reports whether a load error should be retried. When nil, every
+	// error except context cancellation and deadline expiry is retried. Loader
+	// panics, reported as *LoadPanicError, are never retried.
+	Retryable func(err error) bool
+}
+
+// backoff returns the wait before the given retry, starting at 1.
+func (p *RetryPolicy) backoff(retry int, random func() float64) time.Duration {
+	maxBackoff := maxRetryBackoff
+	if p.MaxBackoff > 0 {
+		maxBackoff = min(p.MaxBackoff, maxRetryBackoff)
+	}
+	delay := min(p.BaseBackoff, maxBackoff)
+	for i := 1; i < retry && delay < maxBackoff; i++ {
+		if delay > maxBackoff/2 {
+			delay = maxBackoff
+		} else {
+			delay *= 2
+		}
+	}
+	jitter := min(max(p.Jitter, 0), 1)
+
+	return time.Duration(float64(delay) * (1 - jitter*random()))
+}
+
+func (p *RetryPolicy) retryable(err error) bool {
+	if errors.Is(err, ErrLoadPanic) {
+		return false
+	}
+	if p.Retryable != nil {
+		return p.Retryable(err)
+	}
+
+	return !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+}
+
+// loadWithRetry runs loader with loadCtx, retrying retryable failures per the
+// configured RetryPolicy until loadCtx is done. Each retry takes a token from
+// every rate limiter without waiting. Once loadCtx is done or a retry is rate
+// limited, the last load error is returned.
+func (l *singleflightLoader[V]) loadWithRetry(
+	ctx context.Context,
+	key string,
+	loadCtx context.Context,
+	slot *loadSlot,
+	loader CacheLoadFunc[V],
+) (V, error) {
+	v, err := l.loadHedged(ctx, key, loadCtx, slot, loader)
+	if l.retry == nil {
+		return v, err
+	}
+
+	for retry := 1; err != nil && retry < l.retry.MaxAttempts && l.retry.retryable(err); retry++ {
+		backoff := l.retry.backoff(retry, l.random)
+		l.logger.Warn("retrying cache load",
+			slog.String("key", key),
+			slog.Int("retry", retry),
+			slog.Duration("backoff", backoff),
+			slog.String("error", err.Error()),
+		)
+		if metrics, ok := l.metrics.(RetryMetricsProvider); ok {
+			metrics.RecordLoadRetry(ctx, retry)
+		}
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-timer.C:
+		case <-loadCtx.Done():
+			timer.Stop()
+
+			return v, err
+		}
+		if !l.rateLimiters.take(key) {
+			l.logger.Warn("giving up cache load retries: rate limited",
+				slog.String("key", key),
+				slog.Int("retry", retry),
+				slog.String("error", err.Error()),
+			)
+
+			return v, err
+		}
+		v, err = l.loadHedged(ctx, key, loadCtx, slot, loader)
+	}
+
+	return v, err
+}
@@ -0,0 +1,234 @@
+package crema
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"math"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type retryTestMetrics struct {
+	BaseMetricsProvider
+
+	retries atomic.Int32
+}
+
+func (m *retryTestMetrics) RecordLoadRetry(context.Context, int) {
+	m.retries.Add(1)
+}
+
+// failingLoader fails the first failures calls with err and then returns value.
+func failingLoader(failures int32, err error, value int) (CacheLoadFunc[int], *atomic.Int32) {
+	calls := &atomic.Int32{}
+
+	return func(context.Context) (int, error) {
+		if calls.Add(1) <= failures {
+			return 0, err
+		}
+
+		return value, nil
+	}, calls
+}
+
+func TestRetryPolicy_Backoff(t *testing.T) {
+	t.Parallel()
+
+	policy := RetryPolicy{BaseBackoff: 10 * time.Millisecond, MaxBackoff: 50 * time.Millisecond, Jitter: 0.5}
+	tests := []struct {
+		retry  int
+		random float64
+		want   time.Duration
+	}{
+		{retry: 1, random: 0, want: 10 * time.Millisecond},
+		{retry: 2, random: 0, want: 20 * time.Millisecond},
+		{retry: 3, random: 0, want: 40 * time.Millisecond},
+		{retry: 4, random: 0, want: 50 * time.Millisecond},
+		{retry: 100, random: 0, want: 50 * time.Millisecond},
+		{retry: 2, random: 1, want: 10 * time.Millisecond},
+	}
+	for _, tt := range tests {
+		if got := policy.backoff(tt.retry, fakeRandom(tt.random)); got != tt.want {
+			t.Fatalf("backoff(%d, %v) = %v, want %v", tt.retry, tt.random, got, tt.want)
+		}
+	}
+}
+
+func TestRetryPolicy_BackoffDoesNotOverflow(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]RetryPolicy{
+		"uncapped":        {BaseBackoff: time.Second},
+		"uncapped jitter": {BaseBackoff: time.Second, Jitter: 0.5},
+		"huge cap":        {BaseBackoff: time.Second, MaxBackoff: math.MaxInt64},
+	}
+	for name, policy := range tests {
+		for _, retry := range []int{40, 63, 64, 100, math.MaxInt32} {
+			got := policy.backoff(retry, fakeRandom(0))
+			if got <= 0 || got > maxRetryBackoff {
+				t.Fatalf("%s: backoff(%d) = %v, want in (0, %v]", name, retry, got, maxRetryBackoff)
+			}
+		}
+	}
+}
+
+func TestLoadRetry_RetriesUntilSuccess(t *testing.T) {
+	t.Parallel()
+
+	metrics := &retryTestMetrics{}
+	var logs bytes.Buffer
+	provider := &testMemoryProvider[int]{items: make(map[string]CacheObject[int])}
+	cache := NewCache(
+		provider,
+		NoopCacheStorageCodec[int]{},
+		WithMetricsProvider[int, CacheObject[int]](metrics),
+		WithLogger[int, CacheObject[int]](slog.New(slog.NewTextHandler(&logs, nil))),
+		WithLoadRetry[int, CacheObject[int]](RetryPolicy{MaxAttempts: 3, BaseBackoff: time.Millisecond}),
+	)
+
+	loader, calls := failingLoader(2, errors.New("transient"), 5)
+	value, err := cache.GetOrLoad(context.Background(), "key", time.Minute, loader)
+	if err != nil || value != 5 {
+		t.Fatalf("expected value 5 after retries, got value=%d err=%v", value, err)
+	}
+	if got := calls.Load(); got != 3 {
+		t.Fatalf("expected 3 loader calls, got %d", got)
+	}
+	if got := metrics.retries.Load(); got != 2 {
+		t.Fatalf("expected 2 recorded retries, got %d", got)
+	}
+	if got := strings.Count(logs.String(), "retrying cache load"); got != 2 {
+		t.Fatalf("expected 2 retry logs, got %d: %s", got, logs.String())
+	}
+}
+
+func TestLoadRetry_StopsAfterMaxAttempts(t *testing.T) {
+	t.Parallel()
+
+	loaderImpl := newSingleflightLoader[int](NoopMetricsProvider{}, 0)
+	loaderImpl.retry = &RetryPolicy{MaxAttempts: 2}
+	errTransient := errors.New("transient")
+
+	loader, calls := failingLoader(5, errTransient, 5)
+	if _, _, err := loaderImpl.load(context.Background(), "key", loader); !errors.Is(err, errTransient) {
+		t.Fatalf("expected last load error, got %v", err)
+	}
+	if got := calls.Load(); got != 2 {
+		t.Fatalf("expected 2 loader calls, got %d", got)
+	}
+}
+
+func TestLoadRetry_TakesRateLimitTokens(t *testing.T) {
+	t.Parallel()
+
+	provider := &testMemoryProvider[int]{items: make(map[string]CacheObject[int])}
+	cache := NewCache(
+		provider,
+		NoopCacheStorageCodec[int]{},
+		WithLoadRetry[int, CacheObject[int]](RetryPolicy{MaxAttempts: 5}),
+		WithLoadRateLimit[int, CacheObject[int]](LoadRateLimit{Rate: 0.001, Burst: 2}),
+	)
+	errTransient := errors.New("transient")
+
+	loader, calls := failingLoader(5, errTransient, 5)
+	if _, err := cache.GetOrLoad(context.Background(), "key", time.Minute, loader); !errors.Is(err, errTransient) {
+		t.Fatalf("expected last load error, got %v", err)
+	}
+	if got := calls.Load(); got != 2 {
+		t.Fatalf("expected the retries to stop at the rate limit after 2 calls, got %d", got)
+	}
+	if _, err := cache.GetOrLoad(context.Background(), "other", time.Minute, constLoader(1)); !errors.Is(err, ErrLoadRateLimited) {
+		t.Fatalf("expected the retry to have taken the last token, got %v", err)
+	}
+}
+
+func TestLoadRetry_SkipsNonRetryableErrors(t *testing.T) {
+	t.Parallel()
+
+	errPermanent := errors.New("permanent")
+	loaderImpl := newSingleflightLoader[int](NoopMetricsProvider{}, 0)
+	loaderImpl.retry = &RetryPolicy{
+		MaxAttempts: 3,
+		Retryable:   func(err error) bool { return !errors.Is(err, errPermanent) },
+	}
+
+	loader, calls := failingLoader(5, errPermanent, 5)
+	if _, _, err := loaderImpl.load(context.Background(), "key", loader); !errors.Is(err, errPermanent) {
+		t.Fatalf("expected permanent error, got %v", err)
+	}
+	if got := calls.Load(); got != 1 {
+		t.Fatalf("expected a single loader call, got %d", got)
+	}
+}
+
+func TestLoadRetry_BoundedByMaxLoadTimeout(t *testing.T) {
+	t.Parallel()
+
+	errTransient := errors.New("transient")
+	loaderImpl := newSingleflightLoader[int](NoopMetricsProvider{}, 20*time.Millisecond)
+	loaderImpl.retry = &RetryPolicy{MaxAttempts: 10, BaseBackoff: time.Second}
+
+	loader, calls := failingLoader(10, errTransient, 5)
+	start := time.Now()
+	if _, _, err := loaderImpl.load(context.Background(), "key", loader); !errors.Is(err, errTransient) {
+		t.Fatalf("expected last load error, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Fatalf("expected retries to stop at the load timeout, took %v", elapsed)
+	}
+	if got := calls.Load(); got != 1 {
+		t.Fatalf("expected a single loader call before the timeout, got %d", got)
+	}
+}
+
+func TestLoadRetry_FollowersShareRetrySequence(t *testing.T) {
+	t.Parallel()
+
+	loaderImpl := newSingleflightLoader[int](NoopMetricsProvider{}, 0)
+	loaderImpl.retry = &RetryPolicy{MaxAttempts: 3, BaseBackoff: 50 * time.Millisecond}
+
+	started := make(chan struct{})
+	var once sync.Once
+	var calls atomic.Int32
+	loader := func(context.Context) (int, error) {
+		once.Do(func() { close(started) })
+		if calls.Add(1) < 3 {
+			return 0, errors.New("transient")
+		}
+
+		return 5, nil
+	}
+
+	var wg sync.WaitGroup
+	results := make(chan error, 3)
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			value, _, err := loaderImpl.load(context.Background(), "key", loader)
+			if err == nil && value != 5 {
+				err = errors.New("unexpected value")
+			}
+			results <- err
+		}()
+		if i == 0 {
+			<-started
+		}
+	}
+	wg.Wait()
+	close(results)
+
+	for err := range results {
+		if err != nil {
+			t.Fatalf("expected every caller to get the retried value, got %v", err)
+		}
+	}
+	if got := calls.Load(); got != 3 {
+		t.Fatalf("expected one shared retry sequence of 3 calls, got %d", got)
+	}
+}
@@ -0,0 +1,289 @@
+package crema
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+const (
+	defaultXFetchBeta = 1.0
+	// xfetchWindowProbability is the revalidation probability at the edge of
+	// the window reported by XFetch, whose probability never reaches zero.
+	xfetchWindowProbability = 0.001
+)
+
+// RevalidationEntry describes a cached entry whose early revalidation is considered.
+type RevalidationEntry struct {
+	// Key is the cache key of the entry.
+	Key string
+	// NowMillis is the current time in milliseconds since epoch.
+	NowMillis int64
+	// ExpireAtMillis is the absolute expiration time in milliseconds since epoch.
+	ExpireAtMillis int64
+	// TTL is the time to live the entry was stored with, from
+	// CacheObject.TTLMillis, or else the one requested by the caller.
+	// It is zero when unknown.
+	TTL time.Duration
+	// LoadDuration is how long the stored value took to load, from
+	// CacheObject.LoadDurationMillis. It is zero when unknown.
+	LoadDuration time.Duration
+}
+
+// RemainingMillis returns the time left until expiry in milliseconds.
+func (e RevalidationEntry) RemainingMillis() int64 {
+	return e.ExpireAtMillis - e.NowMillis
+}
+
+// RevalidationStrategy decides how likely an unexpired entry is to be
+// revalidated early. Expired entries are always revalidated.
+// Implementations must be safe for concurrent use. The built-in strategies
+// also implement fmt.Stringer, naming the strategy and its parameters.
+type RevalidationStrategy interface {
+	// Probability returns the probability, in [0, 1], that entry is revalidated now.
+	Probability(entry RevalidationEntry) float64
+	// Window returns how long before expiry revalidation may start for entries
+	// with the given ttl.
+	Window(ttl time.Duration) time.Duration
+}
+
+// RevalidationObserver is implemented by strategies learning from the traffic
+// of each key, such as NewAdaptiveRevalidation. Caches call ObserveRequest for
+// every read of a cached entry, so Probability stays free of side effects and
+// can be called to inspect entries without skewing what was learned.
+// random is the cache random source set by WithRandomSource.
+type RevalidationObserver interface {
+	ObserveRequest(entry RevalidationEntry, random func() float64)
+}
+
+// RevalidationEntryWindow is implemented by strategies whose window depends on
+// more of an entry than its TTL, such as NewMeasuredXFetchRevalidation, whose
+// window follows the recorded load duration.
+type RevalidationEntryWindow interface {
+	// EntryWindow returns how long before expiry revalidation may start for entry.
+	EntryWindow(entry RevalidationEntry) time.Duration
+}
+
+// EntryRevalidationWindow returns how long before expiry strategy may start
+// revalidating entry, using EntryWindow when strategy implements
+// RevalidationEntryWindow and Window(entry.TTL) otherwise.
+func EntryRevalidationWindow(strategy RevalidationStrategy, entry RevalidationEntry) time.Duration {
+	if windower, ok := strategy.(RevalidationEntryWindow); ok {
+		return windower.EntryWindow(entry)
+	}
+
+	return strategy.Window(entry.TTL)
+}
+
+type exponentialRevalidation struct {
+	steepness    float64
+	windowMillis int64
+}
+
+// NewExponentialRevalidation returns the default strategy, revalidating with
+// probability p(t)=1-exp(-k*t) within the window, where t is the remaining
+// time and k is set so that p reaches 0.999 at the target window.
+// A zero window disables early revalidation and a negative one uses the default.
+func NewExponentialRevalidation(window time.Duration) RevalidationStrategy {
+	steepness, windowMillis := calculateSteepnessAndRevalidationWindow(window.Milliseconds())
+
+	return &exponentialRevalidation{steepness: steepness, windowMillis: windowMillis}
+}
+
+func (r *exponentialRevalidation) Probability(entry RevalidationEntry) float64 {
+	remainMillis := entry.RemainingMillis()
+	if remainMillis > r.windowMillis {
+		return 0
+	}
+
+	return 1.0 - math.Exp(-r.steepness*float64(remainMillis))
+}
+
+func (r *exponentialRevalidation) Window(time.Duration) time.Duration {
+	return time.Duration(r.windowMillis) * time.Millisecond
+}
+
+func (r *exponentialRevalidation) String() string {
+	return fmt.Sprintf("exponential(window=%s)", r.Window(0))
+}
+
+type relativeExponentialRevalidation struct {
+	fraction float64
+}
+
+// NewRelativeExponentialRevalidation returns the exponential strategy with a
+// target window of fraction of each entry's TTL instead of a fixed duration,
+// so short and long lived entries spend the same share of their life in the
+// window. Entries with an unknown TTL are only revalidated once expired.
+func NewRelativeExponentialRevalidation(fraction float64) RevalidationStrategy {
+	return &relativeExponentialRevalidation{fraction: min(max(fraction, 0), 1)}
+}
+
+func (r *relativeExponentialRevalidation) Probability(entry RevalidationEntry) float64 {
+	return r.exponential(entry.TTL).Probability(entry)
+}
+
+func (r *relativeExponentialRevalidation) Window(ttl time.Duration) time.Duration {
+	return r.exponential(ttl).Window(ttl)
+}
+
+func (r *relativeExponentialRevalidation) String() string {
+	return fmt.Sprintf("relative-exponential(fraction=%g)", r.fraction)
+}
+
+// exponential returns the exponential strategy for entries with the given ttl.
+func (r *relativeExponentialRevalidation) exponential(ttl time.Duration) *exponentialRevalidation {
+	window := time.Duration(r.fraction * float64(max(ttl, 0)))
+	steepness, windowMillis := calculateSteepnessAndRevalidationWindow(window.Milliseconds())
+
+	return &exponentialRevalidation{steepness: steepness, windowMillis: windowMillis}
+}
+
+type xfetchRevalidation struct {
+	delta time.Duration
+	beta  float64
+}
+
+// NewXFetchRevalidation returns the XFetch strategy from "Optimal Probabilistic
+// Cache Stampede Prevention" (Vattani et al.), which revalidates when
+// now - delta*beta*ln(rand) >= expiry. delta is the time a recomputation
+// takes, so expensive values are revalidated earlier; beta > 1 favors earlier
+// revalidation and defaults to 1 when non-positive.
+func NewXFetchRevalidation(delta time.Duration, beta float64) RevalidationStrategy {
+	if beta <= 0 {
+		beta = defaultXFetchBeta
+	}
+
+	return &xfetchRevalidation{delta: delta, beta: beta}
+}
+
+func (r *xfetchRevalidation) Probability(entry RevalidationEntry) float64 {
+	return xfetchProbability(entry.RemainingMillis(), r.delta, r.beta)
+}
+
+func (r *xfetchRevalidation) Window(time.Duration) time.Duration {
+	return xfetchWindow(r.delta, r.beta)
+}
+
+func (r *xfetchRevalidation) String() string {
+	return fmt.Sprintf("xfetch(delta=%s, beta=%g)", r.delta, r.beta)
+}
+
+// xfetchProbability returns P(-delta*beta*ln(rand) >= remaining) for a uniform rand.
+func xfetchProbability(remainMillis int64, delta time.Duration, beta float64) float64 {
+	scale := float64(delta.Milliseconds()) * beta
+	if scale <= 0 {
+		return 0
+	}
+
+	return math.Exp(-float64(remainMillis) / scale)
+}
+
+// xfetchWindow returns the remaining time at which the XFetch probability drops
+// to xfetchWindowProbability.
+func xfetchWindow(delta time.Duration, beta float64) time.Duration {
+	return time.Duration(-math.Log(xfetchWindowProbability) * beta * float64(delta))
+}
+
+type measuredXFetchRevalidation struct {
+	defaultDelta time.Duration
+	beta         float64
+}
+
+// NewMeasuredXFetchRevalidation returns the XFetch strategy using the load
+// duration recorded in each entry as delta, so expensive-to-compute keys are
+// revalidated earlier than cheap ones. defaultDelta is used for entries with
+// no recorded duration, and beta defaults to 1 when non-positive.
+func NewMeasuredXFetchRevalidation(defaultDelta time.Duration, beta float64) RevalidationStrategy {
+	if beta <= 0 {
+		beta = defaultXFetchBeta
+	}
+
+	return &measuredXFetchRevalidation{defaultDelta: defaultDelta, beta: beta}
+}
+
+var _ RevalidationEntryWindow = (*measuredXFetchRevalidation)(nil)
+
+func (r *measuredXFetchRevalidation) Probability(entry RevalidationEntry) float64 {
+	return xfetchProbability(entry.RemainingMillis(), r.delta(entry), r.beta)
+}
+
+// Window returns the window for entries loading in defaultDelta, since the
+// actual load durations are only known per entry. See EntryWindow.
+func (r *measuredXFetchRevalidation) Window(time.Duration) time.Duration {
+	return xfetchWindow(r.defaultDelta, r.beta)
+}
+
+// EntryWindow returns the window for the load duration recorded in entry.
+func (r *measuredXFetchRevalidation) EntryWindow(entry RevalidationEntry) time.Duration {
+	return xfetchWindow(r.delta(entry), r.beta)
+}
+
+func (r *measuredXFetchRevalidation) String() string {
+	return fmt.Sprintf("measured-xfetch(default_delta=%s, beta=%g)", r.defaultDelta, r.beta)
+}
+
+// delta returns the recorded load duration of entry, or defaultDelta when unknown.
+func (r *measuredXFetchRevalidation) delta(entry RevalidationEntry) time.Duration {
+	if entry.LoadDuration > 0 {
+		return entry.LoadDuration
+	}
+
+	return r.defaultDelta
+}
+
+type linearRevalidation struct {
+	window time.Duration
+}
+
+// NewLinearRevalidation returns a strategy whose probability rises linearly
+// from 0 at the start of the window to 1 at expiry.
+func NewLinearRevalidation(window time.Duration) RevalidationStrategy {
+	return &linearRevalidation{window: max(window, 0)}
+}
+
+func (r *linearRevalidation) Probability(entry RevalidationEntry) float64 {
+	windowMillis := r.window.Milliseconds()
+	remainMillis := entry.RemainingMillis()
+	if windowMillis <= 0 || remainMillis >= windowMillis {
+		return 0
+	}
+
+	return 1 - float64(remainMillis)/float64(windowMillis)
+}
+
+func (r *linearRevalidation) Window(time.Duration) time.Duration {
+	return r.window
+}
+
+func (r *linearRevalidation) String() string {
+	return fmt.Sprintf("linear(window=%s)", r.window)
+}
+
+type fixedPercentageRevalidation struct {
+	fraction float64
+}
+
+// NewFixedPercentageRevalidation returns a deterministic strategy revalidating
+// entries once less than fraction of their TTL remains, e.g. 0.1 refreshes
+// during the last 10% of the TTL. Entries with an unknown TTL are only
+// revalidated once expired.
+func NewFixedPercentageRevalidation(fraction float64) RevalidationStrategy {
+	return &fixedPercentageRevalidation{fraction: min(max(fraction, 0), 1)}
+}
+
+func (r *fixedPercentageRevalidation) Probability(entry RevalidationEntry) float64 {
+	if float64(entry.RemainingMillis()) <= r.fraction*float64(entry.TTL.Milliseconds()) {
+		return 1
+	}
+
+	return 0
+}
+
+func (r *fixedPercentageRevalidation) Window(ttl time.Duration) time.Duration {
+	return time.Duration(r.fraction * float64(ttl))
+}
+
+func (r *fixedPercentageRevalidation) String() string {
+	return fmt.Sprintf("fixed-percentage(fraction=%g)", r.fraction)
+}
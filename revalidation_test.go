@@ -0,0 +1,240 @@
+package crema
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"testing"
+	"time"
+)
+
+func revalidationEntry(remaining, ttl time.Duration) RevalidationEntry {
+	return RevalidationEntry{NowMillis: 1000, ExpireAtMillis: 1000 + remaining.Milliseconds(), TTL: ttl}
+}
+
+func TestExponentialRevalidation_Probability(t *testing.T) {
+	t.Parallel()
+
+	strategy := NewExponentialRevalidation(time.Second)
+	if p := strategy.Probability(revalidationEntry(time.Minute, 0)); p != 0 {
+		t.Fatalf("expected 0 outside the window, got %f", p)
+	}
+	window := strategy.Window(time.Hour)
+	if window <= 0 || window > time.Second {
+		t.Fatalf("expected window within the target, got %v", window)
+	}
+	if p := strategy.Probability(revalidationEntry(window, 0)); math.Abs(p-0.995) > 1e-3 {
+		t.Fatalf("expected 0.995 at the window edge, got %f", p)
+	}
+}
+
+func TestXFetchRevalidation_Probability(t *testing.T) {
+	t.Parallel()
+
+	strategy := NewXFetchRevalidation(100*time.Millisecond, 0)
+	if p := strategy.Probability(revalidationEntry(0, 0)); p != 1 {
+		t.Fatalf("expected 1 at expiry, got %f", p)
+	}
+	if p, want := strategy.Probability(revalidationEntry(100*time.Millisecond, 0)), math.Exp(-1); math.Abs(p-want) > 1e-9 {
+		t.Fatalf("expected %f one delta before expiry, got %f", want, p)
+	}
+	slow := NewXFetchRevalidation(time.Second, 2)
+	if slow.Probability(revalidationEntry(time.Second, 0)) <= strategy.Probability(revalidationEntry(time.Second, 0)) {
+		t.Fatal("expected costlier recomputation to revalidate earlier")
+	}
+	window := strategy.Window(0)
+	if p := strategy.Probability(revalidationEntry(window, 0)); math.Abs(p-xfetchWindowProbability) > 1e-3 {
+		t.Fatalf("expected window edge probability %f, got %f", xfetchWindowProbability, p)
+	}
+}
+
+func TestRelativeExponentialRevalidation_Probability(t *testing.T) {
+	t.Parallel()
+
+	strategy := NewRelativeExponentialRevalidation(0.1)
+	if window := strategy.Window(30 * time.Second); window <= 0 || window > 3*time.Second {
+		t.Fatalf("expected window within 10%% of a 30s ttl, got %v", window)
+	}
+	if window := strategy.Window(24 * time.Hour); window <= time.Hour || window > 144*time.Minute {
+		t.Fatalf("expected window within 10%% of a 24h ttl, got %v", window)
+	}
+	if p := strategy.Probability(revalidationEntry(5*time.Second, 30*time.Second)); p != 0 {
+		t.Fatalf("expected 0 before the window of a short ttl, got %f", p)
+	}
+	if p := strategy.Probability(revalidationEntry(5*time.Second, time.Hour)); p <= 0 {
+		t.Fatalf("expected positive probability within the window of a long ttl, got %f", p)
+	}
+	if p := strategy.Probability(revalidationEntry(time.Second, 0)); p != 0 {
+		t.Fatalf("expected 0 with unknown ttl, got %f", p)
+	}
+}
+
+func TestLinearRevalidation_Probability(t *testing.T) {
+	t.Parallel()
+
+	strategy := NewLinearRevalidation(time.Second)
+	tests := []struct {
+		remaining time.Duration
+		want      float64
+	}{
+		{remaining: 2 * time.Second, want: 0},
+		{remaining: time.Second, want: 0},
+		{remaining: 750 * time.Millisecond, want: 0.25},
+		{remaining: 0, want: 1},
+	}
+	for _, tt := range tests {
+		if got := strategy.Probability(revalidationEntry(tt.remaining, 0)); math.Abs(got-tt.want) > 1e-9 {
+			t.Fatalf("remaining %v: expected %f, got %f", tt.remaining, tt.want, got)
+		}
+	}
+}
+
+func TestFixedPercentageRevalidation_Probability(t *testing.T) {
+	t.Parallel()
+
+	strategy := NewFixedPercentageRevalidation(0.1)
+	if p := strategy.Probability(revalidationEntry(20*time.Second, 100*time.Second)); p != 0 {
+		t.Fatalf("expected 0 before the last 10%%, got %f", p)
+	}
+	if p := strategy.Probability(revalidationEntry(10*time.Second, 100*time.Second)); p != 1 {
+		t.Fatalf("expected 1 within the last 10%%, got %f", p)
+	}
+	if p := strategy.Probability(revalidationEntry(time.Second, 0)); p != 0 {
+		t.Fatalf("expected 0 with unknown ttl, got %f", p)
+	}
+	if window := strategy.Window(100 * time.Second); window != 10*time.Second {
+		t.Fatalf("expected 10s window, got %v", window)
+	}
+}
+
+func TestWithRevalidationStrategy_UsesTTL(t *testing.T) {
+	t.Parallel()
+
+	provider := &testMemoryProvider[int]{items: make(map[string]CacheObject[int])}
+	provider.items["key"] = CacheObject[int]{Value: 1, ExpireAtMillis: 1000 + 5*time.Second.Milliseconds()}
+	cache := NewCache(
+		provider,
+		NoopCacheStorageCodec[int]{},
+		WithRevalidationStrategy[int, CacheObject[int]](NewFixedPercentageRevalidation(0.1)),
+	)
+	impl := cache.(*cacheImpl[int, CacheObject[int]])
+	impl.now = func() time.Time { return time.UnixMilli(1000) }
+
+	value, err := cache.GetOrLoad(context.Background(), "key", time.Minute, constLoader(2))
+	if err != nil || value != 2 {
+		t.Fatalf("expected revalidation within the last 10%% of the ttl, got value=%d err=%v", value, err)
+	}
+	value, err = cache.GetOrLoad(context.Background(), "key", time.Minute, constLoader(3))
+	if err != nil || value != 2 {
+		t.Fatalf("expected fresh value to be served, got value=%d err=%v", value, err)
+	}
+}
+
+func TestMeasuredXFetchRevalidation_Probability(t *testing.T) {
+	t.Parallel()
+
+	strategy := NewMeasuredXFetchRevalidation(100*time.Millisecond, 0)
+	unknown := revalidationEntry(time.Second, 0)
+	measured := unknown
+	measured.LoadDuration = time.Second
+	if p, want := strategy.Probability(unknown), math.Exp(-10); math.Abs(p-want) > 1e-9 {
+		t.Fatalf("expected default delta probability %f, got %f", want, p)
+	}
+	if p, want := strategy.Probability(measured), math.Exp(-1); math.Abs(p-want) > 1e-9 {
+		t.Fatalf("expected measured delta probability %f, got %f", want, p)
+	}
+	if window := strategy.Window(0); window != xfetchWindow(100*time.Millisecond, 1) {
+		t.Fatalf("expected window from the default delta, got %v", window)
+	}
+}
+
+func TestGetOrLoad_StoresEntryMetadata(t *testing.T) {
+	t.Parallel()
+
+	provider := &testMemoryProvider[int]{items: make(map[string]CacheObject[int])}
+	cache := NewCache(provider, NoopCacheStorageCodec[int]{})
+
+	const latency = 20 * time.Millisecond
+	_, err := cache.GetOrLoad(context.Background(), "key", time.Minute, func(context.Context) (int, error) {
+		time.Sleep(latency)
+
+		return 1, nil
+	})
+	if err != nil {
+		t.Fatalf("expected load to succeed, got %v", err)
+	}
+	value, found, err := cache.Get(context.Background(), "key")
+	if err != nil || !found {
+		t.Fatalf("expected stored value, got found=%v err=%v", found, err)
+	}
+	if value.LoadDurationMillis < latency.Milliseconds() {
+		t.Fatalf("expected load duration of at least %dms, got %d", latency.Milliseconds(), value.LoadDurationMillis)
+	}
+	if value.TTLMillis != time.Minute.Milliseconds() {
+		t.Fatalf("expected stored ttl 1m, got %d", value.TTLMillis)
+	}
+}
+
+func TestShouldRevalidate_PrefersStoredTTL(t *testing.T) {
+	t.Parallel()
+
+	cache := NewCache(
+		&testMemoryProvider[int]{items: make(map[string]CacheObject[int])},
+		NoopCacheStorageCodec[int]{},
+		WithRevalidationWindowRatio[int, CacheObject[int]](0.1),
+	)
+	impl := cache.(*cacheImpl[int, CacheObject[int]])
+	impl.random = func() float64 { return 0 }
+	value := CacheObject[int]{ExpireAtMillis: 5 * time.Second.Milliseconds()}
+
+	if impl.shouldRevalidate("key", 0, value, time.Minute) {
+		t.Fatal("expected no revalidation 5s before expiry of a 1m ttl")
+	}
+	value.TTLMillis = time.Hour.Milliseconds()
+	if !impl.shouldRevalidate("key", 0, value, time.Minute) {
+		t.Fatal("expected the stored 1h ttl to put the entry in the window")
+	}
+}
+
+func TestEntryRevalidationWindow(t *testing.T) {
+	t.Parallel()
+
+	measured := NewMeasuredXFetchRevalidation(0, 1)
+	entry := revalidationEntry(2*time.Second, time.Minute)
+	entry.LoadDuration = time.Second
+	if got, want := EntryRevalidationWindow(measured, entry), xfetchWindow(time.Second, 1); got != want {
+		t.Fatalf("expected the window of the recorded load duration %v, got %v", want, got)
+	}
+	if p := measured.Probability(entry); p <= 0 || measured.Window(entry.TTL) != 0 {
+		t.Fatalf("expected a probability within the entry window only, got p=%f window=%v", p, measured.Window(entry.TTL))
+	}
+	linear := NewLinearRevalidation(time.Minute)
+	if got := EntryRevalidationWindow(linear, entry); got != time.Minute {
+		t.Fatalf("expected Window(ttl) for strategies without entry windows, got %v", got)
+	}
+}
+
+func TestRevalidationStrategies_String(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		strategy RevalidationStrategy
+		want     string
+	}{
+		{strategy: NewExponentialRevalidation(time.Second), want: "exponential(window=768ms)"},
+		{strategy: NewRelativeExponentialRevalidation(0.1), want: "relative-exponential(fraction=0.1)"},
+		{strategy: NewXFetchRevalidation(100*time.Millisecond, 2), want: "xfetch(delta=100ms, beta=2)"},
+		{strategy: NewMeasuredXFetchRevalidation(time.Second, 0), want: "measured-xfetch(default_delta=1s, beta=1)"},
+		{strategy: NewLinearRevalidation(time.Minute), want: "linear(window=1m0s)"},
+		{strategy: NewFixedPercentageRevalidation(0.25), want: "fixed-percentage(fraction=0.25)"},
+		{
+			strategy: NewAdaptiveRevalidation(time.Second, WithAdaptiveExpectedRefreshes(2)),
+			want:     "adaptive(window=768ms, expected_refreshes=2, sample_rate=0.1)",
+		},
+	}
+	for _, tc := range cases {
+		if got := fmt.Sprint(tc.strategy); got != tc.want {
+			t.Fatalf("String() = %q, want %q", got, tc.want)
+		}
+	}
+}
@@ -15,6 +15,11 @@ SUBMODULE_DIRS=(
   "example"
 )
 
+# Commands are released after the submodules they depend on.
+CMD_DIRS=(
+  "cmd/crema"
+)
+
 export GOPRIVATE=${MODULE_PREFIX}
 
 usage() {
@@ -86,7 +91,7 @@ create_tag "." "${VERSION}"
 # Update submodules
 echo ""
 echo "### update submodules ###"
-for dir in "${SUBMODULE_DIRS[@]}" ; do
+for dir in "${SUBMODULE_DIRS[@]}" "${CMD_DIRS[@]}" ; do
   pushd "${dir}" > /dev/null
     echo "update ${dir}/go.mod"
     go get "${MODULE_PREFIX}@${VERSION}"
@@ -118,10 +123,28 @@ pushd "example" > /dev/null
     go mod tidy
   done
 popd > /dev/null
+for cmd in "${CMD_DIRS[@]}" ; do
+  pushd "${cmd}" > /dev/null
+    for dir in "${SUBMODULE_DIRS[@]}" ; do
+      if grep -q "${MODULE_PREFIX}/${dir} " go.mod; then
+        go get "${MODULE_PREFIX}/${dir}@${VERSION}"
+      fi
+    done
+    go mod tidy
+  popd > /dev/null
+done
 go work sync
-git commit -a -m "update example to ${VERSION}"
+git commit -a -m "update example and commands to ${VERSION}"
 git push release-origin main
 
+# Release commands
+echo "### release commands ###"
+for dir in "${CMD_DIRS[@]}" ; do
+  echo "release ${dir}"
+  create_tag "${dir}" "${VERSION}"
+  release_tag "${dir}" "${VERSION}" "false"
+done
+
 echo ""
 echo "Create GitHub Release..."
 
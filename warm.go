@@ -0,0 +1,523 @@
+package crema
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"runtime/debug"
+	"sync"
+	"time"
+)
+
+// ErrWarmValueMissing is reported for keys a BatchLoadFunc returned no value for.
+var ErrWarmValueMissing = errors.New("batch loader returned no value for key")
+
+const (
+	defaultWarmConcurrency = 8
+	defaultWarmBatchSize   = 100
+)
+
+// WarmLoadFunc loads the value of a single key for Warm.
+type WarmLoadFunc[V any] func(ctx context.Context, key string) (V, error)
+
+// BatchLoadFunc loads the values of several keys at once for WarmBatch.
+// Keys missing from the returned map are reported with ErrWarmValueMissing.
+type BatchLoadFunc[V any] func(ctx context.Context, keys []string) (map[string]V, error)
+
+// CacheWarmer is implemented by caches that can be preloaded in bulk.
+// Caches built by NewCache implement it; use a type assertion to access it.
+type CacheWarmer[V any] interface {
+	// Warm preloads keys with loader and bounded concurrency, skipping keys
+	// that are cached and not due for revalidation. Failed keys are reported
+	// in a *WarmError.
+	Warm(ctx context.Context, keys []string, ttl time.Duration, loader WarmLoadFunc[V], opts ...WarmOption) error
+	// WarmSeq is like Warm but takes the keys from an iter.Seq[string].
+	WarmSeq(ctx context.Context, keys func(yield func(string) bool), ttl time.Duration, loader WarmLoadFunc[V], opts ...WarmOption) error
+	// WarmBatch is like Warm but loads keys in batches with a single loader call each.
+	WarmBatch(ctx context.Context, keys []string, ttl time.Duration, loader BatchLoadFunc[V], opts ...WarmOption) error
+	// WarmBatchSeq is like WarmBatch but takes the keys from an iter.Seq[string].
+	WarmBatchSeq(
+		ctx context.Context,
+		keys func(yield func(string) bool),
+		ttl time.Duration,
+		loader BatchLoadFunc[V],
+		opts ...WarmOption,
+	) error
+}
+
+var _ CacheWarmer[any] = (*cacheImpl[any, any])(nil)
+
+// WarmProgress reports how far a warm-up has progressed.
+type WarmProgress struct {
+	// Total is the number of keys to warm. For WarmSeq and WarmBatchSeq, it is
+	// the number of keys taken from the sequence so far.
+	Total int
+	// Loaded is the number of keys loaded and stored.
+	Loaded int
+	// Skipped is the number of keys already cached and not due for revalidation.
+	Skipped int
+	// Stale is the number of keys whose load was shed or failed while the
+	// cached value was kept, as with LoadShedPolicyServeStale.
+	Stale int
+	// Failed is the number of keys that could not be loaded.
+	Failed int
+}
+
+// Done returns the number of keys processed so far.
+func (p WarmProgress) Done() int {
+	return p.Loaded + p.Skipped + p.Stale + p.Failed
+}
+
+// WarmError reports the keys a warm-up failed to load.
+type WarmError struct {
+	// Errors maps each failed key to its error.
+	Errors map[string]error
+	// Err is the error that stopped the warm-up before every key was
+	// processed, e.g. ctx.Err() or ErrCacheClosed. The keys left are neither
+	// taken from the sequence nor reported in Errors.
+	Err error
+}
+
+// Error implements error.
+func (e *WarmError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("failed to warm %d keys, stopped: %v", len(e.Errors), e.Err)
+	}
+
+	return fmt.Sprintf("failed to warm %d keys", len(e.Errors))
+}
+
+// Unwrap returns the per-key errors and the error that stopped the warm-up.
+func (e *WarmError) Unwrap() []error {
+	errs := make([]error, 0, len(e.Errors)+1)
+	for _, err := range e.Errors {
+		errs = append(errs, err)
+	}
+	if e.Err != nil {
+		errs = append(errs, e.Err)
+	}
+
+	return errs
+}
+
+type warmConfig struct {
+	concurrency int
+	batchSize   int
+	progress    func(WarmProgress)
+}
+
+// WarmOption configures Warm and WarmBatch.
+type WarmOption func(*warmConfig)
+
+// WithWarmConcurrency caps concurrent loads, or batch loads for WarmBatch.
+// It defaults to 8.
+func WithWarmConcurrency(n int) WarmOption {
+	return func(c *warmConfig) {
+		if n > 0 {
+			c.concurrency = n
+		}
+	}
+}
+
+// WithWarmBatchSize sets the maximum number of keys per WarmBatch loader call.
+// It defaults to 100.
+func WithWarmBatchSize(n int) WarmOption {
+	return func(c *warmConfig) {
+		if n > 0 {
+			c.batchSize = n
+		}
+	}
+}
+
+// WithWarmProgress sets a callback invoked after each key is processed.
+// Calls are serialized.
+func WithWarmProgress(progress func(WarmProgress)) WarmOption {
+	return func(c *warmConfig) {
+		c.progress = progress
+	}
+}
+
+func newWarmConfig(opts []WarmOption) warmConfig {
+	config := warmConfig{
+		concurrency: defaultWarmConcurrency,
+		batchSize:   defaultWarmBatchSize,
+	}
+	for _, opt := range opts {
+		if opt == nil {
+			continue
+		}
+		opt(&config)
+	}
+
+	return config
+}
+
+// warmTracker collects per-key outcomes of a warm-up and reports progress.
+type warmTracker struct {
+	mu       sync.Mutex
+	progress WarmProgress
+	errors   map[string]error
+	stopErr  error
+	report   func(WarmProgress)
+}
+
+func newWarmTracker(total int, report func(WarmProgress)) *warmTracker {
+	return &warmTracker{
+		progress: WarmProgress{Total: total},
+		errors:   make(map[string]error),
+		report:   report,
+	}
+}
+
+// count returns keys, adding each key it yields to the progress total.
+func (t *warmTracker) count(keys keySeq) keySeq {
+	return func(yield func(string) bool) {
+		keys(func(key string) bool {
+			t.mu.Lock()
+			t.progress.Total++
+			t.mu.Unlock()
+
+			return yield(key)
+		})
+	}
+}
+
+func (t *warmTracker) loaded() {
+	t.record(func(p *WarmProgress) { p.Loaded++ })
+}
+
+func (t *warmTracker) skipped() {
+	t.record(func(p *WarmProgress) { p.Skipped++ })
+}
+
+func (t *warmTracker) stale() {
+	t.record(func(p *WarmProgress) { p.Stale++ })
+}
+
+func (t *warmTracker) failed(key string, err error) {
+	t.record(func(p *WarmProgress) {
+		p.Failed++
+		t.errors[key] = err
+	})
+}
+
+func (t *warmTracker) record(update func(*WarmProgress)) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	update(&t.progress)
+	if t.report != nil {
+		t.report(t.progress)
+	}
+}
+
+// stop records err as the reason the warm-up ended before every key was processed.
+func (t *warmTracker) stop(err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.stopErr = err
+}
+
+func (t *warmTracker) err() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if len(t.errors) == 0 && t.stopErr == nil {
+		return nil
+	}
+
+	return &WarmError{Errors: t.errors, Err: t.stopErr}
+}
+
+// keySeq is the underlying type of iter.Seq[string], so iter.Seq[string]
+// values can be passed where it is expected without requiring Go 1.23.
+type keySeq = func(yield func(string) bool)
+
+// sliceKeys returns a keySeq yielding keys in order.
+func sliceKeys(keys []string) keySeq {
+	return func(yield func(string) bool) {
+		for _, key := range keys {
+			if !yield(key) {
+				return
+			}
+		}
+	}
+}
+
+// batchKeys returns a sequence of consecutive batches of up to size keys.
+func batchKeys(keys keySeq, size int) func(yield func([]string) bool) {
+	return func(yield func([]string) bool) {
+		batch := make([]string, 0, size)
+		stopped := false
+		keys(func(key string) bool {
+			batch = append(batch, key)
+			if len(batch) < size {
+				return true
+			}
+			stopped = !yield(batch)
+			batch = make([]string, 0, size)
+
+			return !stopped
+		})
+		if !stopped && len(batch) > 0 {
+			yield(batch)
+		}
+	}
+}
+
+// runWarmTasks runs task for each item of items with at most concurrency tasks
+// at once. Once ctx is done, it stops taking items and returns ctx.Err().
+func runWarmTasks[T any](
+	ctx context.Context,
+	items func(yield func(T) bool),
+	concurrency int,
+	task func(item T),
+) error {
+	var wg sync.WaitGroup
+	var err error
+	slots := make(chan struct{}, concurrency)
+	items(func(item T) bool {
+		if !acquireWarmSlot(ctx, slots) {
+			err = ctx.Err()
+
+			return false
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-slots }()
+			task(item)
+		}()
+
+		return true
+	})
+	wg.Wait()
+
+	return err
+}
+
+// acquireWarmSlot takes a slot unless ctx is done, even if a slot is free.
+func acquireWarmSlot(ctx context.Context, slots chan<- struct{}) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	select {
+	case slots <- struct{}{}:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// Warm preloads keys with loader like GetOrLoad with bounded concurrency,
+// skipping keys that are cached and not due for revalidation. Its lookups are
+// not reported to a RevalidationObserver since they are not requests. It returns a
+// *WarmError with the per-key errors when any key fails, and with ctx.Err()
+// when ctx is done before every key was attempted.
+func (c *cacheImpl[V, S]) Warm(
+	ctx context.Context,
+	keys []string,
+	ttl time.Duration,
+	loader WarmLoadFunc[V],
+	opts ...WarmOption,
+) error {
+	config := newWarmConfig(opts)
+
+	return c.warm(ctx, sliceKeys(keys), newWarmTracker(len(keys), config.progress), ttl, loader, config)
+}
+
+// WarmSeq is like Warm but takes the keys from an iter.Seq[string], e.g. a
+// database cursor, without collecting them first. Once ctx is done, it stops
+// taking keys from the sequence.
+func (c *cacheImpl[V, S]) WarmSeq(
+	ctx context.Context,
+	keys func(yield func(string) bool),
+	ttl time.Duration,
+	loader WarmLoadFunc[V],
+	opts ...WarmOption,
+) error {
+	config := newWarmConfig(opts)
+	tracker := newWarmTracker(0, config.progress)
+
+	return c.warm(ctx, tracker.count(keys), tracker, ttl, loader, config)
+}
+
+func (c *cacheImpl[V, S]) warm(
+	ctx context.Context,
+	keys keySeq,
+	tracker *warmTracker,
+	ttl time.Duration,
+	loader WarmLoadFunc[V],
+	config warmConfig,
+) error {
+	err := runWarmTasks(ctx, keys, config.concurrency, func(key string) {
+		value, found := c.lookup(ctx, key)
+		result, err := c.loadIfDue(ctx, key, ttl, func(ctx context.Context) (V, error) {
+			return loader(ctx, key)
+		}, value, found)
+		switch {
+		case err != nil:
+			tracker.failed(key, err)
+		case result.Source == LoadSourceHit:
+			tracker.skipped()
+		case result.Source == LoadSourceStale:
+			tracker.stale()
+		default:
+			tracker.loaded()
+		}
+	})
+	if err != nil {
+		tracker.stop(err)
+	}
+
+	return tracker.err()
+}
+
+// WarmBatch is like Warm but loads the keys due for loading in batches with a
+// single loader call each. Batch loads bypass singleflight, but each loader
+// call is admitted by WithLoadRateLimit and WithLoadConcurrencyLimit as one
+// load of the first key of its batch. Batches that are not admitted fail.
+func (c *cacheImpl[V, S]) WarmBatch(
+	ctx context.Context,
+	keys []string,
+	ttl time.Duration,
+	loader BatchLoadFunc[V],
+	opts ...WarmOption,
+) error {
+	config := newWarmConfig(opts)
+
+	return c.warmBatches(ctx, sliceKeys(keys), newWarmTracker(len(keys), config.progress), ttl, loader, config)
+}
+
+// WarmBatchSeq is like WarmBatch but takes the keys from an iter.Seq[string].
+func (c *cacheImpl[V, S]) WarmBatchSeq(
+	ctx context.Context,
+	keys func(yield func(string) bool),
+	ttl time.Duration,
+	loader BatchLoadFunc[V],
+	opts ...WarmOption,
+) error {
+	config := newWarmConfig(opts)
+	tracker := newWarmTracker(0, config.progress)
+
+	return c.warmBatches(ctx, tracker.count(keys), tracker, ttl, loader, config)
+}
+
+func (c *cacheImpl[V, S]) warmBatches(
+	ctx context.Context,
+	keys keySeq,
+	tracker *warmTracker,
+	ttl time.Duration,
+	loader BatchLoadFunc[V],
+	config warmConfig,
+) error {
+	if !c.lifecycle.acquire() {
+		tracker.stop(ErrCacheClosed)
+
+		return tracker.err()
+	}
+	defer c.lifecycle.release()
+
+	err := runWarmTasks(ctx, batchKeys(keys, config.batchSize), config.concurrency, func(batch []string) {
+		c.warmBatch(ctx, batch, ttl, loader, tracker)
+	})
+	if err != nil {
+		tracker.stop(err)
+	}
+
+	return tracker.err()
+}
+
+// warmBatch loads and stores the keys of batch that are due for loading.
+func (c *cacheImpl[V, S]) warmBatch(
+	ctx context.Context,
+	batch []string,
+	ttl time.Duration,
+	loader BatchLoadFunc[V],
+	tracker *warmTracker,
+) {
+	due := make([]string, 0, len(batch))
+	for _, key := range batch {
+		value, found := c.lookup(ctx, key)
+		if found && !c.shouldRevalidate(key, c.now().UnixMilli(), value, ttl) {
+			tracker.skipped()
+
+			continue
+		}
+		due = append(due, key)
+	}
+	if len(due) == 0 {
+		return
+	}
+
+	values, loadDuration, err := c.loadBatch(ctx, due, loader)
+	if err != nil {
+		for _, key := range due {
+			tracker.failed(key, err)
+		}
+
+		return
+	}
+	expireAtMillis := c.now().Add(ttl).UnixMilli()
+	for _, key := range due {
+		value, ok := values[key]
+		if !ok {
+			tracker.failed(key, ErrWarmValueMissing)
+
+			continue
+		}
+		c.storeLoaded(ctx, key, CacheObject[V]{
+			Value:              value,
+			ExpireAtMillis:     expireAtMillis,
+			LoadDurationMillis: loadDuration.Milliseconds(),
+			TTLMillis:          ttl.Milliseconds(),
+		})
+		tracker.loaded()
+	}
+}
+
+// admitBatch admits a batch loader call through the rate and concurrency
+// limits of the singleflight loader as a load of key.
+func (c *cacheImpl[V, S]) admitBatch(ctx context.Context, key string) (func(), error) {
+	loader, ok := c.internalLoader.(*singleflightLoader[V])
+	if !ok {
+		return func() {}, nil
+	}
+
+	return loader.admit(ctx, key, ctx)
+}
+
+// loadBatch calls loader for keys once admitted by admitBatch, and returns
+// how long the call took.
+func (c *cacheImpl[V, S]) loadBatch(ctx context.Context, keys []string, loader BatchLoadFunc[V]) (map[string]V, time.Duration, error) {
+	release, err := c.admitBatch(ctx, keys[0])
+	if err != nil {
+		return nil, 0, err
+	}
+	defer release()
+
+	start := time.Now()
+	values, err := c.callBatchLoader(ctx, keys, loader)
+
+	return values, time.Since(start), err
+}
+
+// callBatchLoader calls loader, converting a panic into a *LoadPanicError.
+func (c *cacheImpl[V, S]) callBatchLoader(ctx context.Context, keys []string, loader BatchLoadFunc[V]) (values map[string]V, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			panicErr := &LoadPanicError{Value: r, Stack: debug.Stack()}
+			c.logger.Error("cache batch loader panicked",
+				slog.String("error", panicErr.Error()),
+				slog.String("stack", string(panicErr.Stack)),
+			)
+			err = panicErr
+		}
+	}()
+	c.metrics.RecordLoad(ctx)
+	c.stats.loads.Add(1)
+
+	return loader(ctx, keys)
+}
@@ -0,0 +1,285 @@
+package crema
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCache_WarmLoadsMissingAndSkipsFreshKeys(t *testing.T) {
+	t.Parallel()
+
+	provider := &testMemoryProvider[int]{items: make(map[string]CacheObject[int])}
+	provider.items["fresh"] = CacheObject[int]{Value: 1, ExpireAtMillis: time.Now().Add(time.Hour).UnixMilli()}
+	cache := NewCache(provider, NoopCacheStorageCodec[int]{})
+	impl := cache.(*cacheImpl[int, CacheObject[int]])
+	impl.random = fakeRandom(1)
+
+	errBroken := errors.New("broken")
+	var mu sync.Mutex
+	var progress []WarmProgress
+	err := cache.(CacheWarmer[int]).Warm(context.Background(), []string{"fresh", "a", "b", "broken"}, time.Minute,
+		func(_ context.Context, key string) (int, error) {
+			if key == "broken" {
+				return 0, errBroken
+			}
+
+			return len(key), nil
+		},
+		WithWarmConcurrency(2),
+		WithWarmProgress(func(p WarmProgress) {
+			mu.Lock()
+			defer mu.Unlock()
+			progress = append(progress, p)
+		}),
+	)
+
+	var warmErr *WarmError
+	if !errors.As(err, &warmErr) || !errors.Is(err, errBroken) {
+		t.Fatalf("expected *WarmError wrapping the loader error, got %v", err)
+	}
+	if len(warmErr.Errors) != 1 || !errors.Is(warmErr.Errors["broken"], errBroken) {
+		t.Fatalf("expected only the broken key to fail, got %v", warmErr.Errors)
+	}
+	if provider.items["a"].Value != 1 || provider.items["b"].Value != 1 || provider.items["fresh"].Value != 1 {
+		t.Fatalf("expected missing keys to be loaded and fresh keys kept, got %v", provider.items)
+	}
+	last := progress[len(progress)-1]
+	if len(progress) != 4 || last != (WarmProgress{Total: 4, Loaded: 2, Skipped: 1, Failed: 1}) || last.Done() != 4 {
+		t.Fatalf("expected progress for each key, got %+v", progress)
+	}
+}
+
+func TestCache_WarmHonorsCanceledContext(t *testing.T) {
+	t.Parallel()
+
+	provider := &testMemoryProvider[int]{items: make(map[string]CacheObject[int])}
+	cache := NewCache(provider, NoopCacheStorageCodec[int]{})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	var calls atomic.Int32
+	err := cache.(CacheWarmer[int]).Warm(ctx, []string{"a", "b"}, time.Minute, func(context.Context, string) (int, error) {
+		calls.Add(1)
+
+		return 1, nil
+	})
+	var warmErr *WarmError
+	if !errors.As(err, &warmErr) || len(warmErr.Errors) != 0 || !errors.Is(warmErr.Err, context.Canceled) {
+		t.Fatalf("expected the warm-up to stop with context canceled, got %v", err)
+	}
+	if got := calls.Load(); got != 0 {
+		t.Fatalf("expected no loads after cancellation, got %d", got)
+	}
+}
+
+func TestCache_WarmSeqStopsTakingKeysOnceCanceled(t *testing.T) {
+	t.Parallel()
+
+	provider := &testMemoryProvider[int]{items: make(map[string]CacheObject[int])}
+	cache := NewCache(provider, NoopCacheStorageCodec[int]{}).(CacheWarmer[int])
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var pulled atomic.Int32
+	endless := func(yield func(string) bool) {
+		for i := 0; ; i++ {
+			pulled.Add(1)
+			if !yield(strconv.Itoa(i)) {
+				return
+			}
+		}
+	}
+	err := cache.WarmSeq(ctx, endless, time.Minute, func(context.Context, string) (int, error) {
+		cancel()
+
+		return 1, nil
+	}, WithWarmConcurrency(1))
+
+	var warmErr *WarmError
+	if !errors.As(err, &warmErr) || !errors.Is(warmErr.Err, context.Canceled) {
+		t.Fatalf("expected the warm-up to stop with context canceled, got %v", err)
+	}
+	if got := pulled.Load(); got > 2 {
+		t.Fatalf("expected the sequence to stop once canceled, pulled %d keys", got)
+	}
+}
+
+func TestCache_WarmCountsStaleValues(t *testing.T) {
+	t.Parallel()
+
+	provider := &testMemoryProvider[int]{items: make(map[string]CacheObject[int])}
+	provider.items["expired"] = CacheObject[int]{Value: 1, ExpireAtMillis: time.Now().Add(-time.Minute).UnixMilli()}
+	cache := NewCache(provider, NoopCacheStorageCodec[int]{},
+		WithLoadRateLimit[int, CacheObject[int]](LoadRateLimit{Rate: 0.001, Burst: 1, ShedPolicy: LoadShedPolicyServeStale}),
+	).(CacheWarmer[int])
+
+	var last WarmProgress
+	err := cache.Warm(context.Background(), []string{"missing", "expired"}, time.Minute, func(context.Context, string) (int, error) {
+		return 2, nil
+	}, WithWarmConcurrency(1), WithWarmProgress(func(p WarmProgress) { last = p }))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if last != (WarmProgress{Total: 2, Loaded: 1, Stale: 1}) {
+		t.Fatalf("expected the rate limited key served stale to be counted as stale, got %+v", last)
+	}
+}
+
+func TestCache_WarmBatchLoadsDueKeysInBatches(t *testing.T) {
+	t.Parallel()
+
+	provider := &testMemoryProvider[int]{items: make(map[string]CacheObject[int])}
+	provider.items["fresh"] = CacheObject[int]{Value: 1, ExpireAtMillis: time.Now().Add(time.Hour).UnixMilli()}
+	cache := NewCache(provider, NoopCacheStorageCodec[int]{})
+	impl := cache.(*cacheImpl[int, CacheObject[int]])
+	impl.random = fakeRandom(1)
+
+	var mu sync.Mutex
+	var batches [][]string
+	err := cache.(CacheWarmer[int]).WarmBatch(context.Background(), []string{"fresh", "a", "b", "c", "missing"}, time.Minute,
+		func(_ context.Context, keys []string) (map[string]int, error) {
+			mu.Lock()
+			batches = append(batches, append([]string(nil), keys...))
+			mu.Unlock()
+			values := make(map[string]int)
+			for _, key := range keys {
+				if key != "missing" {
+					values[key] = 2
+				}
+			}
+
+			return values, nil
+		},
+		WithWarmBatchSize(2),
+		WithWarmConcurrency(1),
+	)
+
+	var warmErr *WarmError
+	if !errors.As(err, &warmErr) || len(warmErr.Errors) != 1 || !errors.Is(warmErr.Errors["missing"], ErrWarmValueMissing) {
+		t.Fatalf("expected only the missing key to fail, got %v", err)
+	}
+	for _, key := range []string{"a", "b", "c"} {
+		if provider.items[key].Value != 2 {
+			t.Fatalf("expected %s to be loaded, got %v", key, provider.items[key])
+		}
+	}
+	loaded := make([]string, 0, len(batches)*2)
+	for _, batch := range batches {
+		if len(batch) > 2 {
+			t.Fatalf("expected batches of at most 2 keys, got %v", batch)
+		}
+		loaded = append(loaded, batch...)
+	}
+	sort.Strings(loaded)
+	if len(loaded) != 4 || loaded[0] != "a" || loaded[3] != "missing" {
+		t.Fatalf("expected only due keys to be loaded, got %v", loaded)
+	}
+}
+
+func TestCache_WarmSeqCountsYieldedKeys(t *testing.T) {
+	t.Parallel()
+
+	provider := &testMemoryProvider[int]{items: make(map[string]CacheObject[int])}
+	cache := NewCache(provider, NoopCacheStorageCodec[int]{},
+		WithRandomSource[int, CacheObject[int]](fakeRandom(1)),
+	).(CacheWarmer[int])
+	keys := func(yield func(string) bool) {
+		for _, key := range []string{"a", "bb", "ccc"} {
+			if !yield(key) {
+				return
+			}
+		}
+	}
+
+	var mu sync.Mutex
+	var last WarmProgress
+	progress := WithWarmProgress(func(p WarmProgress) {
+		mu.Lock()
+		defer mu.Unlock()
+		last = p
+	})
+	err := cache.WarmSeq(context.Background(), keys, time.Minute, func(_ context.Context, key string) (int, error) {
+		return len(key), nil
+	}, progress)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if last != (WarmProgress{Total: 3, Loaded: 3}) {
+		t.Fatalf("expected every yielded key to be counted, got %+v", last)
+	}
+	if provider.items["ccc"].Value != 3 {
+		t.Fatalf("expected ccc to be loaded, got %v", provider.items["ccc"])
+	}
+
+	var batches atomic.Int32
+	err = cache.WarmBatchSeq(context.Background(), keys, time.Minute, func(_ context.Context, keys []string) (map[string]int, error) {
+		batches.Add(1)
+		values := make(map[string]int)
+		for _, key := range keys {
+			values[key] = 0
+		}
+
+		return values, nil
+	}, WithWarmBatchSize(2), progress)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if got := batches.Load(); got != 0 {
+		t.Fatalf("expected fresh keys to be skipped, got %d batch loads", got)
+	}
+	if last != (WarmProgress{Total: 3, Skipped: 3}) {
+		t.Fatalf("expected every yielded key to be skipped, got %+v", last)
+	}
+}
+
+func TestCache_WarmBatchIsRateLimited(t *testing.T) {
+	t.Parallel()
+
+	provider := &testMemoryProvider[int]{items: make(map[string]CacheObject[int])}
+	cache := NewCache(provider, NoopCacheStorageCodec[int]{},
+		WithLoadRateLimit[int, CacheObject[int]](LoadRateLimit{Rate: 0.001, Burst: 1}),
+	).(CacheWarmer[int])
+
+	var calls atomic.Int32
+	err := cache.WarmBatch(context.Background(), []string{"a", "b", "c"}, time.Minute,
+		func(_ context.Context, keys []string) (map[string]int, error) {
+			calls.Add(1)
+			values := make(map[string]int)
+			for _, key := range keys {
+				values[key] = 1
+			}
+
+			return values, nil
+		},
+		WithWarmBatchSize(2),
+		WithWarmConcurrency(1),
+	)
+
+	var warmErr *WarmError
+	if !errors.As(err, &warmErr) || len(warmErr.Errors) != 1 || !errors.Is(err, ErrLoadRateLimited) {
+		t.Fatalf("expected the second batch to be rate limited, got %v", err)
+	}
+	if got := calls.Load(); got != 1 {
+		t.Fatalf("expected a single batch load, got %d", got)
+	}
+}
+
+func TestCache_WarmBatchRecoversLoaderPanic(t *testing.T) {
+	t.Parallel()
+
+	provider := &testMemoryProvider[int]{items: make(map[string]CacheObject[int])}
+	cache := NewCache(provider, NoopCacheStorageCodec[int]{}).(CacheWarmer[int])
+
+	err := cache.WarmBatch(context.Background(), []string{"a"}, time.Minute, func(context.Context, []string) (map[string]int, error) {
+		panic("boom")
+	})
+	if !errors.Is(err, ErrLoadPanic) {
+		t.Fatalf("expected ErrLoadPanic, got %v", err)
+	}
+}
@@ -0,0 +1,174 @@
+package crema
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+)
+
+// WriteBehindPolicy selects what happens when the write-behind queue is full.
+type WriteBehindPolicy int
+
+const (
+	// WriteBehindPolicyDrop discards the write when the queue is full.
+	WriteBehindPolicyDrop WriteBehindPolicy = iota
+	// WriteBehindPolicyBlock waits for queue space until the caller context is done,
+	// then discards the write.
+	WriteBehindPolicyBlock
+)
+
+const (
+	defaultWriteBehindQueueSize = 1024
+	defaultWriteBehindWorkers   = 4
+)
+
+// WriteBehindConfig configures the write-behind queue used by WithWriteBehind.
+type WriteBehindConfig struct {
+	// QueueSize is the maximum number of pending writes. Defaults to 1024.
+	QueueSize int
+	// Workers is the number of goroutines performing writes. Defaults to 4.
+	Workers int
+	// Policy is the backpressure policy applied when the queue is full.
+	Policy WriteBehindPolicy
+}
+
+type writeBehindTask[V any] struct {
+	ctx   context.Context
+	key   string
+	value CacheObject[V]
+}
+
+type writeBehindQueue[V any] struct {
+	_       noCopy
+	tasks   chan writeBehindTask[V]
+	policy  WriteBehindPolicy
+	set     func(ctx context.Context, key string, value CacheObject[V])
+	metrics MetricsProvider
+	logger  *slog.Logger
+	wg      sync.WaitGroup
+	mu      sync.RWMutex
+	closed  bool
+	// closing is closed when close starts, releasing enqueue calls blocked on
+	// a full queue so that close can take mu.
+	closing     chan struct{}
+	closingOnce sync.Once
+}
+
+func newWriteBehindQueue[V any](
+	config WriteBehindConfig,
+	metrics MetricsProvider,
+	logger *slog.Logger,
+	set func(ctx context.Context, key string, value CacheObject[V]),
+) *writeBehindQueue[V] {
+	if config.QueueSize <= 0 {
+		config.QueueSize = defaultWriteBehindQueueSize
+	}
+	if config.Workers <= 0 {
+		config.Workers = defaultWriteBehindWorkers
+	}
+
+	q := &writeBehindQueue[V]{
+		tasks:   make(chan writeBehindTask[V], config.QueueSize),
+		policy:  config.Policy,
+		set:     set,
+		metrics: metrics,
+		logger:  logger,
+		closing: make(chan struct{}),
+	}
+	q.wg.Add(config.Workers)
+	for range config.Workers {
+		go q.work()
+	}
+
+	return q
+}
+
+// enqueue schedules a write detached from the caller's cancellation.
+// After close, or once close starts while waiting for queue space, the write
+// is performed synchronously instead.
+func (q *writeBehindQueue[V]) enqueue(ctx context.Context, key string, value CacheObject[V]) {
+	if !q.tryEnqueue(ctx, key, value) {
+		q.set(ctx, key, value)
+	}
+}
+
+// tryEnqueue queues the write, or drops it as the policy requires. It returns
+// false when the queue is closing and the write must be performed by the caller.
+func (q *writeBehindQueue[V]) tryEnqueue(ctx context.Context, key string, value CacheObject[V]) bool {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	if q.closed {
+		return false
+	}
+
+	task := writeBehindTask[V]{ctx: context.WithoutCancel(ctx), key: key, value: value}
+	select {
+	case q.tasks <- task:
+		q.recordDepth(ctx)
+
+		return true
+	default:
+	}
+
+	if q.policy == WriteBehindPolicyBlock {
+		select {
+		case q.tasks <- task:
+			q.recordDepth(ctx)
+
+			return true
+		case <-q.closing:
+			return false
+		case <-ctx.Done():
+		}
+	}
+	q.drop(ctx, key)
+
+	return true
+}
+
+// close stops accepting writes and waits until pending writes are flushed or ctx is done.
+func (q *writeBehindQueue[V]) close(ctx context.Context) error {
+	q.closingOnce.Do(func() { close(q.closing) })
+
+	done := make(chan struct{})
+	go func() {
+		q.mu.Lock()
+		if !q.closed {
+			q.closed = true
+			close(q.tasks)
+		}
+		q.mu.Unlock()
+
+		q.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (q *writeBehindQueue[V]) work() {
+	defer q.wg.Done()
+	for task := range q.tasks {
+		q.recordDepth(task.ctx)
+		q.set(task.ctx, task.key, task.value)
+	}
+}
+
+func (q *writeBehindQueue[V]) recordDepth(ctx context.Context) {
+	if metrics, ok := q.metrics.(WriteBehindMetricsProvider); ok {
+		metrics.RecordWriteBehindQueueDepth(ctx, len(q.tasks))
+	}
+}
+
+func (q *writeBehindQueue[V]) drop(ctx context.Context, key string) {
+	q.logger.Warn("dropped write-behind cache set", slog.String("key", key))
+	if metrics, ok := q.metrics.(WriteBehindMetricsProvider); ok {
+		metrics.RecordWriteBehindDropped(ctx)
+	}
+}
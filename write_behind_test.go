@@ -0,0 +1,316 @@
+package crema
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type blockingSetProvider struct {
+	testMemoryProvider[int]
+
+	release chan struct{}
+	started chan struct{}
+}
+
+func newBlockingSetProvider() *blockingSetProvider {
+	return &blockingSetProvider{
+		testMemoryProvider: testMemoryProvider[int]{items: make(map[string]CacheObject[int])},
+		release:            make(chan struct{}),
+		started:            make(chan struct{}, 16),
+	}
+}
+
+func (p *blockingSetProvider) Set(ctx context.Context, key string, value CacheObject[int], ttl time.Duration) error {
+	p.started <- struct{}{}
+	<-p.release
+
+	return p.testMemoryProvider.Set(ctx, key, value, ttl)
+}
+
+func (p *blockingSetProvider) stored(key string) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	_, ok := p.items[key]
+
+	return ok
+}
+
+type writeBehindTestMetrics struct {
+	BaseMetricsProvider
+
+	dropped  atomic.Int32
+	maxDepth atomic.Int32
+}
+
+func (m *writeBehindTestMetrics) RecordWriteBehindQueueDepth(_ context.Context, depth int) {
+	for {
+		current := m.maxDepth.Load()
+		if int32(depth) <= current || m.maxDepth.CompareAndSwap(current, int32(depth)) {
+			return
+		}
+	}
+}
+
+func (m *writeBehindTestMetrics) RecordWriteBehindDropped(context.Context) {
+	m.dropped.Add(1)
+}
+
+func constLoader(value int) CacheLoadFunc[int] {
+	return func(context.Context) (int, error) {
+		return value, nil
+	}
+}
+
+func TestWriteBehind_ReturnsBeforeSetAndFlushesOnClose(t *testing.T) {
+	t.Parallel()
+
+	provider := newBlockingSetProvider()
+	cache := NewCache(
+		provider,
+		NoopCacheStorageCodec[int]{},
+		WithWriteBehind[int, CacheObject[int]](WriteBehindConfig{QueueSize: 4, Workers: 1}),
+	)
+
+	value, err := cache.GetOrLoad(context.Background(), "key", time.Minute, constLoader(1))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if value != 1 {
+		t.Fatalf("expected value 1, got %d", value)
+	}
+	<-provider.started
+	if provider.stored("key") {
+		t.Fatal("expected write to be pending")
+	}
+
+	closeErr := make(chan error, 1)
+	go func() {
+		closeErr <- cache.(CacheCloser).Close(context.Background())
+	}()
+	select {
+	case err := <-closeErr:
+		t.Fatalf("expected close to wait for pending write, got %v", err)
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	close(provider.release)
+	if err := <-closeErr; err != nil {
+		t.Fatalf("expected close to succeed, got %v", err)
+	}
+	if !provider.stored("key") {
+		t.Fatal("expected pending write to be flushed on close")
+	}
+}
+
+func TestWriteBehind_DropPolicyDropsWhenFull(t *testing.T) {
+	t.Parallel()
+
+	provider := newBlockingSetProvider()
+	metrics := &writeBehindTestMetrics{}
+	cache := NewCache(
+		provider,
+		NoopCacheStorageCodec[int]{},
+		WithWriteBehind[int, CacheObject[int]](WriteBehindConfig{QueueSize: 1, Workers: 1, Policy: WriteBehindPolicyDrop}),
+		WithMetricsProvider[int, CacheObject[int]](metrics),
+	)
+	ctx := context.Background()
+
+	if _, err := cache.GetOrLoad(ctx, "a", time.Minute, constLoader(1)); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	<-provider.started
+	for _, key := range []string{"b", "c"} {
+		if _, err := cache.GetOrLoad(ctx, key, time.Minute, constLoader(1)); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	}
+
+	if dropped := metrics.dropped.Load(); dropped != 1 {
+		t.Fatalf("expected one dropped write, got %d", dropped)
+	}
+	if depth := metrics.maxDepth.Load(); depth != 1 {
+		t.Fatalf("expected max queue depth 1, got %d", depth)
+	}
+
+	close(provider.release)
+	if err := cache.(CacheCloser).Close(ctx); err != nil {
+		t.Fatalf("expected close to succeed, got %v", err)
+	}
+	if !provider.stored("a") || !provider.stored("b") || provider.stored("c") {
+		t.Fatal("expected a and b to be stored and c to be dropped")
+	}
+}
+
+func TestWriteBehind_BlockPolicyWaitsForSpace(t *testing.T) {
+	t.Parallel()
+
+	provider := newBlockingSetProvider()
+	metrics := &writeBehindTestMetrics{}
+	cache := NewCache(
+		provider,
+		NoopCacheStorageCodec[int]{},
+		WithWriteBehind[int, CacheObject[int]](WriteBehindConfig{QueueSize: 1, Workers: 1, Policy: WriteBehindPolicyBlock}),
+		WithMetricsProvider[int, CacheObject[int]](metrics),
+	)
+	ctx := context.Background()
+
+	if _, err := cache.GetOrLoad(ctx, "a", time.Minute, constLoader(1)); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	<-provider.started
+	if _, err := cache.GetOrLoad(ctx, "b", time.Minute, constLoader(1)); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		if _, err := cache.GetOrLoad(ctx, "c", time.Minute, constLoader(1)); err != nil {
+			t.Errorf("expected no error, got %v", err)
+		}
+	}()
+	select {
+	case <-done:
+		t.Fatal("expected caller to block while queue is full")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	close(provider.release)
+	<-done
+	if err := cache.(CacheCloser).Close(ctx); err != nil {
+		t.Fatalf("expected close to succeed, got %v", err)
+	}
+	if dropped := metrics.dropped.Load(); dropped != 0 {
+		t.Fatalf("expected no dropped writes, got %d", dropped)
+	}
+	for _, key := range []string{"a", "b", "c"} {
+		if !provider.stored(key) {
+			t.Fatalf("expected %s to be stored", key)
+		}
+	}
+}
+
+func TestWriteBehind_BlockPolicyDropsOnContextDone(t *testing.T) {
+	t.Parallel()
+
+	var stored sync.Map
+	started := make(chan struct{}, 1)
+	release := make(chan struct{})
+	queue := newWriteBehindQueue(
+		WriteBehindConfig{QueueSize: 1, Workers: 1, Policy: WriteBehindPolicyBlock},
+		NoopMetricsProvider{},
+		testLogger(),
+		func(_ context.Context, key string, _ CacheObject[int]) {
+			if key == "busy" {
+				started <- struct{}{}
+				<-release
+			}
+			stored.Store(key, true)
+		},
+	)
+	queue.enqueue(context.Background(), "busy", CacheObject[int]{})
+	<-started
+	queue.enqueue(context.Background(), "queued", CacheObject[int]{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	queue.enqueue(ctx, "late", CacheObject[int]{})
+
+	close(release)
+	if err := queue.close(context.Background()); err != nil {
+		t.Fatalf("expected close to succeed, got %v", err)
+	}
+	if _, ok := stored.Load("queued"); !ok {
+		t.Fatal("expected queued write to be flushed")
+	}
+	if _, ok := stored.Load("late"); ok {
+		t.Fatal("expected write to be dropped once the caller context is done")
+	}
+}
+
+func TestWriteBehind_CloseHonorsContext(t *testing.T) {
+	t.Parallel()
+
+	provider := newBlockingSetProvider()
+	cache := NewCache(
+		provider,
+		NoopCacheStorageCodec[int]{},
+		WithWriteBehind[int, CacheObject[int]](WriteBehindConfig{}),
+	)
+	if _, err := cache.GetOrLoad(context.Background(), "key", time.Minute, constLoader(1)); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	<-provider.started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := cache.(CacheCloser).Close(ctx); err != context.DeadlineExceeded {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+	close(provider.release)
+}
+
+func TestWriteBehind_CloseReleasesBlockedEnqueue(t *testing.T) {
+	t.Parallel()
+
+	var stored sync.Map
+	release := make(chan struct{})
+	defer close(release)
+	started := make(chan struct{}, 1)
+	queue := newWriteBehindQueue(
+		WriteBehindConfig{QueueSize: 1, Workers: 1, Policy: WriteBehindPolicyBlock},
+		NoopMetricsProvider{},
+		testLogger(),
+		func(_ context.Context, key string, _ CacheObject[int]) {
+			if key == "busy" {
+				started <- struct{}{}
+				<-release
+			}
+			stored.Store(key, true)
+		},
+	)
+	queue.enqueue(context.Background(), "busy", CacheObject[int]{})
+	<-started
+	queue.enqueue(context.Background(), "queued", CacheObject[int]{})
+	enqueued := make(chan struct{})
+	go func() {
+		defer close(enqueued)
+		queue.enqueue(context.Background(), "blocked", CacheObject[int]{})
+	}()
+	time.Sleep(10 * time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := queue.close(ctx); err != context.DeadlineExceeded {
+		t.Fatalf("expected close to give up at the deadline, got %v", err)
+	}
+	<-enqueued
+	if _, ok := stored.Load("blocked"); !ok {
+		t.Fatal("expected the blocked write to be performed inline once close started")
+	}
+}
+
+func TestWriteBehind_SetsInlineAfterClose(t *testing.T) {
+	t.Parallel()
+
+	var stored sync.Map
+	queue := newWriteBehindQueue(
+		WriteBehindConfig{},
+		NoopMetricsProvider{},
+		testLogger(),
+		func(_ context.Context, key string, _ CacheObject[int]) {
+			stored.Store(key, true)
+		},
+	)
+	if err := queue.close(context.Background()); err != nil {
+		t.Fatalf("expected close to succeed, got %v", err)
+	}
+
+	queue.enqueue(context.Background(), "key", CacheObject[int]{})
+	if _, ok := stored.Load("key"); !ok {
+		t.Fatal("expected write to be performed inline after close")
+	}
+}